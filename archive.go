@@ -0,0 +1,347 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// zipSnapshotWriter zip形式のスナップショット
+type zipSnapshotWriter struct {
+	path string
+	file *os.File
+	zw   *zip.Writer
+}
+
+func newZipSnapshotWriter(path string) (*zipSnapshotWriter, error) {
+	file, err := os.Create(path + ".partial")
+	if err != nil {
+		return nil, err
+	}
+	return &zipSnapshotWriter{path: path, file: file, zw: zip.NewWriter(file)}, nil
+}
+
+func (w *zipSnapshotWriter) AddFile(relPath, srcPath string, info os.FileInfo) (int64, string, error) {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return 0, "", err
+	}
+	header.Name = relPath
+	header.Method = zip.Deflate
+
+	dst, err := w.zw.CreateHeader(header)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return copyWithHash(dst, srcPath)
+}
+
+func (w *zipSnapshotWriter) Commit(manifest *BackupManifest) error {
+	data, err := marshalManifest(manifest)
+	if err != nil {
+		return err
+	}
+
+	header := &zip.FileHeader{Name: manifestFileName, Method: zip.Deflate, Modified: time.Now()}
+	header.SetMode(0644)
+	dst, err := w.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	if _, err := dst.Write(data); err != nil {
+		return err
+	}
+
+	if err := w.zw.Close(); err != nil {
+		return err
+	}
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(w.file.Name(), w.path)
+}
+
+func (w *zipSnapshotWriter) Abort() error {
+	w.zw.Close()
+	w.file.Close()
+	return os.Remove(w.file.Name())
+}
+
+func (w *zipSnapshotWriter) Location() string {
+	return w.path
+}
+
+// tarGzSnapshotWriter tar.gz形式のスナップショット
+type tarGzSnapshotWriter struct {
+	path string
+	file *os.File
+	gw   *gzip.Writer
+	tw   *tar.Writer
+}
+
+func newTarGzSnapshotWriter(path string) (*tarGzSnapshotWriter, error) {
+	file, err := os.Create(path + ".partial")
+	if err != nil {
+		return nil, err
+	}
+	gw := gzip.NewWriter(file)
+	return &tarGzSnapshotWriter{path: path, file: file, gw: gw, tw: tar.NewWriter(gw)}, nil
+}
+
+func (w *tarGzSnapshotWriter) AddFile(relPath, srcPath string, info os.FileInfo) (int64, string, error) {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return 0, "", err
+	}
+	header.Name = relPath
+	header.Format = tar.FormatPAX
+
+	if err := w.tw.WriteHeader(header); err != nil {
+		return 0, "", err
+	}
+
+	return copyWithHash(w.tw, srcPath)
+}
+
+func (w *tarGzSnapshotWriter) Commit(manifest *BackupManifest) error {
+	data, err := marshalManifest(manifest)
+	if err != nil {
+		return err
+	}
+
+	header := &tar.Header{
+		Name:     manifestFileName,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+		Typeflag: tar.TypeReg,
+		Format:   tar.FormatPAX,
+	}
+	if err := w.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := w.tw.Write(data); err != nil {
+		return err
+	}
+
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	if err := w.gw.Close(); err != nil {
+		return err
+	}
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(w.file.Name(), w.path)
+}
+
+func (w *tarGzSnapshotWriter) Abort() error {
+	w.tw.Close()
+	w.gw.Close()
+	w.file.Close()
+	return os.Remove(w.file.Name())
+}
+
+func (w *tarGzSnapshotWriter) Location() string {
+	return w.path
+}
+
+// zipSnapshotReader zip形式のスナップショット読み込み
+type zipSnapshotReader struct {
+	zr    *zip.ReadCloser
+	files map[string]*zip.File
+}
+
+func openZipSnapshot(path string) (*zipSnapshotReader, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		if !f.FileInfo().IsDir() {
+			files[f.Name] = f
+		}
+	}
+
+	return &zipSnapshotReader{zr: zr, files: files}, nil
+}
+
+func (r *zipSnapshotReader) Manifest() (*BackupManifest, error) {
+	if _, ok := r.files[manifestFileName]; !ok {
+		return nil, nil
+	}
+	data, err := r.ReadFile(manifestFileName)
+	if err != nil {
+		return nil, err
+	}
+	return parseManifest(data)
+}
+
+func (r *zipSnapshotReader) Files() ([]string, error) {
+	var files []string
+	for name := range r.files {
+		if name != manifestFileName {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (r *zipSnapshotReader) ReadFile(relPath string) ([]byte, error) {
+	f, ok := r.files[relPath]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+func (r *zipSnapshotReader) Close() error {
+	return r.zr.Close()
+}
+
+// tarGzSnapshotReader tar.gz形式のスナップショット読み込み
+// （展開せずに先頭から順に読み込み、戻る場合のみ開き直す）
+type tarGzSnapshotReader struct {
+	path     string
+	index    map[string]int // ファイル名 → アーカイブ内の位置
+	manifest []byte
+
+	file *os.File
+	gr   *gzip.Reader
+	tr   *tar.Reader
+	pos  int // 次に読み込むエントリの位置
+}
+
+// openTarGzSnapshot tar.gz形式のスナップショットを開き、ファイル一覧とマニフェストだけを読み込む
+func openTarGzSnapshot(path string) (*tarGzSnapshotReader, error) {
+	r := &tarGzSnapshotReader{path: path, index: make(map[string]int)}
+	if err := r.rewind(); err != nil {
+		return nil, err
+	}
+
+	for {
+		header, err := r.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		r.index[header.Name] = r.pos - 1
+		if header.Name == manifestFileName {
+			if r.manifest, err = io.ReadAll(r.tr); err != nil {
+				r.Close()
+				return nil, err
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// rewind アーカイブを先頭から開き直す
+func (r *tarGzSnapshotReader) rewind() error {
+	r.Close()
+
+	file, err := os.Open(r.path)
+	if err != nil {
+		return err
+	}
+	gr, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file, r.gr, r.tr, r.pos = file, gr, tar.NewReader(gr), 0
+	return nil
+}
+
+// next 次のエントリに進む
+func (r *tarGzSnapshotReader) next() (*tar.Header, error) {
+	header, err := r.tr.Next()
+	if err != nil {
+		return nil, err
+	}
+	r.pos++
+	return header, nil
+}
+
+func (r *tarGzSnapshotReader) Manifest() (*BackupManifest, error) {
+	if r.manifest == nil {
+		return nil, nil
+	}
+	return parseManifest(r.manifest)
+}
+
+func (r *tarGzSnapshotReader) Files() ([]string, error) {
+	var files []string
+	for name := range r.index {
+		if name != manifestFileName {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (r *tarGzSnapshotReader) ReadFile(relPath string) ([]byte, error) {
+	target, ok := r.index[relPath]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+
+	// 通過済みのエントリは先頭から読み直す
+	if r.tr == nil || target < r.pos {
+		if err := r.rewind(); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		header, err := r.next()
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		if r.pos-1 == target && header.Name == relPath {
+			return io.ReadAll(r.tr)
+		}
+	}
+}
+
+func (r *tarGzSnapshotReader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	r.gr.Close()
+	err := r.file.Close()
+	r.file, r.gr, r.tr = nil, nil, nil
+	return err
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeTestSnapshot ファイルを指定形式のスナップショットに書き込む
+func writeTestSnapshot(t *testing.T, format string, files map[string]string) *BackupSnapshot {
+	t.Helper()
+	src := t.TempDir()
+	writeTree(t, src, files)
+
+	writer, err := newSnapshotWriter(t.TempDir(), "20240118-053022", format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := &BackupManifest{Game: "fm24", DBVersion: "2400"}
+	for _, relPath := range sortedPaths(files) {
+		info, err := os.Stat(filepath.Join(src, filepath.FromSlash(relPath)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		size, sum, err := writer.AddFile(relPath, filepath.Join(src, filepath.FromSlash(relPath)), info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest.Files = append(manifest.Files, ManifestEntry{Path: relPath, Size: size, Mode: info.Mode(), SHA256: sum})
+	}
+	if err := writer.Commit(manifest); err != nil {
+		t.Fatal(err)
+	}
+	return &BackupSnapshot{ID: "20240118-053022", Path: writer.Location(), Format: format, Manifest: manifest}
+}
+
+// sortedPaths テスト用ファイルの相対パスを昇順で取得
+func sortedPaths(files map[string]string) []string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestSnapshotReader(t *testing.T) {
+	files := map[string]string{
+		"dbc/permanent/license.dbc": "license",
+		"edt/permanent/fake.edt":    "fake",
+		"lnc/all/a.lnc":             "lnc a",
+		"lnc/all/sub/b.lnc":         "lnc b",
+	}
+	paths := sortedPaths(files)
+
+	for _, format := range []string{BackupFormatDir, BackupFormatZip, BackupFormatTarGz, BackupFormatCAS} {
+		t.Run(format, func(t *testing.T) {
+			snapshot := writeTestSnapshot(t, format, files)
+			reader, err := openSnapshot(snapshot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+
+			manifest, err := reader.Manifest()
+			if err != nil || manifest == nil || len(manifest.Files) != len(files) {
+				t.Fatalf("Manifest() = %+v, %v", manifest, err)
+			}
+			if got, err := reader.Files(); err != nil || !slices.Equal(got, paths) {
+				t.Errorf("Files() = %q, %v, want %q", got, err, paths)
+			}
+
+			// 書き込み順・逆順・同じファイルの再読み込みのいずれでも読める
+			order := append(append(slices.Clone(paths), paths[1]), paths...)
+			slices.Reverse(order[len(paths)+1:])
+			for _, relPath := range order {
+				data, err := reader.ReadFile(relPath)
+				if err != nil {
+					t.Fatalf("ReadFile(%s): %v", relPath, err)
+				}
+				if string(data) != files[relPath] {
+					t.Errorf("ReadFile(%s) = %q, want %q", relPath, data, files[relPath])
+				}
+			}
+
+			if _, err := reader.ReadFile("dbc/permanent/missing.dbc"); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("存在しないファイルの ReadFile() = %v, want os.ErrNotExist", err)
+			}
+		})
+	}
+}
+
+func TestIsSafeSnapshotPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"dbc/permanent/license.dbc", true},
+		{"lnc/all/a..b.lnc", true},
+		{"", false},
+		{"../evil.dbc", false},
+		{"dbc/../../evil.dbc", false},
+		{"dbc/..", false},
+		{"/etc/passwd", false},
+		{`..\evil.dbc`, false},
+		{`dbc\..\..\evil.dbc`, false},
+		{`C:\evil.dbc`, false},
+	}
+
+	for _, tt := range tests {
+		if got := isSafeSnapshotPath(tt.path); got != tt.want {
+			t.Errorf("isSafeSnapshotPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRestoreSnapshotRejectsUnsafePaths(t *testing.T) {
+	tool := newTestTool(t)
+	outside := filepath.Dir(tool.DBBasePath)
+
+	snapshot := writeTestSnapshot(t, BackupFormatTarGz, map[string]string{
+		"dbc/permanent/new.dbc": "new",
+	})
+	// 細工されたアーカイブ（マニフェストなし）
+	writer, err := newTarGzSnapshotWriter(snapshot.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(t.TempDir(), "evil.dbc")
+	writeTree(t, filepath.Dir(src), map[string]string{"evil.dbc": "evil"})
+	info, _ := os.Stat(src)
+	for _, relPath := range []string{"dbc/permanent/new.dbc", "../evil.dbc", "dbc/../../evil2.dbc", "/tmp/evil3.dbc"} {
+		if _, _, err := writer.AddFile(relPath, src, info); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Commit(&BackupManifest{}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot.Manifest = nil
+
+	result, err := tool.restoreSnapshot(snapshot, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(result.Restored, []string{"dbc/permanent/new.dbc"}) {
+		t.Errorf("Restored = %q", result.Restored)
+	}
+	if len(result.Failed) != 3 {
+		t.Errorf("Failed = %q, want 3件", result.Failed)
+	}
+	for _, name := range []string{"evil.dbc", "evil2.dbc"} {
+		if _, err := os.Stat(filepath.Join(outside, name)); !os.IsNotExist(err) {
+			t.Errorf("復元先の外にファイルが作成されました: %s", name)
+		}
+	}
+}
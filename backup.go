@@ -0,0 +1,395 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// backupTimestampFormat バックアップスナップショットのディレクトリ名形式
+const backupTimestampFormat = "20060102_150405"
+
+// BackupSnapshot バックアップスナップショット情報
+type BackupSnapshot struct {
+	ID        string
+	Path      string
+	CreatedAt time.Time
+	Format    string
+	Manifest  *BackupManifest
+}
+
+// Source バックアップ元のDBパス（マニフェストがない場合は空）
+func (s *BackupSnapshot) Source() string {
+	if s.Manifest == nil {
+		return ""
+	}
+	return s.Manifest.SourcePath
+}
+
+// Game バックアップ元のゲーム（ゲームを記録していない古いマニフェストは fm24）
+func (s *BackupSnapshot) Game() string {
+	if s.Manifest == nil || s.Manifest.Game == "" {
+		return defaultGameID
+	}
+	return s.Manifest.Game
+}
+
+// parseSnapshotTime スナップショットIDから作成日時を取得
+//
+// IDはタイムスタンプ、または複数のDBバージョンを処理した場合の「タイムスタンプ_バージョン」。
+func parseSnapshotTime(id string) (time.Time, error) {
+	timestamp := id
+	if len(id) > len(backupTimestampFormat) {
+		timestamp = id[:len(backupTimestampFormat)]
+		if _, err := strconv.Atoi(strings.TrimPrefix(id[len(backupTimestampFormat):], "_")); err != nil || id[len(backupTimestampFormat)] != '_' {
+			return time.Time{}, fmt.Errorf("不正なバックアップID: %s", id)
+		}
+	}
+	return time.ParseInLocation(backupTimestampFormat, timestamp, time.Local)
+}
+
+// backupRoot バックアップのルートディレクトリを取得（設定ファイルの backup.directory）
+func (t *FM24Tool) backupRoot() (string, error) {
+	if t.Config.Backup.Directory == "" {
+		return expandPath("~/" + t.Game.backupDirName())
+	}
+	return expandPath(t.Config.Backup.Directory)
+}
+
+// backupEnabled バックアップが有効か
+func (t *FM24Tool) backupEnabled() bool {
+	return t.Config.Backup.Enabled
+}
+
+// backupFormat バックアップ形式（未指定の場合は dir）
+func (t *FM24Tool) backupFormat() string {
+	if t.Config.Backup.Format == "" {
+		return BackupFormatDir
+	}
+	return t.Config.Backup.Format
+}
+
+// listBackupSnapshots バックアップスナップショットを古い順に列挙
+func listBackupSnapshots(root string) ([]BackupSnapshot, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var candidates []BackupSnapshot
+	for _, entry := range entries {
+		id, format := parseSnapshotName(entry.Name(), entry.IsDir())
+		if id == "" {
+			continue
+		}
+		candidates = append(candidates, BackupSnapshot{
+			ID:     id,
+			Path:   filepath.Join(root, entry.Name()),
+			Format: format,
+		})
+	}
+
+	// 重複排除ストア内のスナップショット
+	casSnapshots, err := listCASSnapshots(root)
+	if err != nil {
+		return nil, err
+	}
+	candidates = append(candidates, casSnapshots...)
+
+	var snapshots []BackupSnapshot
+	for _, snapshot := range candidates {
+		createdAt, err := parseSnapshotTime(snapshot.ID)
+		if err != nil {
+			continue
+		}
+		snapshot.CreatedAt = createdAt
+		if reader, err := openSnapshot(&snapshot); err == nil {
+			snapshot.Manifest, _ = reader.Manifest()
+			reader.Close()
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+	})
+
+	return snapshots, nil
+}
+
+// gameSnapshots 対象ゲームのスナップショットを古い順に列挙
+//
+// backup.directory を複数のゲームで共有している場合も、他のゲームのスナップショットは
+// 一覧・削除・検証・復元の対象にしない。
+func (t *FM24Tool) gameSnapshots(root string) ([]BackupSnapshot, error) {
+	snapshots, err := listBackupSnapshots(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []BackupSnapshot
+	for _, snapshot := range snapshots {
+		if snapshot.Game() == t.Game.ID {
+			filtered = append(filtered, snapshot)
+		}
+	}
+	return filtered, nil
+}
+
+// snapshotSize スナップショットのディスク使用量とファイル数を取得
+func snapshotSize(snapshot *BackupSnapshot) (int64, int) {
+	// 重複排除ストアはブロブを共有するため、論理サイズを返す
+	if snapshot.Format == BackupFormatCAS {
+		var size int64
+		count := 0
+		if snapshot.Manifest != nil {
+			for _, entry := range snapshot.Manifest.Files {
+				size += entry.Size
+			}
+			count = len(snapshot.Manifest.Files)
+		}
+		return size, count
+	}
+
+	if snapshot.Format != BackupFormatDir {
+		count := 0
+		if snapshot.Manifest != nil {
+			count = len(snapshot.Manifest.Files)
+		}
+		if info, err := os.Stat(snapshot.Path); err == nil {
+			return info.Size(), count
+		}
+		return 0, count
+	}
+
+	var size int64
+	count := 0
+	filepath.Walk(snapshot.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		size += info.Size()
+		if filepath.Base(path) != manifestFileName {
+			count++
+		}
+		return nil
+	})
+	return size, count
+}
+
+// installLabel バックアップ元パスに対応するインストール名を取得
+func (t *FM24Tool) installLabel(sourcePath string) string {
+	if sourcePath == "" {
+		return "不明"
+	}
+	for _, installPath := range t.Config.InstallPaths {
+		if installPath.Path != "" && isWithinPath(sourcePath, installPath.Path) {
+			return installPath.Name
+		}
+	}
+	return filepath.Dir(sourcePath)
+}
+
+// isWithinPath パスが root 自体またはその中か（/games/FM に対して /games/FM2 は含まない）
+func isWithinPath(path, root string) bool {
+	rel, err := filepath.Rel(filepath.Clean(root), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// formatSize バイト数を読みやすい形式に変換
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// ListBackups バックアップ一覧を表示
+func (t *FM24Tool) ListBackups() error {
+	color.Cyan("==========================================================")
+	color.Cyan("%s バックアップ一覧", t.Game.Short)
+	color.Cyan("==========================================================\n")
+
+	root, err := t.backupRoot()
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := t.gameSnapshots(root)
+	if err != nil {
+		return fmt.Errorf("バックアップ一覧取得エラー: %w", err)
+	}
+
+	fmt.Printf("バックアップディレクトリ: %s\n\n", root)
+
+	if len(snapshots) == 0 {
+		color.Yellow("バックアップはありません")
+		return nil
+	}
+
+	var totalSize int64
+	fmt.Printf("  %-21s  %-20s  %-6s  %-6s  %6s  %10s  %s\n", "ID", "インストール", "DB", "形式", "ファイル", "サイズ", "日時")
+	for i := range snapshots {
+		snapshot := &snapshots[i]
+		size, count := snapshotSize(snapshot)
+		totalSize += size
+
+		dbVersion := "-"
+		if snapshot.Manifest != nil {
+			dbVersion = snapshot.Manifest.DBVersion
+		}
+
+		fmt.Printf("  %-21s  %-20s  %-6s  %-6s  %6d  %10s  %s\n",
+			snapshot.ID,
+			t.installLabel(snapshot.Source()),
+			dbVersion,
+			snapshot.Format,
+			count,
+			formatSize(size),
+			snapshot.CreatedAt.Format("2006-01-02 15:04:05"),
+		)
+	}
+
+	fmt.Println()
+	fmt.Printf("合計: %d個のバックアップ (%s)\n", len(snapshots), formatSize(totalSize))
+	for _, snapshot := range snapshots {
+		if snapshot.Format == BackupFormatCAS {
+			fmt.Printf("重複排除ストアの実サイズ: %s\n", formatSize(casStoreSize(root)))
+			break
+		}
+	}
+
+	return nil
+}
+
+// selectPrunable 保持ルールに該当しないスナップショットを選択
+func selectPrunable(snapshots []BackupSnapshot, retention RetentionConfig, now time.Time) []BackupSnapshot {
+	// インストールごとにグループ化（snapshots は古い順）
+	groups := make(map[string][]int)
+	for i := range snapshots {
+		source := snapshots[i].Source()
+		groups[source] = append(groups[source], i)
+	}
+
+	keep := make([]bool, len(snapshots))
+	for _, indexes := range groups {
+		// 最新のスナップショットは保持ルールによらず常に保持
+		keep[indexes[len(indexes)-1]] = true
+
+		// 最初の（未改変の）スナップショットは常に保持
+		if retention.KeepVanilla {
+			keep[indexes[0]] = true
+		}
+
+		// 最新N件を保持
+		if retention.KeepLast > 0 {
+			start := len(indexes) - retention.KeepLast
+			if start < 0 {
+				start = 0
+			}
+			for _, i := range indexes[start:] {
+				keep[i] = true
+			}
+		}
+	}
+
+	// 指定日数以内のものを保持
+	if retention.KeepDays > 0 {
+		cutoff := now.AddDate(0, 0, -retention.KeepDays)
+		for i := range snapshots {
+			if snapshots[i].CreatedAt.After(cutoff) {
+				keep[i] = true
+			}
+		}
+	}
+
+	var prunable []BackupSnapshot
+	for i := range snapshots {
+		if !keep[i] {
+			prunable = append(prunable, snapshots[i])
+		}
+	}
+
+	return prunable
+}
+
+// PruneBackups 保持ルールに従って古いバックアップを削除（interactive の場合は削除前に確認）
+func (t *FM24Tool) PruneBackups(retention RetentionConfig, interactive bool) error {
+	if retention.KeepLast <= 0 && retention.KeepDays <= 0 {
+		return fmt.Errorf("保持ルールが設定されていません（backup.retention.keep_last または keep_days を設定してください）")
+	}
+
+	root, err := t.backupRoot()
+	if err != nil {
+		return err
+	}
+
+	// 適用・復元中のバックアップを削除しないようロックを保持
+	release, err := t.lockBackups()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	snapshots, err := t.gameSnapshots(root)
+	if err != nil {
+		return fmt.Errorf("バックアップ一覧取得エラー: %w", err)
+	}
+
+	prunable := selectPrunable(snapshots, retention, time.Now())
+	if len(prunable) == 0 {
+		color.Green("✓ 削除対象のバックアップはありません")
+		return nil
+	}
+
+	fmt.Println("🗑️  削除対象のバックアップ:")
+	for i := range prunable {
+		size, count := snapshotSize(&prunable[i])
+		fmt.Printf("  %s (%s, %d個のファイル, %s)\n", prunable[i].ID, t.installLabel(prunable[i].Source()), count, formatSize(size))
+	}
+
+	if interactive && !confirm("\n削除しますか? (y/n): ", t.AssumeYes) {
+		return errCancelled
+	}
+
+	removed := 0
+	removedCAS := false
+	for _, snapshot := range prunable {
+		if err := os.RemoveAll(snapshot.Path); err != nil {
+			color.Yellow("  ⚠️  削除失敗: %s - %v", snapshot.ID, err)
+			continue
+		}
+		if snapshot.Format == BackupFormatCAS {
+			removedCAS = true
+		}
+		removed++
+	}
+
+	// 参照されなくなったブロブを回収
+	if removedCAS {
+		if err := gcCASBlobs(filepath.Join(root, casDirName)); err != nil {
+			color.Yellow("  ⚠️  重複排除ストアの整理に失敗しました: %v", err)
+		}
+	}
+
+	color.Green("✓ %d個のバックアップを削除しました（%d個保持）", removed, len(snapshots)-removed)
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestIsWithinPath(t *testing.T) {
+	tests := []struct {
+		path string
+		root string
+		want bool
+	}{
+		{"/games/FM/db/2400", "/games/FM", true},
+		{"/games/FM", "/games/FM", true},
+		{"/games/FM", "/games/FM/", true},
+		{"/games/FM/db/../db/2400", "/games/FM", true},
+		{"/games/FM2/db/2400", "/games/FM", false},
+		{"/games/FM-old/db/2400", "/games/FM", false},
+		{"/games", "/games/FM", false},
+		{"/other/FM/db", "/games/FM", false},
+		{"/games/..FM/db", "/games", true},
+	}
+
+	for _, tt := range tests {
+		path, root := filepath.FromSlash(tt.path), filepath.FromSlash(tt.root)
+		if got := isWithinPath(path, root); got != tt.want {
+			t.Errorf("isWithinPath(%q, %q) = %v, want %v", path, root, got, tt.want)
+		}
+	}
+}
+
+func TestInstallLabel(t *testing.T) {
+	tool := &FM24Tool{Config: &Config{InstallPaths: []InstallPath{
+		{Name: "fm", Path: filepath.FromSlash("/games/FM")},
+		{Name: "fm2", Path: filepath.FromSlash("/games/FM2/")},
+		{Name: "empty"},
+	}}}
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"/games/FM/db/2400", "fm"},
+		{"/games/FM2/db/2400", "fm2"},
+		{"/games/FM3/db/2400", "/games/FM3/db"},
+		{"", "不明"},
+	}
+
+	for _, tt := range tests {
+		source := filepath.FromSlash(tt.source)
+		if got := tool.installLabel(source); got != filepath.FromSlash(tt.want) {
+			t.Errorf("installLabel(%q) = %q, want %q", source, got, tt.want)
+		}
+	}
+}
+
+// testSnapshot 指定日数前に作成されたスナップショット（source が空の場合はマニフェストなし）
+func testSnapshot(id, source string, now time.Time, daysAgo int) BackupSnapshot {
+	snapshot := BackupSnapshot{ID: id, CreatedAt: now.AddDate(0, 0, -daysAgo)}
+	if source != "" {
+		snapshot.Manifest = &BackupManifest{SourcePath: source}
+	}
+	return snapshot
+}
+
+func TestSelectPrunable(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+
+	// 古い順（a: 5件、b: 2件、マニフェストなし: 2件）
+	snapshots := []BackupSnapshot{
+		testSnapshot("a1", "/games/a/db/2400", now, 60),
+		testSnapshot("n1", "", now, 50),
+		testSnapshot("a2", "/games/a/db/2400", now, 40),
+		testSnapshot("b1", "/games/b/db/2400", now, 30),
+		testSnapshot("a3", "/games/a/db/2400", now, 20),
+		testSnapshot("n2", "", now, 15),
+		testSnapshot("a4", "/games/a/db/2400", now, 10),
+		testSnapshot("b2", "/games/b/db/2400", now, 5),
+		testSnapshot("a5", "/games/a/db/2400", now, 1),
+	}
+
+	tests := []struct {
+		name      string
+		retention RetentionConfig
+		want      []string
+	}{
+		{
+			name:      "最新1件",
+			retention: RetentionConfig{KeepLast: 1},
+			want:      []string{"a1", "n1", "a2", "b1", "a3", "a4"},
+		},
+		{
+			name:      "最新2件（インストールごと）",
+			retention: RetentionConfig{KeepLast: 2},
+			want:      []string{"a1", "a2", "a3"},
+		},
+		{
+			name:      "最新2件と最初のバックアップ",
+			retention: RetentionConfig{KeepLast: 2, KeepVanilla: true},
+			want:      []string{"a2", "a3"},
+		},
+		{
+			name:      "件数より多い",
+			retention: RetentionConfig{KeepLast: 10},
+			want:      nil,
+		},
+		{
+			name:      "12日以内",
+			retention: RetentionConfig{KeepDays: 12},
+			want:      []string{"a1", "a2", "b1", "a3", "n1"},
+		},
+		{
+			name:      "日数のみでも各インストールの最新は保持",
+			retention: RetentionConfig{KeepDays: 3},
+			want:      []string{"a1", "n1", "a2", "b1", "a3", "a4"},
+		},
+		{
+			name:      "件数と日数（いずれかに該当すれば保持）",
+			retention: RetentionConfig{KeepLast: 1, KeepDays: 25},
+			want:      []string{"a1", "n1", "a2", "b1"},
+		},
+		{
+			name:      "全ルール",
+			retention: RetentionConfig{KeepLast: 1, KeepDays: 12, KeepVanilla: true},
+			want:      []string{"a2", "a3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, snapshot := range selectPrunable(snapshots, tt.retention, now) {
+				got = append(got, snapshot.ID)
+			}
+			slices.Sort(got)
+			want := slices.Clone(tt.want)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("selectPrunable() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	tool := newTestTool(t)
+	root := t.TempDir()
+	tool.Config.Backup.Directory = root
+
+	// 同じインストールの4件と、別のゲーム（FM23）の古いバックアップ
+	now := time.Now()
+	var ids []string
+	for i, daysAgo := range []int{40, 30, 20, 10} {
+		id := now.AddDate(0, 0, -daysAgo).Format(backupTimestampFormat)
+		ids = append(ids, id)
+		writeTree(t, filepath.Join(root, id), map[string]string{
+			"lnc/all/a.lnc":  fmt.Sprintf("lnc %d", i),
+			manifestFileName: fmt.Sprintf(`{"game": "fm24", "source_path": %q, "db_version": "2400"}`, tool.DBBasePath),
+		})
+	}
+	fm23ID := now.AddDate(0, 0, -50).Format(backupTimestampFormat)
+	writeTree(t, filepath.Join(root, fm23ID), map[string]string{
+		manifestFileName: `{"game": "fm23", "source_path": "/games/fm23/db/2340", "db_version": "2340"}`,
+	})
+
+	if err := tool.PruneBackups(RetentionConfig{KeepLast: 1, KeepVanilla: true}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, id := range ids {
+		_, err := os.Stat(filepath.Join(root, id))
+		if kept := i == 0 || i == len(ids)-1; kept != (err == nil) {
+			t.Errorf("%s: 保持 = %v, want %v", id, err == nil, kept)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, fm23ID)); err != nil {
+		t.Error("別のゲームのバックアップが削除されました")
+	}
+
+	if err := tool.PruneBackups(RetentionConfig{}, false); err == nil {
+		t.Error("保持ルールがない場合にエラーになりません")
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// 重複排除ストアのレイアウト
+//
+//	<backup.directory>/store/blobs/<先頭2文字>/<sha256>  ファイル内容
+//	<backup.directory>/store/index/<ID>.json             スナップショットごとのマニフェスト
+const (
+	casDirName  = "store"
+	casBlobsDir = "blobs"
+	casIndexDir = "index"
+	casIndexExt = ".json"
+	casTempDir  = "tmp"
+)
+
+// casHashPattern ブロブのキー（小文字16進のSHA-256）
+var casHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// casBlobPath ハッシュに対応するブロブのパス（ハッシュの形式が不正な場合はエラー）
+func casBlobPath(storeDir, sum string) (string, error) {
+	if !casHashPattern.MatchString(sum) {
+		return "", fmt.Errorf("不正なハッシュです: %q", sum)
+	}
+	return filepath.Join(storeDir, casBlobsDir, sum[:2], sum), nil
+}
+
+// casSnapshotWriter 重複排除ストアへのスナップショット書き込み
+type casSnapshotWriter struct {
+	storeDir  string
+	indexPath string
+}
+
+func newCASSnapshotWriter(storeDir, id string) (*casSnapshotWriter, error) {
+	for _, dir := range []string{casBlobsDir, casIndexDir, casTempDir} {
+		if err := os.MkdirAll(filepath.Join(storeDir, dir), 0755); err != nil {
+			return nil, err
+		}
+	}
+	return &casSnapshotWriter{
+		storeDir:  storeDir,
+		indexPath: filepath.Join(storeDir, casIndexDir, id+casIndexExt),
+	}, nil
+}
+
+func (w *casSnapshotWriter) AddFile(relPath, srcPath string, info os.FileInfo) (int64, string, error) {
+	// 一時ファイルに書き込みながらハッシュを計算し、同じ内容のブロブがなければ配置
+	tmp, err := os.CreateTemp(filepath.Join(w.storeDir, casTempDir), "blob-*")
+	if err != nil {
+		return 0, "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	size, sum, err := copyWithHash(tmp, srcPath)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	blobPath, err := casBlobPath(w.storeDir, sum)
+	if err != nil {
+		return 0, "", err
+	}
+	if _, err := os.Stat(blobPath); err == nil {
+		return size, sum, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(blobPath), 0755); err != nil {
+		return 0, "", err
+	}
+	if err := os.Rename(tmp.Name(), blobPath); err != nil {
+		return 0, "", err
+	}
+
+	return size, sum, nil
+}
+
+func (w *casSnapshotWriter) Commit(manifest *BackupManifest) error {
+	data, err := marshalManifest(manifest)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := w.indexPath + ".partial"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, w.indexPath)
+}
+
+func (w *casSnapshotWriter) Abort() error {
+	// 追加済みのブロブは他のスナップショットと共有される可能性があるため、
+	// 参照されないものは gcCASBlobs で回収する
+	return gcCASBlobs(w.storeDir)
+}
+
+func (w *casSnapshotWriter) Location() string {
+	return w.indexPath
+}
+
+// casSnapshotReader 重複排除ストアからのスナップショット読み込み
+type casSnapshotReader struct {
+	storeDir string
+	manifest *BackupManifest
+	entries  map[string]ManifestEntry
+}
+
+func openCASSnapshot(indexPath string) (*casSnapshotReader, error) {
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := parseManifest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]ManifestEntry, len(manifest.Files))
+	for _, entry := range manifest.Files {
+		if !casHashPattern.MatchString(entry.SHA256) {
+			return nil, fmt.Errorf("インデックスのハッシュが不正です: %s (%q)", entry.Path, entry.SHA256)
+		}
+		entries[entry.Path] = entry
+	}
+
+	return &casSnapshotReader{
+		storeDir: filepath.Dir(filepath.Dir(indexPath)),
+		manifest: manifest,
+		entries:  entries,
+	}, nil
+}
+
+func (r *casSnapshotReader) Manifest() (*BackupManifest, error) {
+	return r.manifest, nil
+}
+
+func (r *casSnapshotReader) Files() ([]string, error) {
+	files := make([]string, 0, len(r.manifest.Files))
+	for _, entry := range r.manifest.Files {
+		files = append(files, entry.Path)
+	}
+	return files, nil
+}
+
+func (r *casSnapshotReader) ReadFile(relPath string) ([]byte, error) {
+	entry, ok := r.entries[relPath]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	blobPath, err := casBlobPath(r.storeDir, entry.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(blobPath)
+	if err != nil {
+		return nil, err
+	}
+	if sha256Hex(data) != entry.SHA256 {
+		return nil, fmt.Errorf("ブロブが破損しています（ハッシュ不一致）: %s", blobPath)
+	}
+	return data, nil
+}
+
+func (r *casSnapshotReader) Close() error {
+	return nil
+}
+
+// listCASSnapshots 重複排除ストア内のスナップショットを列挙
+func listCASSnapshots(root string) ([]BackupSnapshot, error) {
+	indexDir := filepath.Join(root, casDirName, casIndexDir)
+	entries, err := os.ReadDir(indexDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snapshots []BackupSnapshot
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), casIndexExt) {
+			continue
+		}
+		snapshots = append(snapshots, BackupSnapshot{
+			ID:     strings.TrimSuffix(entry.Name(), casIndexExt),
+			Path:   filepath.Join(indexDir, entry.Name()),
+			Format: BackupFormatCAS,
+		})
+	}
+
+	return snapshots, nil
+}
+
+// gcCASBlobs どのスナップショットからも参照されていないブロブを削除
+func gcCASBlobs(storeDir string) error {
+	referenced := make(map[string]bool)
+
+	indexDir := filepath.Join(storeDir, casIndexDir)
+	entries, err := os.ReadDir(indexDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), casIndexExt) {
+			continue
+		}
+		reader, err := openCASSnapshot(filepath.Join(indexDir, entry.Name()))
+		if err != nil {
+			// 読めないインデックスがある場合は安全のため何も削除しない
+			return fmt.Errorf("インデックス読み込みエラー: %s: %w", entry.Name(), err)
+		}
+		for _, file := range reader.manifest.Files {
+			referenced[file.SHA256] = true
+		}
+	}
+
+	return filepath.Walk(filepath.Join(storeDir, casBlobsDir), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || referenced[info.Name()] {
+			return nil
+		}
+		return os.Remove(path)
+	})
+}
+
+// casStoreSize 重複排除ストアの実ディスク使用量
+func casStoreSize(root string) int64 {
+	var size int64
+	filepath.Walk(filepath.Join(root, casDirName), func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCASSnapshot 重複排除ストアにスナップショットを書き込む（commit が false の場合は破棄）
+func writeCASSnapshot(t *testing.T, root, id string, files map[string]string, commit bool) {
+	t.Helper()
+	src := t.TempDir()
+	writeTree(t, src, files)
+
+	writer, err := newSnapshotWriter(root, id, BackupFormatCAS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := &BackupManifest{Game: "fm24", DBVersion: "2400"}
+	for _, relPath := range sortedPaths(files) {
+		srcPath := filepath.Join(src, filepath.FromSlash(relPath))
+		info, err := os.Stat(srcPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size, sum, err := writer.AddFile(relPath, srcPath, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest.add(ManifestEntry{Path: relPath, Size: size, Mode: info.Mode(), SHA256: sum})
+	}
+
+	if !commit {
+		if err := writer.Abort(); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if err := writer.Commit(manifest); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// casBlobs 重複排除ストア内のブロブのハッシュ
+func casBlobs(t *testing.T, root string) map[string]bool {
+	t.Helper()
+	blobs := make(map[string]bool)
+	filepath.Walk(filepath.Join(root, casDirName, casBlobsDir), func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			blobs[info.Name()] = true
+		}
+		return nil
+	})
+	return blobs
+}
+
+func TestCASDedup(t *testing.T) {
+	root := t.TempDir()
+	writeCASSnapshot(t, root, "20240118_143022", map[string]string{"a.dbc": "shared", "b.dbc": "first"}, true)
+	writeCASSnapshot(t, root, "20240120_090000", map[string]string{"a.dbc": "shared", "c/a.dbc": "shared", "b.dbc": "second"}, true)
+
+	blobs := casBlobs(t, root)
+	for _, content := range []string{"shared", "first", "second"} {
+		if !blobs[sha256Hex([]byte(content))] {
+			t.Errorf("ブロブがありません: %q", content)
+		}
+	}
+	if len(blobs) != 3 {
+		t.Errorf("ブロブ数 = %d, want 3（同じ内容は1つのみ）", len(blobs))
+	}
+
+	snapshots, err := listCASSnapshots(root)
+	if err != nil || len(snapshots) != 2 {
+		t.Fatalf("listCASSnapshots() = %+v, %v", snapshots, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(root, casDirName, casTempDir)); len(entries) != 0 {
+		t.Errorf("一時ファイルが残っています: %d個", len(entries))
+	}
+}
+
+func TestCASGC(t *testing.T) {
+	root := t.TempDir()
+	storeDir := filepath.Join(root, casDirName)
+	writeCASSnapshot(t, root, "20240118_143022", map[string]string{"a.dbc": "shared", "b.dbc": "first"}, true)
+	writeCASSnapshot(t, root, "20240120_090000", map[string]string{"a.dbc": "shared", "b.dbc": "second"}, true)
+
+	if err := os.Remove(filepath.Join(storeDir, casIndexDir, "20240118_143022"+casIndexExt)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gcCASBlobs(storeDir); err != nil {
+		t.Fatal(err)
+	}
+
+	blobs := casBlobs(t, root)
+	if blobs[sha256Hex([]byte("first"))] {
+		t.Error("参照されていないブロブが削除されていません")
+	}
+	if !blobs[sha256Hex([]byte("shared"))] || !blobs[sha256Hex([]byte("second"))] {
+		t.Error("参照されているブロブが削除されました")
+	}
+
+	// 読めないインデックスがある場合は何も削除しない
+	writeTree(t, filepath.Join(storeDir, casIndexDir), map[string]string{"broken" + casIndexExt: "{"})
+	writeCASSnapshot(t, root, "20240121_090000", map[string]string{"c.dbc": "third"}, true)
+	os.Remove(filepath.Join(storeDir, casIndexDir, "20240121_090000"+casIndexExt))
+	if err := gcCASBlobs(storeDir); err == nil {
+		t.Error("読めないインデックスがあってもエラーになりません")
+	}
+	if !casBlobs(t, root)[sha256Hex([]byte("third"))] {
+		t.Error("読めないインデックスがある状態でブロブが削除されました")
+	}
+}
+
+func TestCASAbort(t *testing.T) {
+	root := t.TempDir()
+	writeCASSnapshot(t, root, "20240118_143022", map[string]string{"a.dbc": "shared"}, true)
+	writeCASSnapshot(t, root, "20240120_090000", map[string]string{"a.dbc": "shared", "b.dbc": "aborted"}, false)
+
+	blobs := casBlobs(t, root)
+	if !blobs[sha256Hex([]byte("shared"))] {
+		t.Error("確定済みのスナップショットのブロブが削除されました")
+	}
+	if blobs[sha256Hex([]byte("aborted"))] {
+		t.Error("破棄したスナップショットのブロブが残っています")
+	}
+	if _, err := os.Stat(filepath.Join(root, casDirName, casIndexDir, "20240120_090000"+casIndexExt)); !os.IsNotExist(err) {
+		t.Error("破棄したスナップショットのインデックスがあります")
+	}
+}
+
+func TestCASBlobPath(t *testing.T) {
+	valid := sha256Hex([]byte("license"))
+	tests := []struct {
+		sum     string
+		wantErr bool
+	}{
+		{valid, false},
+		{strings.ToUpper(valid), true},
+		{valid[:63], true},
+		{valid + "0", true},
+		{"", true},
+		{"a", true},
+		{"../../../../etc/passwd", true},
+		{"../" + valid[3:], true},
+		{strings.Repeat("g", 64), true},
+	}
+
+	for _, tt := range tests {
+		path, err := casBlobPath("/store", tt.sum)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("casBlobPath(%q) = %q, %v, wantErr %v", tt.sum, path, err, tt.wantErr)
+		}
+	}
+}
+
+func TestOpenCASSnapshotRejectsInvalidHash(t *testing.T) {
+	root := t.TempDir()
+	indexDir := filepath.Join(root, casDirName, casIndexDir)
+	writeTree(t, indexDir, map[string]string{
+		"20240118_143022" + casIndexExt: `{"files": [{"path": "dbc/permanent/license.dbc", "sha256": "../../../../../etc/passwd"}]}`,
+	})
+
+	if _, err := openCASSnapshot(filepath.Join(indexDir, "20240118_143022"+casIndexExt)); err == nil {
+		t.Fatal("不正なハッシュのインデックスを開けました")
+	}
+}
+
+func TestCASReadFileCorrupted(t *testing.T) {
+	root := t.TempDir()
+	writeCASSnapshot(t, root, "20240118_143022", map[string]string{"a.dbc": "license"}, true)
+
+	blobPath, err := casBlobPath(filepath.Join(root, casDirName), sha256Hex([]byte("license")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(blobPath, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := openCASSnapshot(filepath.Join(root, casDirName, casIndexDir, "20240118_143022"+casIndexExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.ReadFile("a.dbc"); err == nil || !strings.Contains(err.Error(), "ハッシュ不一致") {
+		t.Errorf("破損したブロブの ReadFile() = %v, want ハッシュ不一致", err)
+	}
+
+	result, err := verifySnapshot(reader, reader.manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Corrupted) != 1 {
+		t.Errorf("verifySnapshot() = %+v, want 破損1件", result)
+	}
+}
@@ -0,0 +1,685 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/pflag"
+)
+
+// programName コマンド名（使用方法と補完スクリプトに使用）
+const programName = "fm24-real"
+
+// options コマンドラインオプションの値
+type options struct {
+	configPath   string
+	gameID       string
+	customPath   string
+	installName  string
+	installs     []string // 複数指定できる --install（service）
+	profileName  string
+	dbVersion    string
+	allVersions  bool
+	output       string
+	yes          bool
+	dryRun       bool
+	snapshotID   string
+	force        bool
+	keepLast     int
+	keepDays     int
+	keyPath      string
+	platform     string
+	description  string
+	quietPeriod  time.Duration
+	pollInterval time.Duration
+	poll         bool
+	waitForExit  bool
+}
+
+// command サブコマンドの定義
+type command struct {
+	Name     string
+	Args     string // 位置引数の表記（例: "<ルールパック>"）
+	Summary  string
+	Examples []string
+	Children []*command // 2階層目のサブコマンド（backups list 等）
+	Hidden   bool       // 使用方法に表示しない
+
+	MinArgs int
+	MaxArgs int // 負の場合は無制限
+
+	// Flags 固有のオプションを定義
+	Flags func(fs *pflag.FlagSet, opts *options)
+	// Validate オプションの組み合わせを検証
+	Validate func(fs *pflag.FlagSet, opts *options) error
+	// Complete 位置引数の補完候補（args は入力済みの位置引数）
+	Complete func(opts *options, args []string) []string
+	// Run コマンドを実行
+	Run func(fs *pflag.FlagSet, opts *options, args []string) error
+
+	parent *command
+}
+
+// path 親を含むコマンド名（例: "backups list"）
+func (c *command) path() string {
+	if c.parent == nil || c.parent.Name == "" {
+		return c.Name
+	}
+	return c.parent.path() + " " + c.Name
+}
+
+// child 名前が一致するサブコマンド
+func (c *command) child(name string) *command {
+	for _, child := range c.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// childNames 表示するサブコマンド名
+func (c *command) childNames() []string {
+	var names []string
+	for _, child := range c.Children {
+		if !child.Hidden {
+			names = append(names, child.Name)
+		}
+	}
+	return names
+}
+
+// link 親コマンドへの参照を設定
+func (c *command) link() {
+	for _, child := range c.Children {
+		child.parent = c
+		child.link()
+	}
+}
+
+// flagSet コマンドのオプションを定義した FlagSet
+func (c *command) flagSet(opts *options) *pflag.FlagSet {
+	fs := pflag.NewFlagSet(programName+" "+c.path(), pflag.ContinueOnError)
+	fs.SortFlags = false
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	if c.Flags != nil {
+		c.Flags(fs, opts)
+	}
+	fs.BoolP("help", "h", false, "このコマンドのヘルプを表示")
+	return fs
+}
+
+// 共通のオプション
+
+// configFlag 設定ファイルのパス
+func configFlag(fs *pflag.FlagSet, opts *options) {
+	fs.StringVar(&opts.configPath, "config", "", "設定ファイルパス（デフォルト: ~/.config/fm24-real/config.yaml）")
+}
+
+// gameFlag 対象のゲーム
+func gameFlag(fs *pflag.FlagSet, opts *options) {
+	fs.StringVarP(&opts.gameID, "game", "g", "", "対象のゲーム（fm24, fm23、デフォルト: 設定ファイルの game または fm24）")
+}
+
+// installFlags インストールの指定（--path と --install は同時に指定できない）
+func installFlags(fs *pflag.FlagSet, opts *options) {
+	fs.StringVarP(&opts.customPath, "path", "p", "", "データベースのカスタムパス")
+	fs.StringVar(&opts.installName, "install", "", "設定ファイルのインストールパスを名前で指定")
+}
+
+// dbVersionFlags DBバージョンの選択（all の場合は --all-db-versions も定義）
+func dbVersionFlags(fs *pflag.FlagSet, opts *options, all bool) {
+	fs.StringVar(&opts.dbVersion, "db-version", "", "対象のDBバージョン（例: 2410、デフォルト: 最新）")
+	if all {
+		fs.BoolVar(&opts.allVersions, "all-db-versions", false, "全てのDBバージョンを対象にする")
+	}
+}
+
+// profileFlag 使用するプロファイル
+func profileFlag(fs *pflag.FlagSet, opts *options) {
+	fs.StringVar(&opts.profileName, "profile", "", "使用するプロファイル（all, japan-only, everything-except-greece 等）")
+}
+
+// outputFlag 出力形式
+func outputFlag(fs *pflag.FlagSet, opts *options) {
+	fs.StringVarP(&opts.output, "output", "o", OutputTable, "出力形式（table, json）")
+}
+
+// yesFlags 確認プロンプトの省略
+func yesFlags(fs *pflag.FlagSet, opts *options) {
+	fs.BoolVarP(&opts.yes, "yes", "y", false, "確認プロンプトを省略して実行（非対話モード）")
+	fs.BoolVar(&opts.yes, "non-interactive", false, "--yes と同じ")
+}
+
+// waitForExitFlag ゲームの実行中は終了を待つ
+func waitForExitFlag(fs *pflag.FlagSet, opts *options) {
+	fs.BoolVar(&opts.waitForExit, "wait-for-exit", false, "ゲームの実行中は終了を待ってから実行（デフォルト: 実行中はエラー）")
+}
+
+// rejectTogether 同時に指定できないオプションの組み合わせを検証
+func rejectTogether(fs *pflag.FlagSet, names ...string) error {
+	var given []string
+	for _, name := range names {
+		if fs.Changed(name) {
+			given = append(given, "--"+name)
+		}
+	}
+	if len(given) > 1 {
+		return fmt.Errorf("%s は同時に指定できません", strings.Join(given, " と "))
+	}
+	return nil
+}
+
+// usageError 使用方法の誤り（ヘルプの参照を促す）
+type usageError struct {
+	cmd *command
+	err error
+}
+
+func (e *usageError) Error() string {
+	return fmt.Sprintf("%v\n'%s help %s' で使用方法を表示します", e.err, programName, e.cmd.path())
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// execute コマンドラインを解析してコマンドを実行
+func execute(root *command, args []string) error {
+	cmd := root
+	for len(args) > 0 && len(cmd.Children) > 0 {
+		if args[0] == "-h" || args[0] == "--help" {
+			printCommandHelp(cmd)
+			return nil
+		}
+		child := cmd.child(args[0])
+		if child == nil {
+			if cmd == root {
+				return fmt.Errorf("不明なコマンド: %s\n'%s help' でコマンド一覧を表示します", args[0], programName)
+			}
+			return &usageError{cmd, fmt.Errorf("不明な %s サブコマンド: %s（%s）", cmd.path(), args[0], strings.Join(cmd.childNames(), ", "))}
+		}
+		cmd = child
+		args = args[1:]
+	}
+
+	if cmd.Run == nil {
+		if cmd == root {
+			printUsage(root)
+			return nil
+		}
+		return &usageError{cmd, fmt.Errorf("%s のサブコマンドを指定してください: %s", cmd.path(), strings.Join(cmd.childNames(), ", "))}
+	}
+
+	opts := &options{}
+	fs := cmd.flagSet(opts)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			printCommandHelp(cmd)
+			return nil
+		}
+		return &usageError{cmd, translateFlagError(err)}
+	}
+	if help, _ := fs.GetBool("help"); help {
+		printCommandHelp(cmd)
+		return nil
+	}
+
+	positional := fs.Args()
+	if len(positional) < cmd.MinArgs {
+		return &usageError{cmd, fmt.Errorf("%s の引数が足りません: %s", cmd.path(), cmd.Args)}
+	}
+	if cmd.MaxArgs >= 0 && len(positional) > cmd.MaxArgs {
+		return &usageError{cmd, fmt.Errorf("%s に不要な引数があります: %s", cmd.path(), strings.Join(positional[cmd.MaxArgs:], " "))}
+	}
+
+	if fs.Lookup("output") != nil {
+		if err := validateOutput(opts.output); err != nil {
+			return err
+		}
+		// JSON出力時は進行状況を標準エラー出力に表示
+		if opts.output == OutputJSON {
+			color.Output = os.Stderr
+		}
+	}
+	if cmd.Validate != nil {
+		if err := cmd.Validate(fs, opts); err != nil {
+			return &usageError{cmd, err}
+		}
+	}
+	if opts.configPath == "" {
+		opts.configPath = GetDefaultConfigPath()
+	}
+
+	return cmd.Run(fs, opts, positional)
+}
+
+// translateFlagError pflag のエラーを日本語に変換
+func translateFlagError(err error) error {
+	message := err.Error()
+	switch {
+	case strings.HasPrefix(message, "unknown flag: "):
+		return fmt.Errorf("不明なオプション: %s", strings.TrimPrefix(message, "unknown flag: "))
+	case strings.HasPrefix(message, "unknown shorthand flag: "):
+		return fmt.Errorf("不明なオプション: %s", strings.TrimPrefix(message, "unknown shorthand flag: "))
+	case strings.HasPrefix(message, "flag needs an argument: "):
+		return fmt.Errorf("オプションに値が必要です: %s", strings.TrimPrefix(message, "flag needs an argument: "))
+	}
+	return err
+}
+
+// legacyCommands 旧形式のコマンドオプションと対応するサブコマンド
+var legacyCommands = []struct {
+	flags   []string
+	command []string
+}{
+	{[]string{"--check", "-c"}, []string{"check"}},
+	{[]string{"--apply", "-a"}, []string{"apply"}},
+	{[]string{"--update", "-u"}, []string{"update"}},
+	{[]string{"--restore", "-r"}, []string{"restore"}},
+	{[]string{"--init", "-i"}, []string{"config", "init"}},
+}
+
+// translateLegacyArgs 旧形式（fm24-real --check 等）のコマンドラインをサブコマンド形式に変換
+//
+// コマンドのオプションが複数ある場合（-c -a 等）はエラー。
+func translateLegacyArgs(args []string) ([]string, error) {
+	if len(args) == 0 || !strings.HasPrefix(args[0], "-") {
+		return args, nil
+	}
+
+	var found []string
+	var subcommand []string
+	var rest []string
+	for _, arg := range args {
+		legacy := false
+		for _, entry := range legacyCommands {
+			for _, flag := range entry.flags {
+				if arg == flag {
+					legacy = true
+					found = append(found, arg)
+					subcommand = entry.command
+				}
+			}
+		}
+		if !legacy {
+			rest = append(rest, arg)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return args, nil
+	case 1:
+		color.New(color.FgYellow).Fprintf(os.Stderr, "⚠️  %s は非推奨です。'%s %s' を使用してください\n", found[0], programName, strings.Join(subcommand, " "))
+		return append(append([]string{}, subcommand...), rest...), nil
+	default:
+		return nil, fmt.Errorf("%s は同時に指定できません（'%s help' でコマンド一覧を表示します）", strings.Join(found, " と "), programName)
+	}
+}
+
+// printUsage コマンド一覧を表示
+func printUsage(root *command) {
+	fmt.Printf("Football Manager 実名化ツール v%s（FM24, FM23 対応）\n\n", version)
+	fmt.Println("使用方法:")
+	fmt.Printf("  %s <コマンド> [オプション]\n\n", programName)
+	fmt.Println("コマンド:")
+	for _, cmd := range root.Children {
+		if cmd.Hidden {
+			continue
+		}
+		if len(cmd.Children) == 0 {
+			printCommandRow(cmd)
+			continue
+		}
+		for _, child := range cmd.Children {
+			if !child.Hidden {
+				printCommandRow(child)
+			}
+		}
+	}
+	fmt.Println()
+	fmt.Printf("'%s help <コマンド>' または '%s <コマンド> --help' で各コマンドのオプションを表示します。\n", programName, programName)
+	fmt.Println()
+	fmt.Println("例:")
+	for _, cmd := range root.Children {
+		for _, example := range cmd.Examples {
+			fmt.Printf("  %s\n", example)
+		}
+	}
+	fmt.Println()
+	fmt.Println("終了コード:")
+	fmt.Println("  0  成功 / check: 実名化適用済み")
+	fmt.Println("  1  check: 実名化未適用")
+	fmt.Println("  2  check: 一部のみ適用")
+	fmt.Println("  3  インストールまたはバックアップが見つからない")
+	fmt.Println("  4  ファイルの読み書きエラー")
+	fmt.Println("  5  その他のエラー")
+	fmt.Println()
+	fmt.Println("設定ファイル:")
+	fmt.Printf("  デフォルト: %s\n", GetDefaultConfigPath())
+	fmt.Println()
+}
+
+// commandLine 一覧に表示するコマンド（引数を含む）
+func commandLine(cmd *command) string {
+	if cmd.Args == "" {
+		return cmd.path()
+	}
+	return cmd.path() + " " + cmd.Args
+}
+
+// printCommandRow コマンド一覧の1行を表示（コマンドが長い場合は説明を次の行に表示）
+func printCommandRow(cmd *command) {
+	const column = 30
+	line := commandLine(cmd)
+	width := displayWidth(line)
+	if width >= column {
+		fmt.Printf("  %s\n  %s %s\n", line, strings.Repeat(" ", column), cmd.Summary)
+		return
+	}
+	fmt.Printf("  %s%s %s\n", line, strings.Repeat(" ", column-width), cmd.Summary)
+}
+
+// displayWidth 端末での表示幅（全角文字は2桁）
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if r >= 0x1100 {
+			width += 2
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// printCommandHelp コマンドのヘルプを表示
+func printCommandHelp(cmd *command) {
+	if cmd.Name == "" {
+		printUsage(cmd)
+		return
+	}
+
+	fmt.Println("使用方法:")
+	if len(cmd.Children) > 0 {
+		fmt.Printf("  %s %s <サブコマンド> [オプション]\n\n", programName, cmd.path())
+	} else {
+		fmt.Printf("  %s %s [オプション]\n\n", programName, commandLine(cmd))
+	}
+	fmt.Println(cmd.Summary)
+
+	if len(cmd.Children) > 0 {
+		fmt.Println()
+		fmt.Println("サブコマンド:")
+		for _, child := range cmd.Children {
+			if !child.Hidden {
+				printCommandRow(child)
+			}
+		}
+	} else {
+		fmt.Println()
+		fmt.Println("オプション:")
+		fmt.Print(cmd.flagSet(&options{}).FlagUsages())
+	}
+
+	examples := cmd.Examples
+	for _, child := range cmd.Children {
+		examples = append(examples, child.Examples...)
+	}
+	if len(examples) > 0 {
+		fmt.Println()
+		fmt.Println("例:")
+		for _, example := range examples {
+			fmt.Printf("  %s\n", example)
+		}
+	}
+	fmt.Println()
+}
+
+// helpCommand help <コマンド> でコマンドのヘルプを表示
+func helpCommand(root *command) *command {
+	return &command{
+		Name:     "help",
+		Args:     "[コマンド]",
+		Summary:  "コマンドのヘルプを表示",
+		MaxArgs:  2,
+		Complete: func(opts *options, args []string) []string { return completeCommandPath(root, args) },
+		Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+			cmd := root
+			for _, name := range args {
+				child := cmd.child(name)
+				if child == nil {
+					return fmt.Errorf("不明なコマンド: %s", strings.Join(args, " "))
+				}
+				cmd = child
+			}
+			printCommandHelp(cmd)
+			return nil
+		},
+	}
+}
+
+// completeCommandPath help の引数の補完候補
+func completeCommandPath(root *command, args []string) []string {
+	cmd := root
+	for _, name := range args {
+		if cmd = cmd.child(name); cmd == nil {
+			return nil
+		}
+	}
+	return cmd.childNames()
+}
+
+// completeCommand 補完用の隠しコマンド（__complete <単語数> <単語...>）
+//
+// 補完スクリプトから、コマンド名以降の入力済みの単語と補完中の単語を受け取り、
+// 候補を1行ずつ出力する。候補がない場合、補完スクリプトはファイル名を補完する。
+// 空の単語を渡せないシェルがあるため、先頭で単語数を受け取り、足りない分は空の単語とする。
+func completeCommand(root *command) *command {
+	return &command{
+		Name:    "__complete",
+		Summary: "補完候補を出力（補完スクリプトが使用）",
+		Hidden:  true,
+		MaxArgs: -1,
+		Flags: func(fs *pflag.FlagSet, opts *options) {
+			fs.SetInterspersed(false)
+		},
+		Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+			words := args
+			if len(args) > 0 {
+				if count, err := strconv.Atoi(args[0]); err == nil {
+					words = args[1:]
+					for len(words) < count {
+						words = append(words, "")
+					}
+				}
+			}
+			if len(words) == 0 {
+				words = []string{""}
+			}
+			for _, candidate := range completeWords(root, words) {
+				fmt.Println(candidate)
+			}
+			return nil
+		},
+	}
+}
+
+// completeWords 入力済みの単語（最後が補完中の単語）から補完候補を求める
+func completeWords(root *command, words []string) []string {
+	current := words[len(words)-1]
+	previous := words[:len(words)-1]
+
+	// 入力済みの単語からコマンドを特定
+	cmd := root
+	i := 0
+	for ; i < len(previous) && len(cmd.Children) > 0; i++ {
+		child := cmd.child(previous[i])
+		if child == nil {
+			return nil
+		}
+		cmd = child
+	}
+	if len(cmd.Children) > 0 {
+		return filterPrefix(cmd.childNames(), current)
+	}
+
+	opts := &options{}
+	fs := cmd.flagSet(opts)
+	fs.ParseErrorsWhitelist.UnknownFlags = true
+
+	// 位置引数と、値を待っているオプション
+	var positional []string
+	var pending *pflag.Flag
+	for _, word := range previous[i:] {
+		switch {
+		case pending != nil:
+			pending = nil
+		case word == "=":
+			// bash は --game=fm24 を "--game" "=" "fm24" に分割する
+		case strings.HasPrefix(word, "--") && !strings.Contains(word, "="):
+			if flag := fs.Lookup(strings.TrimPrefix(word, "--")); flag != nil && flag.NoOptDefVal == "" {
+				pending = flag
+			}
+		case strings.HasPrefix(word, "-") && len(word) == 2:
+			if flag := fs.ShorthandLookup(word[1:]); flag != nil && flag.NoOptDefVal == "" {
+				pending = flag
+			}
+		case strings.HasPrefix(word, "-"):
+		default:
+			positional = append(positional, word)
+		}
+	}
+	fs.Parse(previous[i:])
+	if opts.configPath == "" {
+		opts.configPath = GetDefaultConfigPath()
+	}
+
+	if current == "=" {
+		current = ""
+	}
+	if pending != nil {
+		return filterPrefix(completeFlagValue(pending.Name, opts), current)
+	}
+
+	// --name=値
+	if strings.HasPrefix(current, "--") && strings.Contains(current, "=") {
+		name, value, _ := strings.Cut(strings.TrimPrefix(current, "--"), "=")
+		var candidates []string
+		for _, candidate := range filterPrefix(completeFlagValue(name, opts), value) {
+			candidates = append(candidates, "--"+name+"="+candidate)
+		}
+		return candidates
+	}
+
+	if strings.HasPrefix(current, "-") {
+		var names []string
+		fs.VisitAll(func(flag *pflag.Flag) {
+			if !flag.Hidden {
+				names = append(names, "--"+flag.Name)
+			}
+		})
+		return filterPrefix(names, current)
+	}
+
+	if cmd.Complete != nil {
+		return filterPrefix(cmd.Complete(opts, positional), current)
+	}
+	return nil
+}
+
+// completeFlagValue オプションの値の補完候補（ファイル名を補完する場合はnil）
+func completeFlagValue(name string, opts *options) []string {
+	switch name {
+	case "game":
+		var ids []string
+		for _, game := range gameProfiles() {
+			ids = append(ids, game.ID)
+		}
+		return ids
+	case "output":
+		return []string{OutputTable, OutputJSON}
+	case "platform":
+		return knownPlatforms
+	case "profile":
+		return completeProfiles(opts)
+	case "install":
+		return completeInstalls(opts)
+	case "snapshot":
+		return completeBackupIDs(opts)
+	}
+	return nil
+}
+
+// completeProfiles プロファイル名の補完候補
+func completeProfiles(opts *options) []string {
+	config, err := LoadConfig(opts.configPath)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, profile := range config.TargetProfiles() {
+		names = append(names, profile.Name)
+	}
+	return names
+}
+
+// completeInstalls インストールパス名の補完候補（--game を指定した場合はそのゲームのもの）
+func completeInstalls(opts *options) []string {
+	config, err := LoadConfig(opts.configPath)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, installPath := range config.InstallPaths {
+		if opts.gameID == "" || installPath.gameID() == opts.gameID {
+			names = append(names, installPath.Name)
+		}
+	}
+	return names
+}
+
+// completeBackupIDs バックアップIDの補完候補
+func completeBackupIDs(opts *options) []string {
+	config, err := LoadConfig(opts.configPath)
+	if err != nil {
+		return nil
+	}
+	game, err := config.SelectGame(opts.gameID)
+	if err != nil {
+		return nil
+	}
+	tool := &FM24Tool{Config: config, Game: game}
+	root, err := tool.backupRoot()
+	if err != nil {
+		return nil
+	}
+	snapshots, err := tool.gameSnapshots(root)
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, snapshot := range snapshots {
+		ids = append(ids, snapshot.ID)
+	}
+	return ids
+}
+
+// filterPrefix 前方一致する候補
+func filterPrefix(candidates []string, prefix string) []string {
+	var matched []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			matched = append(matched, candidate)
+		}
+	}
+	return matched
+}
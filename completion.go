@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// completionShells 補完スクリプトを生成できるシェル
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// completionScript シェルの補完スクリプト
+//
+// 候補は全て fm24-real __complete が出力する（インストール名・バックアップID・
+// プロファイル名は設定ファイルから取得）。候補がない場合はファイル名を補完する。
+func completionScript(shell string) (string, error) {
+	var script string
+	switch shell {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+	case "powershell":
+		script = powershellCompletion
+	default:
+		return "", fmt.Errorf("不明なシェル: %s（%s）", shell, strings.Join(completionShells, ", "))
+	}
+	return strings.ReplaceAll(script, "@PROGRAM@", programName), nil
+}
+
+const bashCompletion = `# @PROGRAM@ の bash 補完（@PROGRAM@ completion bash で生成）
+# 使い方: source <(@PROGRAM@ completion bash)
+
+_fm24_real() {
+    local IFS=$'\n'
+    COMPREPLY=($(@PROGRAM@ __complete "$COMP_CWORD" "${COMP_WORDS[@]:1:$COMP_CWORD}" 2>/dev/null))
+}
+
+complete -o default -F _fm24_real @PROGRAM@
+`
+
+const zshCompletion = `#compdef @PROGRAM@
+# @PROGRAM@ の zsh 補完（@PROGRAM@ completion zsh で生成）
+# 使い方: @PROGRAM@ completion zsh > "${fpath[1]}/_@PROGRAM@"
+
+_fm24_real() {
+    local -a candidates
+    candidates=("${(@f)$(@PROGRAM@ __complete "$((CURRENT - 1))" "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    candidates=(${candidates:#})
+    if (( ${#candidates} == 0 )); then
+        _files
+        return
+    fi
+    compadd -- "${candidates[@]}"
+}
+
+if [ "$funcstack[1]" = "_fm24_real" ]; then
+    _fm24_real "$@"
+else
+    compdef _fm24_real @PROGRAM@
+fi
+`
+
+const fishCompletion = `# @PROGRAM@ の fish 補完（@PROGRAM@ completion fish で生成）
+# 使い方: @PROGRAM@ completion fish > ~/.config/fish/completions/@PROGRAM@.fish
+
+function __fm24_real_complete
+    set -l words (commandline -opc)
+    set -e words[1]
+    set -l current (commandline -ct)
+    set -l candidates (@PROGRAM@ __complete (math (count $words) + 1) $words $current 2>/dev/null)
+    if test (count $candidates) -eq 0
+        __fish_complete_path $current
+        return
+    end
+    printf '%s\n' $candidates
+end
+
+complete -c @PROGRAM@ -f -a '(__fm24_real_complete)'
+`
+
+const powershellCompletion = `# @PROGRAM@ の PowerShell 補完（@PROGRAM@ completion powershell で生成）
+# 使い方: @PROGRAM@ completion powershell | Out-String | Invoke-Expression
+
+Register-ArgumentCompleter -Native -CommandName '@PROGRAM@', '@PROGRAM@.exe' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $words = @($commandAst.CommandElements |
+        Select-Object -Skip 1 |
+        Where-Object { $_.Extent.EndOffset -le $cursorPosition } |
+        ForEach-Object { $_.ToString() })
+    if ($wordToComplete -eq '') {
+        $words += ''
+    }
+
+    $candidates = @(& '@PROGRAM@' __complete $words.Count @words 2>$null)
+    foreach ($candidate in $candidates) {
+        [System.Management.Automation.CompletionResult]::new($candidate, $candidate, 'ParameterValue', $candidate)
+    }
+}
+`
@@ -4,68 +4,76 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config 設定ファイル構造
 type Config struct {
-	InstallPaths []InstallPath `yaml:"install_paths"`
-	Backup       BackupConfig  `yaml:"backup"`
+	Game         string          `yaml:"game,omitempty"` // 使用するゲーム（fm24, fm23）
+	InstallPaths []InstallPath   `yaml:"install_paths"`
+	Backup       BackupConfig    `yaml:"backup"`
+	Rules        []Rule          `yaml:"rules,omitempty"`      // 組み込みルールへの追加・上書き
+	Profiles     []Profile       `yaml:"profiles,omitempty"`   // 組み込みプロファイルへの追加・上書き
+	RulePacks    RulePacksConfig `yaml:"rule_packs,omitempty"` // ルールパックの保存先と署名検証
+	Epic         EpicConfig      `yaml:"epic,omitempty"`       // Epic Games Launcher のマニフェスト
+	Watch        WatchConfig     `yaml:"watch,omitempty"`      // watch コマンドの監視設定
 }
 
-// InstallPath FM24インストールパス設定
+// InstallPath インストールパス設定
 type InstallPath struct {
 	Name        string `yaml:"name"`
 	Path        string `yaml:"path"`
 	Platform    string `yaml:"platform"`
 	Description string `yaml:"description,omitempty"`
+	Game        string `yaml:"game,omitempty"`    // インストールのゲーム（省略時: fm24）
+	Profile     string `yaml:"profile,omitempty"` // このインストールで使用するプロファイル
 }
 
 // BackupConfig バックアップ設定
 type BackupConfig struct {
-	Enabled   bool   `yaml:"enabled"`
-	Directory string `yaml:"directory,omitempty"`
+	Enabled   bool            `yaml:"enabled"`
+	Directory string          `yaml:"directory,omitempty"`
+	Format    string          `yaml:"format,omitempty"` // dir, zip, tar.gz
+	Retention RetentionConfig `yaml:"retention"`
 }
 
-// DefaultConfig デフォルト設定を生成
+// RetentionConfig バックアップ保持ルール設定
+type RetentionConfig struct {
+	KeepLast    int  `yaml:"keep_last,omitempty"`  // インストールごとに最新N件を保持
+	KeepDays    int  `yaml:"keep_days,omitempty"`  // 指定日数以内のバックアップを保持
+	KeepVanilla bool `yaml:"keep_vanilla"`         // インストールごとの最初のバックアップを常に保持
+	AutoPrune   bool `yaml:"auto_prune,omitempty"` // 適用後に自動で古いバックアップを削除
+}
+
+// WatchConfig 監視設定（0 の場合はデフォルト値）
+type WatchConfig struct {
+	QuietPeriod  time.Duration `yaml:"quiet_period,omitempty"`  // 変更の検出後、再適用まで待つ変更のない時間（例: 60s）
+	PollInterval time.Duration `yaml:"poll_interval,omitempty"` // ポーリングで監視する場合の確認間隔（例: 30s）
+	Poll         bool          `yaml:"poll,omitempty"`          // 変更通知を使わず常にポーリングで監視
+}
+
+// DefaultConfig デフォルト設定を生成（組み込みの全ゲームのインストールパスを含む）
 func DefaultConfig() *Config {
-	home, _ := os.UserHomeDir()
+	var installPaths []InstallPath
+	for _, game := range gameProfiles() {
+		installPaths = append(installPaths, game.defaultInstallPaths()...)
+	}
 
 	return &Config{
-		InstallPaths: []InstallPath{
-			// Windows Steam
-			{
-				Name:        "windows-steam",
-				Path:        `C:\Program Files (x86)\Steam\steamapps\common\Football Manager 2024\data\database\db`,
-				Platform:    "windows",
-				Description: "Windows Steam版",
-			},
-			// Windows Epic Games
-			{
-				Name:        "windows-epic",
-				Path:        `C:\Program Files\Epic Games\Football Manager 2024\data\database\db`,
-				Platform:    "windows",
-				Description: "Windows Epic Games版",
-			},
-			// macOS Steam
-			{
-				Name:        "macos-steam",
-				Path:        filepath.Join(home, "Library/Application Support/Steam/steamapps/common/Football Manager 2024/data/database/db"),
-				Platform:    "darwin",
-				Description: "macOS Steam版",
-			},
-			// macOS App Store
-			{
-				Name:        "macos-appstore",
-				Path:        filepath.Join(home, "Library/Application Support/Sports Interactive/Football Manager 2024/data/database/db"),
-				Platform:    "darwin",
-				Description: "macOS App Store版",
-			},
-		},
+		InstallPaths: installPaths,
 		Backup: BackupConfig{
-			Enabled:   true,
-			Directory: filepath.Join(home, "FM24_Backup"),
+			Enabled: true,
+			Format:  BackupFormatDir,
+			Retention: RetentionConfig{
+				KeepLast:    10,
+				KeepVanilla: true,
+			},
 		},
 	}
 }
@@ -82,20 +90,150 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("設定ファイル読み込みエラー: %w", err)
 	}
 
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	return parseConfig(configPath, data)
+}
+
+// parseConfig 設定ファイルの内容を解析して検証
+func parseConfig(configPath string, data []byte) (*Config, error) {
+	// 設定ファイルで省略された項目のデフォルト値
+	config := Config{
+		Backup: BackupConfig{
+			Enabled: true,
+			Retention: RetentionConfig{
+				KeepVanilla: true,
+			},
+		},
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
 		return nil, fmt.Errorf("設定ファイル解析エラー: %w", err)
 	}
+	if len(root.Content) > 0 {
+		if err := root.Decode(&config); err != nil {
+			return nil, fmt.Errorf("設定ファイル解析エラー: %w", err)
+		}
+	}
 
-	// バックアップディレクトリのデフォルト設定
-	if config.Backup.Directory == "" {
-		home, _ := os.UserHomeDir()
-		config.Backup.Directory = filepath.Join(home, "FM24_Backup")
+	if err := config.validate(configPath, &root); err != nil {
+		return nil, fmt.Errorf("設定ファイル検証エラー: %w", err)
 	}
 
 	return &config, nil
 }
 
+// knownPlatforms install_paths の platform に指定できる値（runtime.GOOS）
+var knownPlatforms = []string{"windows", "darwin", "linux"}
+
+// validate 設定内容を検証し、パスの ~ と環境変数を展開（エラーには設定ファイルの行番号を含める）
+func (c *Config) validate(configPath string, root *yaml.Node) error {
+	items := mappingValue(documentNode(root), "install_paths")
+	names := make(map[string]int)
+
+	for i := range c.InstallPaths {
+		installPath := &c.InstallPaths[i]
+
+		// 各項目の行番号（name, platform, path の値の位置）
+		var item *yaml.Node
+		if items != nil && items.Kind == yaml.SequenceNode && i < len(items.Content) {
+			item = items.Content[i]
+		}
+		line := func(key string) int {
+			if node := mappingValue(item, key); node != nil {
+				return node.Line
+			}
+			if item != nil {
+				return item.Line
+			}
+			return 0
+		}
+
+		if !slices.Contains(knownPlatforms, installPath.Platform) {
+			return fmt.Errorf("%s:%d: install_paths[%d] の platform が不明です: %q（%s）",
+				configPath, line("platform"), i, installPath.Platform, strings.Join(knownPlatforms, ", "))
+		}
+
+		if first, ok := names[installPath.Name]; ok {
+			return fmt.Errorf("%s:%d: install_paths の name が重複しています: %q（%d行目で定義済み）",
+				configPath, line("name"), installPath.Name, first)
+		}
+		names[installPath.Name] = line("name")
+
+		// 他のOS向けのパスは、そのOSにしかない環境変数を参照しうるため展開できなくてもエラーにしない
+		path, err := expandPath(installPath.Path)
+		if err != nil {
+			if installPath.Platform == runtime.GOOS {
+				return fmt.Errorf("%s:%d: install_paths[%d] の path: %w", configPath, line("path"), i, err)
+			}
+			continue
+		}
+		installPath.Path = path
+	}
+
+	if c.Backup.Directory != "" {
+		dir, err := expandPath(c.Backup.Directory)
+		if err != nil {
+			line := 0
+			if node := mappingValue(mappingValue(documentNode(root), "backup"), "directory"); node != nil {
+				line = node.Line
+			}
+			return fmt.Errorf("%s:%d: backup.directory: %w", configPath, line, err)
+		}
+		c.Backup.Directory = dir
+	}
+
+	for _, duration := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"quiet_period", c.Watch.QuietPeriod},
+		{"poll_interval", c.Watch.PollInterval},
+	} {
+		if duration.value < 0 {
+			line := 0
+			if node := mappingValue(mappingValue(documentNode(root), "watch"), duration.key); node != nil {
+				line = node.Line
+			}
+			return fmt.Errorf("%s:%d: watch.%s には0以上の時間を指定してください: %s", configPath, line, duration.key, duration.value)
+		}
+	}
+
+	return nil
+}
+
+// documentNode YAML文書のトップレベルのノード
+func documentNode(root *yaml.Node) *yaml.Node {
+	if root != nil && root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		return root.Content[0]
+	}
+	return root
+}
+
+// mappingValue マッピングノードのキーに対応する値のノード（存在しない場合はnil）
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mappingKey マッピングノードのキーのノード（存在しない場合はnil）
+func mappingKey(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
 // SaveConfig 設定ファイルを保存
 func SaveConfig(configPath string, config *Config) error {
 	data, err := yaml.Marshal(config)
@@ -116,25 +254,58 @@ func SaveConfig(configPath string, config *Config) error {
 	return nil
 }
 
+// envVarPattern パス中の環境変数の参照（${VAR}, $VAR, %VAR%）
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)|%([A-Za-z_][A-Za-z0-9_()]*)%`)
+
+// expandPath 先頭の ~ と環境変数（${VAR}, $VAR, %VAR%）を展開
+//
+// HOME と USERPROFILE は未設定の場合もホームディレクトリとして扱う。
+// 未設定の環境変数を参照している場合はエラー。
+func expandPath(path string) (string, error) {
+	var expandErr error
+	path = envVarPattern.ReplaceAllStringFunc(path, func(ref string) string {
+		m := envVarPattern.FindStringSubmatch(ref)
+		name := m[1] + m[2] + m[3]
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		if strings.EqualFold(name, "HOME") || strings.EqualFold(name, "USERPROFILE") {
+			if home, err := os.UserHomeDir(); err == nil {
+				return home
+			}
+		}
+		if expandErr == nil {
+			expandErr = fmt.Errorf("環境変数 %s が設定されていません: %s", name, ref)
+		}
+		return ref
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
 // GetDefaultConfigPath デフォルト設定ファイルパスを取得
 func GetDefaultConfigPath() string {
 	home, _ := os.UserHomeDir()
 	return filepath.Join(home, ".config", "fm24-real", "config.yaml")
 }
 
-// GenerateDefaultConfig デフォルト設定ファイルを生成
-func GenerateDefaultConfig() error {
-	configPath := GetDefaultConfigPath()
-
+// GenerateDefaultConfig デフォルト設定ファイルを生成（assumeYes の場合は確認なしで上書き）
+func GenerateDefaultConfig(configPath string, assumeYes bool) error {
 	// 既に存在する場合は上書き確認
 	if _, err := os.Stat(configPath); err == nil {
 		fmt.Printf("設定ファイルが既に存在します: %s\n", configPath)
-		fmt.Print("上書きしますか? (y/n): ")
-		var response string
-		fmt.Scanln(&response)
-		if response != "y" && response != "Y" {
-			fmt.Println("❌ キャンセルしました")
-			return nil
+		if !confirm("上書きしますか? (y/n): ", assumeYes) {
+			return errCancelled
 		}
 	}
 
@@ -147,6 +318,16 @@ func GenerateDefaultConfig() error {
 	return nil
 }
 
+// findInstallPath 名前からインストールパスを検索
+func (c *Config) findInstallPath(name string) (*InstallPath, error) {
+	for i := range c.InstallPaths {
+		if c.InstallPaths[i].Name == name {
+			return &c.InstallPaths[i], nil
+		}
+	}
+	return nil, notFoundf("設定ファイルにインストールパス %s がありません", name)
+}
+
 // FindInstallPathFromConfig 設定ファイルからインストールパスを検出
 func FindInstallPathFromConfig(config *Config, customPath string) (string, error) {
 	// カスタムパスが指定されている場合
@@ -154,7 +335,7 @@ func FindInstallPathFromConfig(config *Config, customPath string) (string, error
 		if _, err := os.Stat(customPath); err == nil {
 			return customPath, nil
 		}
-		return "", fmt.Errorf("指定されたパスが存在しません: %s", customPath)
+		return "", notFoundf("指定されたパスが存在しません: %s", customPath)
 	}
 
 	// 設定ファイルから検索
@@ -164,5 +345,5 @@ func FindInstallPathFromConfig(config *Config, customPath string) (string, error
 		}
 	}
 
-	return "", fmt.Errorf("FM24のインストールが見つかりません")
+	return "", notFoundf("FM24のインストールが見つかりません")
 }
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("FM_GAMES", "/games")
+	t.Setenv("ProgramFiles(x86)", `C:\Program Files (x86)`)
+	t.Setenv("USERPROFILE", "")
+	os.Unsetenv("USERPROFILE")
+	t.Setenv("FM_UNSET", "")
+	os.Unsetenv("FM_UNSET")
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr string
+	}{
+		{path: "/games/fm24/db", want: "/games/fm24/db"},
+		{path: "", want: ""},
+		{path: "~", want: home},
+		{path: "~/FM24_Backup", want: filepath.Join(home, "FM24_Backup")},
+		{path: "~user/FM24_Backup", want: "~user/FM24_Backup"},
+		{path: "/games/~/db", want: "/games/~/db"},
+		{path: "$FM_GAMES/fm24", want: "/games/fm24"},
+		{path: "${FM_GAMES}fm24", want: "/gamesfm24"},
+		{path: "$HOME/.steam", want: home + "/.steam"},
+		{path: `%ProgramFiles(x86)%\Steam`, want: `C:\Program Files (x86)\Steam`},
+		{path: `%USERPROFILE%\Documents`, want: home + `\Documents`},
+		{path: "100%/db", want: "100%/db"},
+		{path: "$FM_UNSET/db", wantErr: "環境変数 FM_UNSET が設定されていません: $FM_UNSET"},
+		{path: "${FM_UNSET}/db", wantErr: "環境変数 FM_UNSET が設定されていません: ${FM_UNSET}"},
+		{path: "%FM_UNSET%/db", wantErr: "環境変数 FM_UNSET が設定されていません: %FM_UNSET%"},
+	}
+
+	for _, tt := range tests {
+		got, err := expandPath(tt.path)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("expandPath(%q) error = %v, want %q", tt.path, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expandPath(%q): %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("expandPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("FM_UNSET", "")
+	os.Unsetenv("FM_UNSET")
+	other := "windows"
+	if runtime.GOOS == "windows" {
+		other = "linux"
+	}
+
+	config, err := parseConfig("config.yaml", []byte(`install_paths:
+  - name: steam
+    path: ~/games/fm24/db
+    platform: `+runtime.GOOS+`
+  - name: other
+    path: $FM_UNSET/fm24/db
+    platform: `+other+`
+backup:
+  directory: ~/FM24_Backup
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "games", "fm24", "db"); config.InstallPaths[0].Path != want {
+		t.Errorf("install_paths[0].path = %q, want %q", config.InstallPaths[0].Path, want)
+	}
+	// 他のOS向けのパスは展開できなくてもそのまま
+	if config.InstallPaths[1].Path != "$FM_UNSET/fm24/db" {
+		t.Errorf("install_paths[1].path = %q", config.InstallPaths[1].Path)
+	}
+	if want := filepath.Join(home, "FM24_Backup"); config.Backup.Directory != want {
+		t.Errorf("backup.directory = %q, want %q", config.Backup.Directory, want)
+	}
+	// 省略された項目のデフォルト値
+	if !config.Backup.Enabled || !config.Backup.Retention.KeepVanilla {
+		t.Errorf("backup のデフォルト値が設定されていません: %+v", config.Backup)
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	t.Setenv("FM_UNSET", "")
+	os.Unsetenv("FM_UNSET")
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "YAMLの構文エラー",
+			input:   "install_paths:\n  - name: [steam\n",
+			wantErr: "設定ファイル解析エラー",
+		},
+		{
+			name: "不明なplatform",
+			input: `install_paths:
+  - name: steam
+    path: /games/fm24/db
+    platform: steamdeck
+`,
+			wantErr: `config.yaml:4: install_paths[0] の platform が不明です: "steamdeck"`,
+		},
+		{
+			name: "platformなし",
+			input: `install_paths:
+  - name: steam
+    path: /games/fm24/db
+`,
+			wantErr: `config.yaml:2: install_paths[0] の platform が不明です: ""`,
+		},
+		{
+			name: "nameの重複",
+			input: `install_paths:
+  - name: steam
+    path: /games/fm24/db
+    platform: linux
+  - name: epic
+    path: /games/epic/db
+    platform: linux
+
+  - name: steam
+    path: /games/fm24-2/db
+    platform: windows
+`,
+			wantErr: `config.yaml:9: install_paths の name が重複しています: "steam"（2行目で定義済み）`,
+		},
+		{
+			name: "未設定の環境変数",
+			input: `install_paths:
+  - name: steam
+    platform: ` + runtime.GOOS + `
+    path: ${FM_UNSET}/fm24/db
+`,
+			wantErr: "config.yaml:4: install_paths[0] の path: 環境変数 FM_UNSET が設定されていません",
+		},
+		{
+			name: "backup.directory の未設定の環境変数",
+			input: `install_paths: []
+backup:
+  enabled: true
+  directory: $FM_UNSET/FM24_Backup
+`,
+			wantErr: "config.yaml:4: backup.directory: 環境変数 FM_UNSET が設定されていません",
+		},
+		{
+			name: "負の監視間隔",
+			input: `watch:
+  quiet_period: 60s
+  poll_interval: -30s
+`,
+			wantErr: "config.yaml:3: watch.poll_interval には0以上の時間を指定してください: -30s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConfig("config.yaml", []byte(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	config, err := LoadConfig(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.InstallPaths) == 0 || !config.Backup.Enabled {
+		t.Errorf("設定ファイルがない場合にデフォルト設定になりません: %+v", config)
+	}
+}
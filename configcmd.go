@@ -0,0 +1,500 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// 設定値の定義元
+const (
+	configSourceDefault = "default" // デフォルト値
+	configSourceFile    = "config"  // 設定ファイル
+	configSourceFlag    = "flag:"   // コマンドラインオプション（例: flag:--game）
+)
+
+// configValue 有効な設定値と定義元
+type configValue struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`         // default, config, flag:--<名前>
+	Line   int         `json:"line,omitempty"` // 設定ファイルの行番号
+}
+
+// configInstallPath 有効なインストールパスと定義元
+type configInstallPath struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Platform    string `json:"platform"`
+	Description string `json:"description,omitempty"`
+	Game        string `json:"game"`
+	Profile     string `json:"profile,omitempty"`
+	Source      string `json:"source"`
+	Line        int    `json:"line,omitempty"`
+}
+
+// configProfile 有効なプロファイルと定義元
+type configProfile struct {
+	Profile
+	Source string `json:"source"` // builtin, config
+	Line   int    `json:"line,omitempty"`
+}
+
+// configRule 有効なルールと定義元
+type configRule struct {
+	Rule
+	Line int `json:"line,omitempty"`
+}
+
+// ConfigView config show の出力
+type ConfigView struct {
+	Path         string              `json:"path"`
+	Exists       bool                `json:"exists"`
+	Game         string              `json:"game"`
+	Settings     []configValue       `json:"settings"`
+	InstallPaths []configInstallPath `json:"install_paths"`
+	Profiles     []configProfile     `json:"profiles"`
+	Rules        []configRule        `json:"rules"`
+}
+
+// sourceLabel 定義元の表示名
+func sourceLabel(source string, line int) string {
+	switch {
+	case source == configSourceDefault:
+		return "デフォルト"
+	case source == configSourceFile:
+		return fmt.Sprintf("設定ファイル %d行目", line)
+	case source == ruleSourceBuiltin:
+		return "組み込み"
+	case source == ruleSourceConfig:
+		return fmt.Sprintf("設定ファイル %d行目", line)
+	case strings.HasPrefix(source, "pack:"):
+		return "ルールパック " + strings.TrimPrefix(source, "pack:")
+	case strings.HasPrefix(source, configSourceFlag):
+		return strings.TrimPrefix(source, configSourceFlag)
+	}
+	return source
+}
+
+// loadConfigNode 定義元の行番号を調べるために設定ファイルをノードとして読み込み（ない場合はnil）
+func loadConfigNode(path string) *yaml.Node {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil
+	}
+	return &root
+}
+
+// nodeAt キーのパスに対応する値のノード（存在しない場合はnil）
+func nodeAt(root *yaml.Node, keys ...string) *yaml.Node {
+	node := documentNode(root)
+	for _, key := range keys {
+		node = mappingValue(node, key)
+	}
+	return node
+}
+
+// namedItemLine シーケンスから name が一致する項目の行番号（ない場合は0）
+func namedItemLine(items *yaml.Node, name string) int {
+	if items == nil || items.Kind != yaml.SequenceNode {
+		return 0
+	}
+	for _, item := range items.Content {
+		if node := mappingValue(item, "name"); node != nil && node.Value == name {
+			return item.Line
+		}
+	}
+	return 0
+}
+
+// ShowConfig デフォルト値・設定ファイル・コマンドラインオプションを反映した有効な設定と定義元を表示
+func ShowConfig(configPath, gameFlag, profileFlag, output string) error {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	game, err := config.SelectGame(gameFlag)
+	if err != nil {
+		return err
+	}
+
+	root := loadConfigNode(configPath)
+	view := &ConfigView{Path: configPath, Exists: root != nil, Game: game.ID}
+
+	// 設定ファイルにあれば設定ファイル、なければデフォルト値
+	add := func(key string, value interface{}, keys ...string) {
+		entry := configValue{Key: key, Value: value, Source: configSourceDefault}
+		if node := nodeAt(root, keys...); len(keys) > 0 && node != nil {
+			entry.Source = configSourceFile
+			entry.Line = node.Line
+		}
+		view.Settings = append(view.Settings, entry)
+	}
+	// コマンドラインオプションが優先される設定
+	addWithFlag := func(key string, value interface{}, flag string, keys ...string) {
+		if flag != "" {
+			view.Settings = append(view.Settings, configValue{Key: key, Value: value, Source: configSourceFlag + flag})
+			return
+		}
+		add(key, value, keys...)
+	}
+
+	gameOverride := ""
+	if gameFlag != "" {
+		gameOverride = "--game"
+	}
+	addWithFlag("game", game.ID, gameOverride, "game")
+
+	profile := defaultProfileName
+	profileOverride := ""
+	if profileFlag != "" {
+		profile = profileFlag
+		profileOverride = "--profile"
+	}
+	addWithFlag("profile", profile, profileOverride)
+
+	backupDir := config.Backup.Directory
+	if backupDir == "" {
+		backupDir, _ = expandPath("~/" + game.backupDirName())
+	}
+	backupFormat := config.Backup.Format
+	if backupFormat == "" {
+		backupFormat = BackupFormatDir
+	}
+	add("backup.enabled", config.Backup.Enabled, "backup", "enabled")
+	add("backup.directory", backupDir, "backup", "directory")
+	add("backup.format", backupFormat, "backup", "format")
+	add("backup.retention.keep_last", config.Backup.Retention.KeepLast, "backup", "retention", "keep_last")
+	add("backup.retention.keep_days", config.Backup.Retention.KeepDays, "backup", "retention", "keep_days")
+	add("backup.retention.keep_vanilla", config.Backup.Retention.KeepVanilla, "backup", "retention", "keep_vanilla")
+	add("backup.retention.auto_prune", config.Backup.Retention.AutoPrune, "backup", "retention", "auto_prune")
+
+	packsDir, _ := config.rulePacksDir()
+	trustedKeys := config.RulePacks.TrustedKeys
+	if trustedKeys == nil {
+		trustedKeys = []string{}
+	}
+	add("rule_packs.directory", packsDir, "rule_packs", "directory")
+	add("rule_packs.trusted_keys", trustedKeys, "rule_packs", "trusted_keys")
+	add("rule_packs.allow_unsigned", config.RulePacks.AllowUnsigned, "rule_packs", "allow_unsigned")
+	add("epic.manifest_dirs", config.epicManifestDirs(), "epic", "manifest_dir")
+	add("watch.quiet_period", config.Watch.quietPeriod().String(), "watch", "quiet_period")
+	add("watch.poll_interval", config.Watch.pollInterval().String(), "watch", "poll_interval")
+	add("watch.poll", config.Watch.Poll, "watch", "poll")
+
+	// インストールパス（設定ファイルがない場合はデフォルト設定のもの）
+	for _, installPath := range config.InstallPaths {
+		entry := configInstallPath{
+			Name:        installPath.Name,
+			Path:        installPath.Path,
+			Platform:    installPath.Platform,
+			Description: installPath.Description,
+			Game:        installPath.gameID(),
+			Profile:     installPath.Profile,
+			Source:      configSourceDefault,
+		}
+		if line := namedItemLine(nodeAt(root, "install_paths"), installPath.Name); line > 0 {
+			entry.Source = configSourceFile
+			entry.Line = line
+		}
+		view.InstallPaths = append(view.InstallPaths, entry)
+	}
+
+	// プロファイル（組み込みと設定ファイルのもの）
+	for _, profile := range config.TargetProfiles() {
+		entry := configProfile{Profile: profile, Source: ruleSourceBuiltin}
+		if line := namedItemLine(nodeAt(root, "profiles"), profile.Name); line > 0 {
+			entry.Source = ruleSourceConfig
+			entry.Line = line
+		}
+		view.Profiles = append(view.Profiles, entry)
+	}
+
+	// ルール（組み込み・ルールパック・設定ファイルを統合したもの、無効化されたものを含む）
+	packs, err := config.LoadRulePacks()
+	if err != nil {
+		return err
+	}
+	for _, rule := range config.mergedRules(game, packs) {
+		entry := configRule{Rule: rule}
+		if rule.Source == ruleSourceConfig {
+			entry.Line = namedItemLine(nodeAt(root, "rules"), rule.Name)
+		}
+		view.Rules = append(view.Rules, entry)
+	}
+
+	if output == OutputJSON {
+		return printJSON(view)
+	}
+	printConfigView(view)
+	return nil
+}
+
+// printConfigView 有効な設定を表示
+func printConfigView(view *ConfigView) {
+	color.Cyan("==========================================================")
+	color.Cyan("有効な設定")
+	color.Cyan("==========================================================\n")
+
+	if view.Exists {
+		logf("設定ファイル: %s\n\n", view.Path)
+	} else {
+		logf("設定ファイル: %s（なし、デフォルト設定を使用）\n\n", view.Path)
+	}
+
+	for _, entry := range view.Settings {
+		value := fmt.Sprint(entry.Value)
+		if values, ok := entry.Value.([]string); ok {
+			value = "[" + strings.Join(values, ", ") + "]"
+		}
+		logf("  %-30s  %-40s  %s\n", entry.Key, value, sourceLabel(entry.Source, entry.Line))
+	}
+
+	logf("\nインストールパス:\n")
+	if len(view.InstallPaths) == 0 {
+		logln("  なし")
+	}
+	for _, installPath := range view.InstallPaths {
+		profile := installPath.Profile
+		if profile == "" {
+			profile = "-"
+		}
+		logf("  %-28s  %-8s  %-5s  %-12s  %s\n", installPath.Name, installPath.Platform, installPath.Game, profile,
+			sourceLabel(installPath.Source, installPath.Line))
+		logf("      %s\n", installPath.Path)
+	}
+
+	logf("\nプロファイル:\n")
+	for _, profile := range view.Profiles {
+		logf("  %-28s  %s\n", profile.Name, sourceLabel(profile.Source, profile.Line))
+	}
+
+	logf("\n削除対象ルール（%s）:\n", strings.ToUpper(view.Game))
+	for _, rule := range view.Rules {
+		name := rule.Name
+		if rule.Disabled {
+			name += "（無効）"
+		}
+		logf("  %-28s  %-12s  %s\n", name, rule.Category, sourceLabel(rule.Source, rule.Line))
+	}
+}
+
+// ValidateConfig 設定ファイルの内容と、設定されたパスが存在するかを検証
+func ValidateConfig(configPath string) error {
+	color.Cyan("==========================================================")
+	color.Cyan("設定ファイル検証")
+	color.Cyan("==========================================================\n")
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		logf("設定ファイル: %s（なし、デフォルト設定を検証）\n\n", configPath)
+	} else {
+		logf("設定ファイル: %s\n\n", configPath)
+	}
+
+	problems := 0
+	fail := func(format string, args ...interface{}) {
+		color.Red("  ✗ "+format, args...)
+		problems++
+	}
+
+	// 構文、platform、name の重複、環境変数の展開
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		fail("%v", err)
+		return fmt.Errorf("設定ファイルに誤りがあります")
+	}
+	color.Green("  ✓ 構文（install_paths の platform・name、パスの環境変数）")
+
+	game, err := config.SelectGame("")
+	if err != nil {
+		fail("game: %v", err)
+		game, _ = findGame(defaultGameID)
+	} else {
+		color.Green("  ✓ game: %s", game.ID)
+	}
+
+	backupFormat := config.Backup.Format
+	if backupFormat == "" {
+		backupFormat = BackupFormatDir
+	}
+	if _, err := snapshotExtension(backupFormat); err != nil {
+		fail("backup.format: %v", err)
+	} else {
+		color.Green("  ✓ backup.format: %s", backupFormat)
+	}
+
+	// ルールパックの署名、各ゲームのルールとプロファイル
+	packs, err := config.LoadRulePacks()
+	if err != nil {
+		fail("%v", err)
+	} else {
+		for _, profile := range gameProfiles() {
+			if _, err := config.TargetRules(profile, packs); err != nil {
+				fail("%s のルール: %v", profile.Short, err)
+				continue
+			}
+			if err := config.validateProfiles(profile, packs); err != nil {
+				fail("%s のプロファイル: %v", profile.Short, err)
+				continue
+			}
+			color.Green("  ✓ %s のルール・プロファイル", profile.Short)
+		}
+	}
+
+	// インストールパス（現在のOSのもののみ存在を確認）
+	logf("\nインストールパス:\n")
+	if len(config.InstallPaths) == 0 {
+		logln("  なし")
+	}
+	for _, installPath := range config.InstallPaths {
+		if _, err := findGame(installPath.gameID()); err != nil {
+			fail("%s: %v", installPath.Name, err)
+			continue
+		}
+		if installPath.Platform != runtime.GOOS {
+			color.White("  - %s: 他のOS（%s）のため確認しません", installPath.Name, installPath.Platform)
+			continue
+		}
+		if _, err := os.Stat(installPath.Path); err != nil {
+			color.Yellow("  ⚠ %s: パスが存在しません: %s", installPath.Name, installPath.Path)
+			continue
+		}
+		versions, err := listVersionFolders(installPath.Path)
+		if err != nil {
+			color.Yellow("  ⚠ %s: DBバージョンフォルダがありません: %s", installPath.Name, installPath.Path)
+			continue
+		}
+		color.Green("  ✓ %s: %s (DB %s)", installPath.Name, installPath.Path, strings.Join(versions, ", "))
+	}
+
+	// 保存先のディレクトリ（未作成の場合は初回使用時に作成される）
+	logf("\nディレクトリ:\n")
+	backupDir := config.Backup.Directory
+	if backupDir == "" {
+		backupDir, _ = expandPath("~/" + game.backupDirName())
+	}
+	packsDir, _ := config.rulePacksDir()
+	for _, dir := range []struct{ key, path string }{
+		{"backup.directory", backupDir},
+		{"rule_packs.directory", packsDir},
+	} {
+		if _, err := os.Stat(dir.path); err == nil {
+			color.Green("  ✓ %s: %s", dir.key, dir.path)
+		} else {
+			color.White("  - %s: %s（未作成、初回使用時に作成）", dir.key, dir.path)
+		}
+	}
+	if config.Epic.ManifestDir != "" {
+		dirs := config.epicManifestDirs()
+		if _, err := os.Stat(dirs[0]); err == nil {
+			color.Green("  ✓ epic.manifest_dir: %s", dirs[0])
+		} else {
+			color.Yellow("  ⚠ epic.manifest_dir: ディレクトリが存在しません: %s", dirs[0])
+		}
+	}
+
+	logln()
+	if problems > 0 {
+		return fmt.Errorf("設定ファイルに%d件の誤りがあります", problems)
+	}
+	color.Green("✅ 設定ファイルに誤りはありません")
+	return nil
+}
+
+// AddInstallPath 設定ファイルにインストールパスを追加（コメントと書式は保持）
+func AddInstallPath(configPath string, installPath InstallPath) error {
+	if installPath.Name == "" || installPath.Path == "" {
+		return fmt.Errorf("インストールパスの名前とパスを指定してください")
+	}
+	if installPath.Platform == "" {
+		installPath.Platform = runtime.GOOS
+	}
+	if !slices.Contains(knownPlatforms, installPath.Platform) {
+		return fmt.Errorf("不明なプラットフォーム: %s（%s）", installPath.Platform, strings.Join(knownPlatforms, ", "))
+	}
+
+	// ~ や環境変数を含まない相対パスは絶対パスにする
+	if !filepath.IsAbs(installPath.Path) && !strings.ContainsAny(installPath.Path, "~$%") {
+		if absPath, err := filepath.Abs(installPath.Path); err == nil {
+			installPath.Path = absPath
+		}
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		logf("設定ファイルがないため、デフォルト設定で作成します: %s\n", configPath)
+		if err := SaveConfig(configPath, DefaultConfig()); err != nil {
+			return err
+		}
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	for _, existing := range config.InstallPaths {
+		if existing.Name == installPath.Name {
+			return fmt.Errorf("インストールパス %s は既に存在します（config remove-install で削除してから追加してください）", installPath.Name)
+		}
+	}
+	if _, err := findGame(installPath.gameID()); err != nil {
+		return err
+	}
+	if installPath.Profile != "" {
+		if _, err := config.findProfile(installPath.Profile); err != nil {
+			return err
+		}
+	}
+
+	doc, err := loadConfigDocument(configPath)
+	if err != nil {
+		return err
+	}
+	if err := doc.addInstallPath(installPath); err != nil {
+		return err
+	}
+	if err := doc.save(); err != nil {
+		return err
+	}
+
+	color.Green("✅ インストールパスを追加しました: %s", installPath.Name)
+	logf("パス: %s (%s)\n", installPath.Path, installPath.Platform)
+	if installPath.Platform == runtime.GOOS {
+		if path, err := expandPath(installPath.Path); err == nil {
+			if _, err := os.Stat(path); err != nil {
+				color.Yellow("⚠️  パスが存在しません: %s", path)
+			}
+		}
+	}
+	return nil
+}
+
+// RemoveInstallPath 設定ファイルからインストールパスを削除（コメントと書式は保持）
+func RemoveInstallPath(configPath, name string) error {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return notFoundf("設定ファイルがありません: %s", configPath)
+	}
+
+	doc, err := loadConfigDocument(configPath)
+	if err != nil {
+		return err
+	}
+	if err := doc.removeInstallPath(name); err != nil {
+		return err
+	}
+	if err := doc.save(); err != nil {
+		return err
+	}
+
+	color.Green("✅ インストールパスを削除しました: %s", name)
+	return nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configDocument コメントと書式を保ったまま編集する設定ファイル
+//
+// yaml.Node で編集箇所の行を特定し、元のテキストに対して行単位で挿入・削除する。
+// ノードを書き直すと空行やコメントの位置揃えが失われるため、行単位で編集できない
+// 形（フロースタイル、空の install_paths）の場合のみノードを編集して書き出す。
+type configDocument struct {
+	path    string
+	lines   []string // 改行を除いた各行（最後の要素は末尾の改行の後ろ）
+	newline string   // 改行コード（\n または \r\n）
+	root    yaml.Node
+}
+
+// loadConfigDocument 編集用に設定ファイルを読み込み
+func loadConfigDocument(path string) (*configDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("設定ファイル読み込みエラー: %w", err)
+	}
+
+	doc := &configDocument{path: path, newline: "\n"}
+	if bytes.Contains(data, []byte("\r\n")) {
+		doc.newline = "\r\n"
+	}
+	doc.lines = strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	if err := yaml.Unmarshal(data, &doc.root); err != nil {
+		return nil, fmt.Errorf("設定ファイル解析エラー: %w", err)
+	}
+	if doc.root.Kind == 0 {
+		// 空のファイル
+		doc.root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if documentNode(&doc.root).Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("設定ファイル解析エラー: トップレベルがマッピングではありません")
+	}
+
+	return doc, nil
+}
+
+// bytes 編集後の内容
+func (d *configDocument) bytes() []byte {
+	return []byte(strings.Join(d.lines, d.newline))
+}
+
+// installPathItems install_paths のシーケンスノード（ない場合はnil）
+func (d *configDocument) installPathItems() *yaml.Node {
+	return mappingValue(documentNode(&d.root), "install_paths")
+}
+
+// isBlockSequence 行単位で編集できる（1件以上の項目を持つブロックスタイルの）シーケンスか
+func isBlockSequence(node *yaml.Node) bool {
+	return node != nil && node.Kind == yaml.SequenceNode && node.Style&yaml.FlowStyle == 0 && len(node.Content) > 0
+}
+
+// addInstallPath install_paths の末尾に項目を追加
+func (d *configDocument) addInstallPath(installPath InstallPath) error {
+	item, err := renderYAML([]InstallPath{installPath})
+	if err != nil {
+		return err
+	}
+
+	items := d.installPathItems()
+	switch {
+	case items == nil:
+		// install_paths がない場合はファイルの末尾に追加
+		block := append([]string{"install_paths:"}, indentLines(item, "  ")...)
+		end := len(d.lines)
+		if end > 0 && d.lines[end-1] == "" {
+			end--
+		}
+		if end > 0 && strings.TrimSpace(d.lines[end-1]) != "" {
+			block = append([]string{""}, block...)
+		}
+		d.insertLines(end, block)
+		return nil
+
+	case isBlockSequence(items):
+		// 最後の項目の直後に、既存の項目と同じインデントで追加
+		last := items.Content[len(items.Content)-1]
+		d.insertLines(lastLine(last), indentLines(item, dashIndent(d.lines[last.Line-1])))
+		return nil
+
+	default:
+		var node yaml.Node
+		if err := node.Encode(installPath); err != nil {
+			return fmt.Errorf("設定ファイル生成エラー: %w", err)
+		}
+		// 値の行末コメント（install_paths: [] # ...）はブロックスタイルでは項目の後ろに出力されるため、キーに移す
+		if key := mappingKey(documentNode(&d.root), "install_paths"); key != nil && key.LineComment == "" {
+			key.LineComment, items.LineComment = items.LineComment, ""
+		}
+		if items.Kind != yaml.SequenceNode {
+			*items = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		}
+		items.Style = 0
+		items.Content = append(items.Content, &node)
+		return d.rewrite()
+	}
+}
+
+// removeInstallPath install_paths から名前の一致する項目を削除
+func (d *configDocument) removeInstallPath(name string) error {
+	items := d.installPathItems()
+	index := -1
+	if items != nil && items.Kind == yaml.SequenceNode {
+		for i, item := range items.Content {
+			if node := mappingValue(item, "name"); node != nil && node.Value == name {
+				index = i
+				break
+			}
+		}
+	}
+	if index < 0 {
+		return notFoundf("インストールパスが見つかりません: %s", name)
+	}
+
+	if !isBlockSequence(items) {
+		items.Content = append(items.Content[:index], items.Content[index+1:]...)
+		return d.rewrite()
+	}
+
+	item := items.Content[index]
+	start := item.Line - 1 // 0始まり
+	end := lastLine(item)
+
+	// 直前の同じインデントのコメント（この項目の説明）も削除
+	indent := dashIndent(d.lines[start])
+	for start > 0 && isCommentLine(d.lines[start-1], indent) {
+		start--
+	}
+
+	d.lines = append(d.lines[:start], d.lines[end:]...)
+
+	// 削除によって空行が連続する場合は1行にまとめる
+	if start > 0 && start < len(d.lines) && strings.TrimSpace(d.lines[start-1]) == "" && strings.TrimSpace(d.lines[start]) == "" {
+		d.lines = append(d.lines[:start], d.lines[start+1:]...)
+	}
+
+	return nil
+}
+
+// rewrite 編集したノードから設定ファイル全体を書き出す（コメントは保持されるが空行は失われる）
+func (d *configDocument) rewrite() error {
+	data, err := renderYAML(&d.root)
+	if err != nil {
+		return err
+	}
+	d.lines = append(data, "")
+	return nil
+}
+
+// insertLines 指定位置（0始まり）に行を挿入
+func (d *configDocument) insertLines(at int, lines []string) {
+	d.lines = append(d.lines[:at], append(lines, d.lines[at:]...)...)
+}
+
+// save 編集後の内容を検証して保存（一時ファイルに書き込んでから置き換える）
+func (d *configDocument) save() error {
+	data := d.bytes()
+	if _, err := parseConfig(d.path, data); err != nil {
+		return fmt.Errorf("編集後の設定ファイルが不正です: %w", err)
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(d.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if err := writeFileAtomic(d.path, data, mode); err != nil {
+		return fmt.Errorf("設定ファイル保存エラー: %w", err)
+	}
+	return nil
+}
+
+// renderYAML 値をインデント2のYAMLとして行ごとに出力
+func renderYAML(v interface{}) ([]string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("設定ファイル生成エラー: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("設定ファイル生成エラー: %w", err)
+	}
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"), nil
+}
+
+// lastLine ノードが占める最後の行（1始まり）
+func lastLine(node *yaml.Node) int {
+	line := node.Line
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		line += strings.Count(strings.TrimSuffix(node.Value, "\n"), "\n") + 1
+	}
+	for _, child := range node.Content {
+		if l := lastLine(child); l > line {
+			line = l
+		}
+	}
+	return line
+}
+
+// dashIndent シーケンス項目の行の "-" より前のインデント
+func dashIndent(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// isCommentLine 指定したインデントのコメント行か
+func isCommentLine(line, indent string) bool {
+	return strings.HasPrefix(line, indent+"#")
+}
+
+// indentLines 各行の先頭にインデントを追加
+func indentLines(lines []string, indent string) []string {
+	indented := make([]string, len(lines))
+	for i, line := range lines {
+		indented[i] = indent + line
+	}
+	return indented
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// editConfig 設定ファイルを読み込んで編集し、編集後の内容を返す
+func editConfig(t *testing.T, content string, edit func(d *configDocument) error) (string, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := loadConfigDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := edit(doc); err != nil {
+		return "", err
+	}
+	return string(doc.bytes()), nil
+}
+
+var testInstallPath = InstallPath{Name: "deck", Path: "/run/media/sd/fm24/db", Platform: "linux"}
+
+func TestAddInstallPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name: "コメントと空行を保持",
+			input: `# FM24 実名化ツール
+game: fm24
+
+install_paths:
+  # Steam版
+  - name: steam
+    path: ~/.steam/steam/steamapps/common/Football Manager 2024/data/database/db
+    platform: linux   # Steam Deck
+
+backup:
+  enabled: true
+`,
+			want: `# FM24 実名化ツール
+game: fm24
+
+install_paths:
+  # Steam版
+  - name: steam
+    path: ~/.steam/steam/steamapps/common/Football Manager 2024/data/database/db
+    platform: linux   # Steam Deck
+  - name: deck
+    path: /run/media/sd/fm24/db
+    platform: linux
+
+backup:
+  enabled: true
+`,
+		},
+		{
+			name: "インデントなしのシーケンス",
+			input: `install_paths:
+- name: steam
+  path: /games/fm24/db
+  platform: linux
+`,
+			want: `install_paths:
+- name: steam
+  path: /games/fm24/db
+  platform: linux
+- name: deck
+  path: /run/media/sd/fm24/db
+  platform: linux
+`,
+		},
+		{
+			name: "複数行の説明",
+			input: `install_paths:
+  - name: steam
+    description: |
+      Steam版
+      （メインPC）
+    path: /games/fm24/db
+    platform: linux
+# 末尾のコメント
+`,
+			want: `install_paths:
+  - name: steam
+    description: |
+      Steam版
+      （メインPC）
+    path: /games/fm24/db
+    platform: linux
+  - name: deck
+    path: /run/media/sd/fm24/db
+    platform: linux
+# 末尾のコメント
+`,
+		},
+		{
+			name: "install_paths なし",
+			input: `# 設定
+backup:
+  enabled: false
+`,
+			want: `# 設定
+backup:
+  enabled: false
+
+install_paths:
+  - name: deck
+    path: /run/media/sd/fm24/db
+    platform: linux
+`,
+		},
+		{
+			name:  "空のファイル",
+			input: "",
+			want: `install_paths:
+  - name: deck
+    path: /run/media/sd/fm24/db
+    platform: linux
+`,
+		},
+		{
+			name: "空の install_paths",
+			input: `install_paths: [] # 未設定
+backup:
+  enabled: true
+`,
+			want: `install_paths: # 未設定
+  - name: deck
+    path: /run/media/sd/fm24/db
+    platform: linux
+backup:
+  enabled: true
+`,
+		},
+		{
+			name: "値のない install_paths",
+			input: `install_paths: # 未設定
+backup:
+  enabled: true
+`,
+			want: `install_paths: # 未設定
+  - name: deck
+    path: /run/media/sd/fm24/db
+    platform: linux
+backup:
+  enabled: true
+`,
+		},
+		{
+			name:  "CRLF",
+			input: "install_paths:\r\n  - name: steam\r\n    path: /games/fm24/db\r\n    platform: linux\r\n",
+			want:  "install_paths:\r\n  - name: steam\r\n    path: /games/fm24/db\r\n    platform: linux\r\n  - name: deck\r\n    path: /run/media/sd/fm24/db\r\n    platform: linux\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := editConfig(t, tt.input, func(d *configDocument) error {
+				return d.addInstallPath(testInstallPath)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("addInstallPath()\n--- got ---\n%s\n--- want ---\n%s", got, tt.want)
+			}
+			if _, err := parseConfig("config.yaml", []byte(got)); err != nil {
+				t.Errorf("編集後の設定ファイルが不正です: %v", err)
+			}
+		})
+	}
+}
+
+func TestRemoveInstallPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		remove  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "説明のコメントごと削除",
+			input: `install_paths:
+  # Steam版
+  - name: steam
+    path: /games/fm24/db
+    platform: linux   # Steam Deck
+
+  # SDカード
+  - name: deck
+    path: /run/media/sd/fm24/db
+    platform: linux
+
+backup:
+  enabled: true
+`,
+			remove: "deck",
+			want: `install_paths:
+  # Steam版
+  - name: steam
+    path: /games/fm24/db
+    platform: linux   # Steam Deck
+
+backup:
+  enabled: true
+`,
+		},
+		{
+			name: "先頭の項目",
+			input: `install_paths:
+  # Steam版
+  - name: steam
+    path: /games/fm24/db
+    platform: linux
+  - name: deck
+    path: /run/media/sd/fm24/db
+    platform: linux
+`,
+			remove: "steam",
+			want: `install_paths:
+  - name: deck
+    path: /run/media/sd/fm24/db
+    platform: linux
+`,
+		},
+		{
+			name: "複数行の説明",
+			input: `install_paths:
+  - name: steam
+    description: |
+      Steam版
+      （メインPC）
+    path: /games/fm24/db
+    platform: linux
+  - name: deck
+    path: /run/media/sd/fm24/db
+    platform: linux
+`,
+			remove: "steam",
+			want: `install_paths:
+  - name: deck
+    path: /run/media/sd/fm24/db
+    platform: linux
+`,
+		},
+		{
+			name: "フロースタイル",
+			input: `install_paths: [{name: steam, path: /games/fm24/db, platform: linux}, {name: deck, path: /run/media/sd/fm24/db, platform: linux}]
+`,
+			remove: "steam",
+			want: `install_paths: [{name: deck, path: /run/media/sd/fm24/db, platform: linux}]
+`,
+		},
+		{
+			name: "存在しない名前",
+			input: `install_paths:
+  - name: steam
+    path: /games/fm24/db
+    platform: linux
+`,
+			remove:  "epic",
+			wantErr: true,
+		},
+		{
+			name:    "install_paths なし",
+			input:   "backup:\n  enabled: true\n",
+			remove:  "steam",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := editConfig(t, tt.input, func(d *configDocument) error {
+				return d.removeInstallPath(tt.remove)
+			})
+			if tt.wantErr {
+				if exitCodeFor(err) != ExitNotFound {
+					t.Fatalf("removeInstallPath() error = %v, want 見つからないエラー", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("removeInstallPath()\n--- got ---\n%s\n--- want ---\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigDocumentSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := "install_paths:\n  - name: steam\n    path: /games/fm24/db\n    platform: linux\n"
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := loadConfigDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 検証に失敗する内容は保存しない
+	if err := doc.addInstallPath(InstallPath{Name: "steam", Path: "/games/fm24-2/db", Platform: "linux"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.save(); err == nil || !strings.Contains(err.Error(), "name が重複しています") {
+		t.Fatalf("save() = %v, want 重複エラー", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Errorf("検証に失敗した内容が保存されました:\n%s", data)
+	}
+
+	doc, err = loadConfigDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.addInstallPath(testInstallPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.save(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("パーミッションが変更されました: %s", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("一時ファイルが残っています: %d個のファイル", len(entries))
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// listVersionFolders データベースバージョンフォルダを古い順に取得（例: 2400, 2410, 2430）
+func listVersionFolders(basePath string) ([]string, error) {
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []int
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if version, err := strconv.Atoi(entry.Name()); err == nil {
+				versions = append(versions, version)
+			}
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, notFoundf("バージョンフォルダが見つかりません")
+	}
+
+	sort.Ints(versions)
+	names := make([]string, len(versions))
+	for i, version := range versions {
+		names[i] = strconv.Itoa(version)
+	}
+
+	return names, nil
+}
+
+// useDBRoot 検出したDBフォルダから処理対象のバージョンを選択
+//
+// --all-db-versions の場合は全バージョン、--db-version の場合は指定バージョン、
+// どちらもない場合は最新バージョンのみを対象とする。
+func (t *FM24Tool) useDBRoot(dbRoot string) error {
+	versions, err := listVersionFolders(dbRoot)
+	if err != nil {
+		return err
+	}
+
+	selected := versions[len(versions)-1:]
+	switch {
+	case t.AllDBVersions:
+		selected = versions
+	case t.DBVersion != "":
+		selected = nil
+		for _, version := range versions {
+			if version == t.DBVersion {
+				selected = []string{version}
+			}
+		}
+		if selected == nil {
+			return notFoundf("DBバージョン %s が見つかりません（%s）", t.DBVersion, strings.Join(versions, ", "))
+		}
+	}
+
+	t.DBRoot = dbRoot
+	t.DBVersions = selected
+	t.useDBVersion(selected[len(selected)-1])
+
+	return nil
+}
+
+// useDBVersion 処理対象のバージョンを切り替え
+func (t *FM24Tool) useDBVersion(version string) {
+	t.DBBasePath = filepath.Join(t.DBRoot, version)
+}
+
+// multipleVersions 複数のバージョンを処理するか
+func (t *FM24Tool) multipleVersions() bool {
+	return len(t.DBVersions) > 1
+}
+
+// printDetected 検出したデータベースを表示
+func (t *FM24Tool) printDetected() {
+	if t.multipleVersions() {
+		color.Green("✓ %sデータベース検出: %s (DB %s)\n", t.Game.Short, t.DBRoot, strings.Join(t.DBVersions, ", "))
+		return
+	}
+	color.Green("✓ %sデータベース検出: %s\n", t.Game.Short, t.DBBasePath)
+}
+
+// snapshotID 新しいバックアップのID（複数バージョンの処理ではバージョンごとに分ける）
+func (t *FM24Tool) snapshotID(timestamp string) string {
+	if t.multipleVersions() {
+		return timestamp + "_" + filepath.Base(t.DBBasePath)
+	}
+	return timestamp
+}
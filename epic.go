@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// EpicConfig Epic Games Launcher 設定
+type EpicConfig struct {
+	ManifestDir string `yaml:"manifest_dir,omitempty"` // .item マニフェストの場所（標準の場所に加えて検索）
+}
+
+// epicManifest Epic Games Launcher のインストールマニフェスト（.item）
+type epicManifest struct {
+	DisplayName         string `json:"DisplayName"`
+	AppName             string `json:"AppName"`
+	InstallLocation     string `json:"InstallLocation"`
+	AppVersionString    string `json:"AppVersionString"`
+	IsIncompleteInstall bool   `json:"bIsIncompleteInstall"`
+}
+
+// epicManifestDirs .item マニフェストを検索するフォルダ（設定ファイルの指定が優先）
+func (c *Config) epicManifestDirs() []string {
+	var dirs []string
+	if c.Epic.ManifestDir != "" {
+		if dir, err := expandPath(c.Epic.ManifestDir); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+		dirs = append(dirs, filepath.Join(programData, `Epic\EpicGamesLauncher\Data\Manifests`))
+	case "darwin":
+		home, _ := os.UserHomeDir()
+		dirs = append(dirs, filepath.Join(home, "Library/Application Support/Epic/EpicGamesLauncher/Data/Manifests"))
+	}
+
+	return dirs
+}
+
+// readEpicManifests フォルダ内の .item マニフェストを読み込み（読めないファイルは無視）
+func readEpicManifests(dir string) []*epicManifest {
+	paths, err := filepath.Glob(filepath.Join(escapeGlob(dir), "*.item"))
+	if err != nil {
+		return nil
+	}
+	sort.Strings(paths)
+
+	var manifests []*epicManifest
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var manifest epicManifest
+		if err := json.Unmarshal(data, &manifest); err != nil || manifest.InstallLocation == "" {
+			continue
+		}
+		manifests = append(manifests, &manifest)
+	}
+	return manifests
+}
+
+// isGame マニフェストがゲームのものか（表示名またはインストールフォルダ名で判定）
+//
+// Wine プレフィックス内のマニフェストもあるため、区切り文字は \ と / の両方を扱う。
+func (m *epicManifest) isGame(game *GameProfile) bool {
+	if strings.EqualFold(m.DisplayName, game.Name) {
+		return true
+	}
+	base := path.Base(strings.ReplaceAll(m.InstallLocation, `\`, "/"))
+	for _, dir := range game.InstallDirs {
+		if strings.EqualFold(base, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// findEpicApps ゲームの Epic インストールを全てのマニフェストから検索
+func (c *Config) findEpicApps(game *GameProfile) []*epicManifest {
+	var apps []*epicManifest
+	for _, dir := range c.epicManifestDirs() {
+		for _, manifest := range readEpicManifests(dir) {
+			if manifest.IsIncompleteInstall || !manifest.isGame(game) {
+				continue
+			}
+			apps = append(apps, manifest)
+		}
+	}
+	return apps
+}
+
+// epicAppForPath DBフォルダを含む Epic インストールのマニフェストを検索
+func (c *Config) epicAppForPath(dbRoot string, game *GameProfile) *epicManifest {
+	for _, app := range c.findEpicApps(game) {
+		rel, err := filepath.Rel(filepath.Clean(app.InstallLocation), dbRoot)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return app
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// testdata/epic のインストール済み FM24 の .item とそのインストール先
+const (
+	epicFM24     = "3F1C8A2B4D6E4F7A9B0C1D2E3F4A5B6C.item"
+	epicFM24Path = `C:\Program Files\Epic Games\FootballManager2024`
+)
+
+// writeEpicManifest testdata の .item を InstallLocation を置き換えて配置
+func writeEpicManifest(t *testing.T, dir, name, installLocation string) {
+	t.Helper()
+	location, err := json.Marshal(installLocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, _ := json.Marshal(epicFM24Path)
+	writeTree(t, dir, map[string]string{
+		name: strings.Replace(readFixture(t, "epic/"+name), string(original), string(location), 1),
+	})
+}
+
+func TestReadEpicManifests(t *testing.T) {
+	// 不正な JSON、InstallLocation のないもの、.item 以外は無視
+	manifests := readEpicManifests(filepath.Join("testdata", "epic"))
+
+	want := []*epicManifest{
+		{DisplayName: "Football Manager 2024", AppName: "FM24AppName", InstallLocation: epicFM24Path, AppVersionString: "24.3.0.2043-Windows"},
+		{DisplayName: "Football Manager 2024", AppName: "FM24AppName", InstallLocation: `D:\Epic Games\FootballManager2024`, AppVersionString: "24.4.0.2051-Windows", IsIncompleteInstall: true},
+		{DisplayName: "Fortnite", AppName: "Fortnite", InstallLocation: `C:\Program Files\Epic Games\Fortnite`, AppVersionString: "++Fortnite+Release-28.01-CL-31000000-Windows"},
+		{DisplayName: "Football Manager 2024 (Renamed)", AppName: "FM24AppName", InstallLocation: `E:\Games\FootballManager2024\`, AppVersionString: "24.3.0.2043-Windows"},
+	}
+	if !reflect.DeepEqual(manifests, want) {
+		t.Errorf("readEpicManifests() =")
+		for _, m := range manifests {
+			t.Errorf("  %+v", *m)
+		}
+	}
+
+	if got := readEpicManifests(filepath.Join(t.TempDir(), "missing")); got != nil {
+		t.Errorf("存在しないフォルダ: %+v", got)
+	}
+}
+
+func TestEpicManifestIsGame(t *testing.T) {
+	fm24, err := findGame("fm24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm23, err := findGame("fm23")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		manifest epicManifest
+		game     *GameProfile
+		want     bool
+	}{
+		{epicManifest{DisplayName: "Football Manager 2024", InstallLocation: `D:\Games\FM`}, fm24, true},
+		{epicManifest{DisplayName: "football manager 2024"}, fm24, true},
+		{epicManifest{DisplayName: "FM", InstallLocation: epicFM24Path}, fm24, true},
+		{epicManifest{DisplayName: "FM", InstallLocation: `E:\Games\footballmanager2024\`}, fm24, true},
+		{epicManifest{DisplayName: "FM", InstallLocation: "/home/user/Games/Heroic/FootballManager2024"}, fm24, true},
+		{epicManifest{DisplayName: "Football Manager 2024", InstallLocation: epicFM24Path}, fm23, false},
+		{epicManifest{DisplayName: "Football Manager 2024 Editor", InstallLocation: `C:\Program Files\Epic Games\FM24Editor`}, fm24, false},
+		{epicManifest{DisplayName: "Fortnite", InstallLocation: `C:\Program Files\Epic Games\Fortnite`}, fm24, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.manifest.isGame(tt.game); got != tt.want {
+			t.Errorf("%+v.isGame(%s) = %v, want %v", tt.manifest, tt.game.ID, got, tt.want)
+		}
+	}
+}
+
+func TestFindEpicApps(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Linux のみ（他のOSでは標準のマニフェストフォルダも検索する）")
+	}
+	game, err := findGame("fm24")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 未完了のインストールと他のゲームは除外
+	config := &Config{Epic: EpicConfig{ManifestDir: filepath.Join("testdata", "epic")}}
+	var locations []string
+	for _, app := range config.findEpicApps(game) {
+		locations = append(locations, app.InstallLocation)
+	}
+	want := []string{epicFM24Path, `E:\Games\FootballManager2024\`}
+	if !reflect.DeepEqual(locations, want) {
+		t.Errorf("findEpicApps() = %q, want %q", locations, want)
+	}
+}
+
+func TestEpicAppForPath(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Linux のみ（他のOSでは標準のマニフェストフォルダも検索する）")
+	}
+	game, err := findGame("fm24")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifestDir := t.TempDir()
+	installDir := filepath.Join(t.TempDir(), "FootballManager2024")
+	writeEpicManifest(t, manifestDir, epicFM24, installDir)
+	config := &Config{Epic: EpicConfig{ManifestDir: manifestDir}}
+
+	tests := []struct {
+		path  string
+		found bool
+	}{
+		{filepath.Join(installDir, "data", "database", "db"), true},
+		{installDir, true},
+		{installDir + "-old", false},
+		{filepath.Dir(installDir), false},
+	}
+	for _, tt := range tests {
+		app := config.epicAppForPath(tt.path, game)
+		if (app != nil) != tt.found {
+			t.Errorf("epicAppForPath(%s) = %+v, want found=%v", tt.path, app, tt.found)
+		}
+	}
+}
+
+func TestScanForInstallationEpic(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Linux のみ（他のOSでは標準のマニフェストフォルダも検索する）")
+	}
+
+	tool := newTestTool(t)
+	manifestDir := t.TempDir()
+	installDir := filepath.Join(t.TempDir(), "FM24")
+	writeTree(t, installDir, map[string]string{"data/database/db/2400/": "", "data/database/db/2410/": ""})
+	writeEpicManifest(t, manifestDir, epicFM24, installDir)
+	tool.Config.Epic.ManifestDir = manifestDir
+
+	install, err := tool.scanForInstallation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &InstallInfo{
+		Path:    filepath.Join(installDir, "data", "database", "db"),
+		Source:  "scan",
+		Store:   "epic",
+		Version: "24.3.0.2043-Windows",
+	}
+	if !reflect.DeepEqual(install, want) {
+		t.Errorf("scanForInstallation() = %+v, want %+v", install, want)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// 終了コード（スクリプトから分岐できるよう固定値とする）
+const (
+	ExitOK         = 0 // 成功 / check: 実名化適用済み
+	ExitNotApplied = 1 // check: 実名化未適用
+	ExitPartial    = 2 // check: 一部のみ適用
+	ExitNotFound   = 3 // インストール・バックアップ等が見つからない
+	ExitIOError    = 4 // ファイルの読み書きエラー
+	ExitError      = 5 // その他のエラー（設定・引数エラー等）
+)
+
+// ApplyStatus 実名化の適用状態
+type ApplyStatus int
+
+const (
+	StatusApplied    ApplyStatus = ExitOK
+	StatusNotApplied ApplyStatus = ExitNotApplied
+	StatusPartial    ApplyStatus = ExitPartial
+)
+
+// ExitCode 適用状態に対応する終了コード
+func (s ApplyStatus) ExitCode() int {
+	return int(s)
+}
+
+// NotFoundError 対象が見つからないエラー
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+// notFoundf NotFoundError を生成
+func notFoundf(format string, args ...interface{}) error {
+	return &NotFoundError{Message: fmt.Sprintf(format, args...)}
+}
+
+// StatusError 終了コードを指定するエラー（一部のファイルのみ処理できた場合等、Message が空の場合は表示しない）
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+// statusErrorf StatusError を生成
+func statusErrorf(code int, format string, args ...interface{}) error {
+	return &StatusError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// errCancelled 確認で続行しなかった（入力がない場合を含む）ため処理を行わなかった
+var errCancelled = &StatusError{Code: ExitNotApplied, Message: "処理をキャンセルしました"}
+
+// exitCodeFor エラーに対応する終了コード
+func exitCodeFor(err error) int {
+	var status *StatusError
+	var notFound *NotFoundError
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	var syscallErr *os.SyscallError
+
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &status):
+		return status.Code
+	case errors.As(err, &notFound):
+		return ExitNotFound
+	case errors.As(err, &pathErr), errors.As(err, &linkErr), errors.As(err, &syscallErr):
+		return ExitIOError
+	default:
+		return ExitError
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	_, pathErr := os.ReadFile("/nonexistent/config.yaml")
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"成功", nil, ExitOK},
+		{"キャンセル", errCancelled, ExitNotApplied},
+		{"キャンセル（ラップ）", fmt.Errorf("復元: %w", errCancelled), ExitNotApplied},
+		{"一部のみ", statusErrorf(ExitPartial, "競合"), ExitPartial},
+		{"見つからない", notFoundf("バックアップが見つかりません"), ExitNotFound},
+		{"読み書きエラー", fmt.Errorf("設定ファイル読み込みエラー: %w", pathErr), ExitIOError},
+		{"その他", errors.New("不明なコマンド"), ExitError},
+	}
+
+	for _, tt := range tests {
+		if got := exitCodeFor(tt.err); got != tt.want {
+			t.Errorf("%s: exitCodeFor(%v) = %d, want %d", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
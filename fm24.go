@@ -5,79 +5,117 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
-	"sort"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/fatih/color"
 )
 
-// TargetFile 削除対象ファイルの定義
-type TargetFile struct {
-	Path        string
-	Description string
-	IsDirectory bool
-	DeleteAll   bool // ディレクトリ内全削除フラグ
-}
-
 // FM24Tool FM24実名化ツール
 type FM24Tool struct {
-	DBBasePath  string
-	BackupDir   string
-	TargetFiles []TargetFile
-	Config      *Config
+	DBRoot        string   // DBフォルダ（バージョンフォルダの親）
+	DBVersions    []string // 処理対象のDBバージョン
+	DBBasePath    string   // 処理中のDBバージョンフォルダ
+	DBVersion     string   // 対象のDBバージョン（--db-version）
+	AllDBVersions bool     // 全てのDBバージョンを対象にする（--all-db-versions）
+	InstallName   string   // 設定ファイルのインストールパス名（--install）
+	BackupDir     string
+	Game          *GameProfile // 対象のゲーム（--game）
+	Rules         []Rule       // 有効な削除対象ルール
+	Profile       string       // 使用するプロファイル（--profile、空の場合は設定に従う）
+	Config        *Config
+	AssumeYes     bool   // 確認プロンプトを省略（--yes）
+	WaitForExit   bool   // ゲームの実行中は終了を待つ（--wait-for-exit）
+	Output        string // 出力形式（table, json）
+	Install       *InstallInfo
+
+	snapshot snapshotWriter
+	manifest *BackupManifest
+	locks    map[string]*fileLock // 取得済みのロック（ロックファイルのパス）
 }
 
-// NewFM24Tool ツールインスタンスを作成
-func NewFM24Tool(config *Config) *FM24Tool {
+// NewFM24Tool ゲームに対するツールインスタンスを作成（インポート済みのルールパックを検証して読み込む）
+func NewFM24Tool(config *Config, game *GameProfile) (*FM24Tool, error) {
+	packs, err := config.LoadRulePacks()
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := config.TargetRules(game, packs)
+	if err != nil {
+		return nil, fmt.Errorf("削除対象ルールの設定エラー: %w", err)
+	}
+	if err := config.validateProfiles(game, packs); err != nil {
+		return nil, fmt.Errorf("プロファイルの設定エラー: %w", err)
+	}
+
 	return &FM24Tool{
 		Config: config,
-		TargetFiles: []TargetFile{
-			{Path: "lnc/all", Description: "lnc/all (全ファイル)", IsDirectory: true, DeleteAll: true},
-			{Path: "lnc/greek", Description: "lnc/greek (全ファイル)", IsDirectory: true, DeleteAll: true},
-			{Path: "edt/permanent/fake.edt", Description: "fake.edt", IsDirectory: false},
-			{Path: "dbc/permanent/brazil_kits.dbc", Description: "brazil_kits.dbc", IsDirectory: false},
-			{Path: "dbc/permanent/forbidden names.dbc", Description: "forbidden names.dbc", IsDirectory: false},
-			{Path: "dbc/permanent/license.dbc", Description: "license.dbc", IsDirectory: false},
-			{Path: "dbc/permanent/j league non player.dbc", Description: "j league non player.dbc", IsDirectory: false},
-			{Path: "dbc/permanent/1_japan_removed_clubs.dbc", Description: "1_japan_removed_clubs.dbc", IsDirectory: false},
-			{Path: "language/Licensing2.dbc", Description: "Licensing2.dbc", IsDirectory: false},
-			{Path: "language/Licensing2_chn.dbc", Description: "Licensing2_chn.dbc", IsDirectory: false},
-		},
-	}
+		Game:   game,
+		Rules:  rules,
+	}, nil
 }
 
-// DetectInstallation FM24のインストールパスを検出（設定ファイルベース）
+// DetectInstallation ゲームのインストールパスを検出（設定ファイルベース）
 func (t *FM24Tool) DetectInstallation(customPath string) error {
 	osType := runtime.GOOS
 
 	// カスタムパスが指定されている場合
 	if customPath != "" {
+		if absPath, err := filepath.Abs(customPath); err == nil {
+			customPath = absPath
+		}
 		if _, err := os.Stat(customPath); err == nil {
-			versionPath, err := t.detectVersionFolder(customPath)
-			if err != nil {
+			if err := t.useDBRoot(customPath); err != nil {
 				return fmt.Errorf("カスタムパスのバージョン検出エラー: %w", err)
 			}
-			t.DBBasePath = versionPath
+			t.setInstall(&InstallInfo{Path: customPath, Source: "custom"})
 			return nil
 		}
-		return fmt.Errorf("指定されたパスが存在しません: %s", customPath)
+		return notFoundf("指定されたパスが存在しません: %s", customPath)
+	}
+
+	// 設定ファイルのインストールパスを名前で指定した場合
+	if t.InstallName != "" {
+		installPath, err := t.Config.findInstallPath(t.InstallName)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(installPath.Path); err != nil {
+			return notFoundf("インストールパス %s が存在しません: %s", installPath.Name, installPath.Path)
+		}
+		if err := t.useDBRoot(installPath.Path); err != nil {
+			return err
+		}
+		t.setInstall(&InstallInfo{
+			Name:        installPath.Name,
+			Description: installPath.Description,
+			Path:        installPath.Path,
+			Source:      "config",
+		})
+		color.Cyan("検出: %s (%s)", installPath.Description, installPath.Name)
+		return nil
 	}
 
 	// 設定ファイルから現在のOSに対応するパスを検索
 	for _, installPath := range t.Config.InstallPaths {
-		// プラットフォームが一致する場合のみチェック
-		if installPath.Platform != osType {
+		// ゲームとプラットフォームが一致する場合のみチェック
+		if installPath.gameID() != t.Game.ID || installPath.Platform != osType {
 			continue
 		}
 
 		if _, err := os.Stat(installPath.Path); err == nil {
-			versionPath, err := t.detectVersionFolder(installPath.Path)
-			if err != nil {
+			if _, err := t.detectVersionFolder(installPath.Path); err != nil {
 				continue
 			}
-			t.DBBasePath = versionPath
+			if err := t.useDBRoot(installPath.Path); err != nil {
+				return err
+			}
+			t.setInstall(&InstallInfo{
+				Name:        installPath.Name,
+				Description: installPath.Description,
+				Path:        installPath.Path,
+				Source:      "config",
+			})
 			color.Cyan("検出: %s (%s)", installPath.Description, installPath.Name)
 			return nil
 		}
@@ -85,241 +123,202 @@ func (t *FM24Tool) DetectInstallation(customPath string) error {
 
 	// 設定ファイルにない場合、自動スキャンを試行
 	color.Yellow("設定ファイルに一致するパスが見つかりません。自動スキャンを開始します...")
-	if foundPath, err := t.scanForInstallation(); err == nil {
-		versionPath, err := t.detectVersionFolder(foundPath)
-		if err == nil {
-			t.DBBasePath = versionPath
-			color.Green("✓ 自動検出: %s", foundPath)
+	if found, err := t.scanForInstallation(); err == nil {
+		if err := t.useDBRoot(found.Path); err == nil {
+			t.setInstall(found)
+			color.Green("✓ 自動検出: %s", found.Path)
 			return nil
 		}
 	}
 
-	return fmt.Errorf("FM24のインストールが見つかりません。設定ファイルを確認するか、--path オプションでパスを指定してください")
+	return notFoundf("%sのインストールが見つかりません。設定ファイルを確認するか、--path オプションでパスを指定してください", t.Game.Short)
+}
+
+// setInstall 検出したインストール情報を記録（Steam/Epic のインストールの場合はビルドID・バージョンを補完）
+func (t *FM24Tool) setInstall(install *InstallInfo) {
+	if install.Store == "" {
+		if app := steamAppForPath(install.Path, t.Game.SteamAppID); app != nil {
+			install.Store = "steam"
+			install.BuildID = app.BuildID
+		} else if app := t.Config.epicAppForPath(install.Path, t.Game); app != nil {
+			install.Store = "epic"
+			install.Version = app.AppVersionString
+		}
+	}
+	if install.BuildID != "" {
+		color.Cyan("Steam ビルドID: %s", install.BuildID)
+	}
+	if install.Version != "" {
+		color.Cyan("Epic バージョン: %s", install.Version)
+	}
+	t.Install = install
 }
 
-// detectVersionFolder データベースバージョンフォルダを検出（例: 2400, 2410など）
+// detectVersionFolder 最新のデータベースバージョンフォルダを検出（例: 2400, 2410など）
 func (t *FM24Tool) detectVersionFolder(basePath string) (string, error) {
-	entries, err := os.ReadDir(basePath)
+	versions, err := listVersionFolders(basePath)
 	if err != nil {
 		return "", err
 	}
 
-	var versions []int
-	for _, entry := range entries {
-		if entry.IsDir() {
-			if version, err := strconv.Atoi(entry.Name()); err == nil {
-				versions = append(versions, version)
+	return filepath.Join(basePath, versions[len(versions)-1]), nil
+}
+
+// scanForInstallation システムをスキャンしてゲームのインストールを自動検出
+func (t *FM24Tool) scanForInstallation() (*InstallInfo, error) {
+	osType := runtime.GOOS
+	home, _ := os.UserHomeDir()
+
+	// Steam: libraryfolders.vdf と appmanifest_<appid>.acf からインストール先を特定
+	libraries := findSteamLibraries()
+	if app, err := findSteamApp(libraries, t.Game.SteamAppID); err == nil {
+		for _, scanPath := range t.Game.dbLayoutPaths(app.Path()) {
+			if _, err := t.detectVersionFolder(scanPath); err == nil {
+				return &InstallInfo{
+					Path:    scanPath,
+					Source:  "scan",
+					Store:   "steam",
+					BuildID: app.BuildID,
+				}, nil
 			}
 		}
 	}
 
-	if len(versions) == 0 {
-		return "", fmt.Errorf("バージョンフォルダが見つかりません")
+	// Epic Games: .item マニフェストの InstallLocation からインストール先を特定
+	for _, app := range t.Config.findEpicApps(t.Game) {
+		for _, scanPath := range t.Game.dbLayoutPaths(app.InstallLocation) {
+			if _, err := t.detectVersionFolder(scanPath); err == nil {
+				return &InstallInfo{
+					Path:    scanPath,
+					Source:  "scan",
+					Store:   "epic",
+					Version: app.AppVersionString,
+				}, nil
+			}
+		}
 	}
 
-	// 最新バージョンを選択
-	sort.Ints(versions)
-	latestVersion := versions[len(versions)-1]
-
-	return filepath.Join(basePath, strconv.Itoa(latestVersion)), nil
-}
-
-// scanForInstallation システムをスキャンしてFM24のインストールを自動検出
-func (t *FM24Tool) scanForInstallation() (string, error) {
-	osType := runtime.GOOS
-	home, _ := os.UserHomeDir()
-
-	// スキャン対象パスのリスト
-	var scanPaths []string
+	// スキャン対象のフォルダ（この下にゲームのインストールフォルダがある）
+	var scanRoots []string
 
 	if osType == "windows" {
 		// Windows: 一般的なインストール場所をスキャン
-		scanPaths = []string{
-			`C:\Program Files (x86)\Steam\steamapps\common\Football Manager 2024\data\database\db`,
-			`C:\Program Files\Steam\steamapps\common\Football Manager 2024\data\database\db`,
-			`C:\Program Files\Epic Games\Football Manager 2024\data\database\db`,
-			`C:\Program Files\Epic Games\FootballManager2024\data\database\db`,
-			`C:\XboxGames\Football Manager 2024\Content\data\database\db`,
-			// Steamライブラリフォルダ（複数のドライブをチェック）
-			`D:\SteamLibrary\steamapps\common\Football Manager 2024\data\database\db`,
-			`E:\SteamLibrary\steamapps\common\Football Manager 2024\data\database\db`,
-			`F:\SteamLibrary\steamapps\common\Football Manager 2024\data\database\db`,
-		}
+		scanRoots = windowsScanRoots
 	} else if osType == "darwin" {
 		// macOS: 一般的なインストール場所をスキャン
-		scanPaths = []string{
-			filepath.Join(home, "Library/Application Support/Steam/steamapps/common/Football Manager 2024/data/database/db"),
-			filepath.Join(home, "Library/Application Support/Sports Interactive/Football Manager 2024/data/database/db"),
-			"/Users/Shared/Epic Games/FootballManager2024/data/database/db",
-			filepath.Join(home, "Library/Application Support/Steam/steamapps/common/Football Manager 2024/database/data/db"),
+		scanRoots = []string{
+			filepath.Join(home, "Library/Application Support/Steam/steamapps/common"),
+			filepath.Join(home, "Library/Application Support/Sports Interactive"),
+			"/Users/Shared/Epic Games",
+		}
+	} else if osType == "linux" {
+		// Linux: Steam（ネイティブ/Flatpak）のライブラリ。FM24 は Proton で実行するため
+		// Windows版のファイルがそのまま steamapps/common に置かれる
+		for _, steamRoot := range linuxSteamRoots(home) {
+			scanRoots = append(scanRoots, filepath.Join(steamRoot, "steamapps", "common"))
 		}
+		// Heroic: Epic版はプレフィックスの外にインストールされる
+		scanRoots = append(scanRoots, heroicInstallDirs(home)...)
+	}
+
+	// appmanifest がない場合に備えて全てのSteamライブラリをチェック
+	for _, library := range libraries {
+		scanRoots = append(scanRoots, filepath.Join(library.Path, "steamapps", "common"))
 	}
 
 	// 各パスをチェック
-	for _, scanPath := range scanPaths {
-		if _, err := os.Stat(scanPath); err == nil {
-			// バージョンフォルダが存在するか確認
-			if _, err := t.detectVersionFolder(scanPath); err == nil {
-				return scanPath, nil
+	for _, root := range scanRoots {
+		for _, scanPath := range t.Game.dbPaths(root) {
+			if _, err := os.Stat(scanPath); err == nil {
+				// バージョンフォルダが存在するか確認
+				if _, err := t.detectVersionFolder(scanPath); err == nil {
+					return &InstallInfo{Path: scanPath, Source: "scan"}, nil
+				}
 			}
 		}
 	}
 
-	// Steamライブラリフォルダを動的に検索（macOS/Windows共通）
-	if steamPath := t.findSteamLibraryPath(); steamPath != "" {
-		fmPath := filepath.Join(steamPath, "steamapps/common/Football Manager 2024/data/database/db")
-		if _, err := os.Stat(fmPath); err == nil {
-			if _, err := t.detectVersionFolder(fmPath); err == nil {
-				return fmPath, nil
-			}
-		}
-		// macOSの場合の別パス
-		if osType == "darwin" {
-			fmPath = filepath.Join(steamPath, "steamapps/common/Football Manager 2024/database/data/db")
-			if _, err := os.Stat(fmPath); err == nil {
-				if _, err := t.detectVersionFolder(fmPath); err == nil {
-					return fmPath, nil
-				}
+	// Wine系ランチャー（Wine, Lutris, Heroic, Bottles, Proton）のプレフィックス内のWindows版
+	if osType != "windows" {
+		for _, prefix := range findWinePrefixes(home, libraries) {
+			if install := t.scanWinePrefix(prefix); install != nil {
+				color.Cyan("Wine プレフィックス（%s）: %s", prefix.Launcher, prefix.Path)
+				return install, nil
 			}
 		}
 	}
 
-	return "", fmt.Errorf("自動スキャンでインストールが見つかりませんでした")
+	return nil, notFoundf("自動スキャンでインストールが見つかりませんでした")
 }
 
-// findSteamLibraryPath Steamライブラリパスを検索
-func (t *FM24Tool) findSteamLibraryPath() string {
-	osType := runtime.GOOS
-	home, _ := os.UserHomeDir()
-
-	if osType == "windows" {
-		// Windows: Steamの設定ファイルからライブラリパスを取得
-		steamConfig := filepath.Join(home, "AppData/Local/Steam/steamapps/libraryfolders.vdf")
-		if data, err := os.ReadFile(steamConfig); err == nil {
-			content := string(data)
-			// "path" キーを探してパスを抽出（簡易パース）
-			lines := strings.Split(content, "\n")
-			for _, line := range lines {
-				line = strings.TrimSpace(line)
-				if strings.Contains(line, "\"path\"") {
-					// "path"		"C:\\..." の形式からパスを抽出
-					parts := strings.Split(line, "\"")
-					for _, part := range parts {
-						if strings.Contains(part, ":\\") || strings.Contains(part, ":/") {
-							// パスらしい文字列を抽出
-							path := strings.Trim(part, " \t\"")
-							path = strings.ReplaceAll(path, "\\\\", "\\")
-							if _, err := os.Stat(path); err == nil {
-								return path
-							}
-						}
-					}
-				}
-			}
-		}
-		// デフォルトのSteamパス
-		defaultPaths := []string{
-			`C:\Program Files (x86)\Steam`,
-			`C:\Program Files\Steam`,
-		}
-		for _, p := range defaultPaths {
-			if _, err := os.Stat(p); err == nil {
-				return p
-			}
-		}
-	} else if osType == "darwin" {
-		// macOS: デフォルトのSteamパス
-		steamPath := filepath.Join(home, "Library/Application Support/Steam")
-		if _, err := os.Stat(steamPath); err == nil {
-			return steamPath
-		}
+// linuxSteamRoots LinuxでSteamがインストールされうる場所（Steam Deck を含む）
+func linuxSteamRoots(home string) []string {
+	return []string{
+		filepath.Join(home, ".steam/steam"),
+		filepath.Join(home, ".local/share/Steam"),
+		// Flatpak版
+		filepath.Join(home, ".var/app/com.valvesoftware.Steam/.local/share/Steam"),
+		filepath.Join(home, ".var/app/com.valvesoftware.Steam/data/Steam"),
+		// Steam Deck のSDカード
+		"/run/media/mmcblk0p1",
 	}
-
-	return ""
 }
 
 // CheckStatus 実名化対応されているかチェック
-func (t *FM24Tool) CheckStatus(customPath string) error {
+func (t *FM24Tool) CheckStatus(customPath string) (ApplyStatus, error) {
 	color.Cyan("==========================================================")
-	color.Cyan("FM24 実名化状態チェック")
+	color.Cyan("%s 実名化状態チェック", t.Game.Short)
 	color.Cyan("==========================================================\n")
 
 	// インストールパス検出
 	if err := t.DetectInstallation(customPath); err != nil {
-		return err
+		return StatusNotApplied, err
 	}
 
-	color.Green("✓ FM24データベース検出: %s\n", t.DBBasePath)
+	t.printDetected()
 
-	// 対象ファイルの存在チェック
-	existCount := 0
-	deletedCount := 0
-
-	fmt.Println("\n📋 ライセンスファイル状態:")
-	fmt.Println()
-
-	for _, target := range t.TargetFiles {
-		fullPath := filepath.Join(t.DBBasePath, target.Path)
-		exists := false
-
-		if target.IsDirectory {
-			if stat, err := os.Stat(fullPath); err == nil && stat.IsDir() {
-				// ディレクトリ内のファイル数をチェック
-				entries, _ := os.ReadDir(fullPath)
-				if len(entries) > 0 {
-					exists = true
-					color.Yellow("  ⊘ %s (%d個のファイル存在)", target.Description, len(entries))
-				} else {
-					color.Green("  ✓ %s (空)", target.Description)
-				}
-			} else {
-				color.Green("  ✓ %s (ディレクトリなし)", target.Description)
-			}
-		} else {
-			if _, err := os.Stat(fullPath); err == nil {
-				exists = true
-				color.Yellow("  ⊘ %s (存在)", target.Description)
-			} else {
-				color.Green("  ✓ %s (削除済み)", target.Description)
-			}
-		}
+	// 対象ファイルの存在チェック（バージョンごと）
+	reports, err := t.inspectVersions()
+	if err != nil {
+		return StatusNotApplied, err
+	}
 
-		if exists {
-			existCount++
-		} else {
-			deletedCount++
+	if !t.multipleVersions() {
+		report := reports[0]
+		if t.Output == OutputJSON {
+			return report.Status, printJSON(report)
 		}
+		printStatus(report)
+		return report.Status, nil
 	}
 
-	// 日本関連ファイルチェック
-	japanFiles, _ := t.findJapanFiles()
-	if len(japanFiles) > 0 {
-		color.Yellow("  ⊘ 日本関連ファイル (%d個存在)", len(japanFiles))
-		existCount += len(japanFiles)
-	} else {
-		color.Green("  ✓ 日本関連ファイル (削除済み)")
+	summary := t.newVersionsStatusReport(reports)
+	if t.Output == OutputJSON {
+		return summary.Status, printJSON(summary)
 	}
+	printVersionsStatus(summary)
 
-	// 結果サマリー
-	fmt.Println()
-	color.Cyan("==========================================================")
-	fmt.Printf("ライセンスファイル: %d個存在 / %d個削除済み\n", existCount, deletedCount)
+	return summary.Status, nil
+}
 
-	if existCount > 0 {
-		color.Yellow("\n⚠️  実名化は未適用です")
-		color.White("実名化を適用するには: fm24-real --apply")
-	} else {
-		color.Green("\n✅ 実名化が適用されています")
+// inspectVersions 処理対象の全バージョンの実名化状態を調べる
+func (t *FM24Tool) inspectVersions() ([]*StatusReport, error) {
+	var reports []*StatusReport
+	for _, version := range t.DBVersions {
+		t.useDBVersion(version)
+		report, err := t.inspectStatus()
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
 	}
-	color.Cyan("==========================================================")
-
-	return nil
+	return reports, nil
 }
 
 // Apply 実名化対応を実施
 func (t *FM24Tool) Apply(customPath string) error {
 	color.Cyan("==========================================================")
-	color.Cyan("FM24 実名化適用")
+	color.Cyan("%s 実名化適用", t.Game.Short)
 	color.Cyan("==========================================================\n")
 
 	// インストールパス検出
@@ -327,109 +326,177 @@ func (t *FM24Tool) Apply(customPath string) error {
 		return err
 	}
 
-	color.Green("✓ FM24データベース検出: %s\n", t.DBBasePath)
+	t.printDetected()
 
-	// バックアップディレクトリ作成
-	if err := t.createBackupDir(); err != nil {
+	if err := t.ensureGameNotRunning(); err != nil {
 		return err
 	}
 
-	color.Cyan("📦 バックアップディレクトリ: %s\n", t.BackupDir)
-
 	// 確認
 	color.Yellow("\n⚠️  警告: ライセンスファイルを削除します")
-	fmt.Println("バックアップは自動的に作成されますが、自己責任で実行してください")
-	fmt.Print("\n続行しますか? (y/n): ")
-
-	var response string
-	fmt.Scanln(&response)
-	if response != "y" && response != "Y" {
-		color.Red("❌ 処理をキャンセルしました")
-		return nil
+	if t.backupEnabled() {
+		logln("バックアップは自動的に作成されますが、自己責任で実行してください")
+	} else {
+		color.Yellow("⚠️  バックアップは無効です（backup.enabled: false）")
 	}
-
-	// 実名化処理実行
-	totalFiles, deletedCount, err := t.executeRealNameProcess()
-	if err != nil {
-		return err
+	if !confirm("\n続行しますか? (y/n): ", t.AssumeYes) {
+		return errCancelled
 	}
 
-	// レポート生成
-	t.generateReport(totalFiles, deletedCount)
-
-	return nil
+	// 実名化処理実行
+	return t.applyVersions()
 }
 
 // Update 実名化対応を更新（再適用）
 func (t *FM24Tool) Update(customPath string) error {
 	color.Cyan("==========================================================")
-	color.Cyan("FM24 実名化更新（再適用）")
+	color.Cyan("%s 実名化更新（再適用）", t.Game.Short)
 	color.Cyan("==========================================================\n")
 
 	color.Yellow("ゲームアップデート後にライセンスファイルが復活した場合に使用します\n")
 
 	// 状態チェック
-	if err := t.CheckStatus(customPath); err != nil {
+	if err := t.DetectInstallation(customPath); err != nil {
 		return err
 	}
 
-	fmt.Println()
-	fmt.Print("実名化を再適用しますか? (y/n): ")
+	t.printDetected()
+	reports, err := t.inspectVersions()
+	if err != nil {
+		return err
+	}
+	if t.multipleVersions() {
+		printVersionsStatus(t.newVersionsStatusReport(reports))
+	} else {
+		printStatus(reports[0])
+	}
 
-	var response string
-	fmt.Scanln(&response)
-	if response != "y" && response != "Y" {
-		color.Red("❌ 処理をキャンセルしました")
-		return nil
+	logln()
+	if err := t.ensureGameNotRunning(); err != nil {
+		return err
+	}
+	if !confirm("実名化を再適用しますか? (y/n): ", t.AssumeYes) {
+		return errCancelled
 	}
 
 	// Apply処理を実行（確認なしで実行）
-	if err := t.DetectInstallation(customPath); err != nil {
+	return t.applyVersions()
+}
+
+// applyVersions 処理対象の各バージョンに実名化処理を実行（バックアップはバージョンごと）
+func (t *FM24Tool) applyVersions() error {
+	// バックアップの作成から自動削除までロックを保持
+	release, err := t.lockForUpdate()
+	if err != nil {
 		return err
 	}
+	defer release()
 
-	if err := t.createBackupDir(); err != nil {
-		return err
+	var reports []*ApplyReport
+	for _, version := range t.DBVersions {
+		t.useDBVersion(version)
+		if t.multipleVersions() {
+			color.Cyan("\n▶ DB %s", version)
+		}
+
+		if err := t.createBackupDir(); err != nil {
+			return err
+		}
+		t.printBackupDir()
+
+		plan, err := t.executeRealNameProcess()
+		if err != nil {
+			if t.multipleVersions() {
+				return fmt.Errorf("DB %s: %w", version, err)
+			}
+			return err
+		}
+		reports = append(reports, t.newApplyReport(plan))
 	}
 
-	color.Cyan("\n📦 バックアップディレクトリ: %s\n", t.BackupDir)
+	// レポート生成
+	err = t.generateReport(reports)
+	t.autoPrune()
 
-	totalFiles, deletedCount, err := t.executeRealNameProcess()
-	if err != nil {
-		return err
+	return err
+}
+
+// printBackupDir バックアップ先を表示
+func (t *FM24Tool) printBackupDir() {
+	if t.BackupDir == "" {
+		color.Yellow("\n⚠️  バックアップは無効です（backup.enabled: false）\n")
+		return
 	}
+	color.Cyan("\n📦 バックアップディレクトリ: %s\n", t.BackupDir)
+}
 
-	t.generateReport(totalFiles, deletedCount)
+// autoPrune 設定に従って古いバックアップを自動削除
+func (t *FM24Tool) autoPrune() {
+	retention := t.Config.Backup.Retention
+	if t.BackupDir == "" || !retention.AutoPrune {
+		return
+	}
 
-	return nil
+	logln()
+	if err := t.PruneBackups(retention, false); err != nil {
+		color.Yellow("⚠️  バックアップの自動削除に失敗しました: %v", err)
+	}
 }
 
 // createBackupDir バックアップディレクトリを作成
 func (t *FM24Tool) createBackupDir() error {
-	home, err := os.UserHomeDir()
+	t.BackupDir = ""
+	t.snapshot = nil
+	t.manifest = nil
+
+	if !t.backupEnabled() {
+		return nil
+	}
+
+	root, err := t.backupRoot()
 	if err != nil {
 		return err
 	}
 
-	timestamp := time.Now().Format("20060102_150405")
-	t.BackupDir = filepath.Join(home, "FM24_Backup", timestamp)
+	id := t.snapshotID(time.Now().Format(backupTimestampFormat))
+	snapshot, err := newSnapshotWriter(root, id, t.backupFormat())
+	if err != nil {
+		return fmt.Errorf("バックアップ作成エラー: %w", err)
+	}
+
+	t.snapshot = snapshot
+	t.BackupDir = snapshot.Location()
+	t.manifest = t.newBackupManifest()
 
-	return os.MkdirAll(t.BackupDir, 0755)
+	return nil
 }
 
-// backupFile ファイルをバックアップ
-func (t *FM24Tool) backupFile(srcPath string) error {
-	relPath, err := filepath.Rel(t.DBBasePath, srcPath)
-	if err != nil {
-		return err
+// discardBackup 未使用のバックアップを破棄
+func (t *FM24Tool) discardBackup() {
+	if t.snapshot != nil {
+		t.snapshot.Abort()
+		t.snapshot = nil
 	}
+}
 
-	dstPath := filepath.Join(t.BackupDir, relPath)
+// commitBackup マニフェストを書き込んでバックアップを確定
+func (t *FM24Tool) commitBackup() error {
+	if t.snapshot == nil {
+		return nil
+	}
 
-	// ディレクトリ作成
-	dstDir := filepath.Dir(dstPath)
-	if err := os.MkdirAll(dstDir, 0755); err != nil {
-		return err
+	if err := t.snapshot.Commit(t.manifest); err != nil {
+		return fmt.Errorf("バックアップ確定エラー: %w", err)
+	}
+	t.snapshot = nil
+
+	return nil
+}
+
+// backupFile ファイルをバックアップ
+func (t *FM24Tool) backupFile(srcPath string) error {
+	if t.snapshot == nil {
+		return nil
 	}
 
 	// ファイル情報取得
@@ -439,20 +506,38 @@ func (t *FM24Tool) backupFile(srcPath string) error {
 	}
 
 	if srcInfo.IsDir() {
-		return t.backupDirectory(srcPath, dstPath)
+		return t.backupDirectory(srcPath)
+	}
+
+	return t.copyToBackup(srcPath, srcInfo)
+}
+
+// copyToBackup ファイルをバックアップ先にコピーし、マニフェストに記録
+func (t *FM24Tool) copyToBackup(srcPath string, srcInfo os.FileInfo) error {
+	relPath, err := filepath.Rel(t.DBBasePath, srcPath)
+	if err != nil {
+		return err
 	}
+	relPath = filepath.ToSlash(relPath)
 
-	// ファイルコピー
-	srcFile, err := os.ReadFile(srcPath)
+	size, sum, err := t.snapshot.AddFile(relPath, srcPath, srcInfo)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(dstPath, srcFile, srcInfo.Mode())
+	t.manifest.add(ManifestEntry{
+		Path:    relPath,
+		Size:    size,
+		Mode:    srcInfo.Mode(),
+		ModTime: srcInfo.ModTime(),
+		SHA256:  sum,
+	})
+
+	return nil
 }
 
 // backupDirectory ディレクトリを再帰的にバックアップ
-func (t *FM24Tool) backupDirectory(srcDir, dstDir string) error {
+func (t *FM24Tool) backupDirectory(srcDir string) error {
 	entries, err := os.ReadDir(srcDir)
 	if err != nil {
 		return err
@@ -460,148 +545,149 @@ func (t *FM24Tool) backupDirectory(srcDir, dstDir string) error {
 
 	for _, entry := range entries {
 		srcPath := filepath.Join(srcDir, entry.Name())
-		dstPath := filepath.Join(dstDir, entry.Name())
 
 		if entry.IsDir() {
-			os.MkdirAll(dstPath, 0755)
-			if err := t.backupDirectory(srcPath, dstPath); err != nil {
+			if err := t.backupDirectory(srcPath); err != nil {
 				return err
 			}
 		} else {
-			srcFile, err := os.ReadFile(srcPath)
+			info, err := entry.Info()
 			if err != nil {
-				continue
+				return err
+			}
+			if err := t.copyToBackup(srcPath, info); err != nil {
+				return err
 			}
-			info, _ := entry.Info()
-			os.WriteFile(dstPath, srcFile, info.Mode())
 		}
 	}
 
 	return nil
 }
 
-// findJapanFiles 日本関連ファイルを検索
-func (t *FM24Tool) findJapanFiles() ([]string, error) {
-	japanDir := filepath.Join(t.DBBasePath, "dbc/permanent")
-	if _, err := os.Stat(japanDir); os.IsNotExist(err) {
-		return nil, nil
-	}
+// executeRealNameProcess 実名化処理を実行
+//
+// 全てのバックアップと削除が成功した場合のみ変更を確定し、
+// 途中でエラーが発生した場合はインストールを開始時の状態に戻す。
+func (t *FM24Tool) executeRealNameProcess() (*Plan, error) {
+	color.Cyan("\n🔄 実名化処理を開始します...\n")
 
-	entries, err := os.ReadDir(japanDir)
+	// 呼び出し元で取得済みの場合はそのまま使用
+	release, err := t.lockForUpdate()
 	if err != nil {
+		t.discardBackup()
 		return nil, err
 	}
+	defer release()
 
-	var japanFiles []string
-	for _, entry := range entries {
-		if !entry.IsDir() && len(entry.Name()) >= 5 && entry.Name()[:5] == "japan" {
-			japanFiles = append(japanFiles, filepath.Join(japanDir, entry.Name()))
-		}
+	// 前回中断された処理があれば元に戻す
+	if err := t.recoverStaging(); err != nil {
+		t.discardBackup()
+		return nil, err
 	}
 
-	return japanFiles, nil
-}
-
-// deleteDirectoryContents ディレクトリ内の全ファイルを削除
-func (t *FM24Tool) deleteDirectoryContents(dirPath string) (int, error) {
-	entries, err := os.ReadDir(dirPath)
+	plan, err := t.buildPlan(t.BackupDir)
 	if err != nil {
-		return 0, err
+		t.discardBackup()
+		return nil, fmt.Errorf("実行計画の作成エラー: %w", err)
 	}
 
-	deletedCount := 0
-	for _, entry := range entries {
-		fullPath := filepath.Join(dirPath, entry.Name())
-
-		// バックアップ
-		t.backupFile(fullPath)
+	for _, skip := range plan.Skipped {
+		color.White("  ⊘ %s: %s", skip.Rule, skip.Reason)
+	}
 
-		// 削除
-		if err := os.RemoveAll(fullPath); err != nil {
-			color.Yellow("  ⚠️  削除失敗: %s - %v", entry.Name(), err)
-			continue
+	// 1. 全件バックアップ
+	for _, item := range plan.Items {
+		if err := t.backupFile(item.FullPath); err != nil {
+			t.discardBackup()
+			return nil, fmt.Errorf("バックアップ失敗: %s - %w（ファイルは変更されていません）", item.Path, err)
 		}
+	}
 
-		deletedCount++
+	// 2. 全件ステージングディレクトリへ移動
+	tx, err := newRemovalTransaction(t.DBBasePath)
+	if err != nil {
+		t.discardBackup()
+		return nil, err
 	}
 
-	return deletedCount, nil
-}
+	for _, item := range plan.Items {
+		if err := tx.stage(item.FullPath); err != nil {
+			color.Red("  ✗ %s: 削除失敗 - %v", item.Path, err)
+			return nil, t.rollbackRemoval(tx, fmt.Errorf("削除失敗: %s - %w", item.Path, err))
+		}
+	}
 
-// executeRealNameProcess 実名化処理を実行
-func (t *FM24Tool) executeRealNameProcess() (int, int, error) {
-	color.Cyan("\n🔄 実名化処理を開始します...\n")
+	// 3. 確定済みマーカーとバックアップ確定（マニフェスト書き込み）
+	backupLocation := ""
+	if t.snapshot != nil {
+		backupLocation = t.snapshot.Location()
+	}
+	if err := tx.markCommitted(backupLocation); err != nil {
+		return nil, t.rollbackRemoval(tx, err)
+	}
+	if err := t.commitBackup(); err != nil {
+		return nil, t.rollbackRemoval(tx, err)
+	}
 
-	totalFiles := 0
-	deletedCount := 0
-
-	// ターゲットファイル処理
-	for _, target := range t.TargetFiles {
-		fullPath := filepath.Join(t.DBBasePath, target.Path)
-
-		if target.IsDirectory && target.DeleteAll {
-			// ディレクトリ内全削除
-			if stat, err := os.Stat(fullPath); err == nil && stat.IsDir() {
-				count, _ := t.deleteDirectoryContents(fullPath)
-				color.Green("  ✓ %s: %d個のファイルを削除", target.Description, count)
-				deletedCount += count
-			} else {
-				color.White("  ⊘ %s: ディレクトリが見つかりません", target.Description)
-			}
-			totalFiles++
-		} else {
-			// 個別ファイル削除
-			if _, err := os.Stat(fullPath); err == nil {
-				// バックアップ
-				t.backupFile(fullPath)
-
-				// 削除
-				if err := os.RemoveAll(fullPath); err != nil {
-					color.Yellow("  ⚠️  削除失敗: %s - %v", target.Description, err)
-				} else {
-					color.Green("  ✓ %s: 削除完了", target.Description)
-					deletedCount++
-				}
-			} else {
-				color.White("  ⊘ %s: ファイルが見つかりません", target.Description)
-			}
-			totalFiles++
-		}
+	// 4. 削除確定
+	if err := tx.commit(); err != nil {
+		return nil, fmt.Errorf("%w: %s（削除とバックアップは完了しています。次回の実行時に削除されます）", err, tx.stagingDir)
 	}
 
-	// 日本関連ファイル削除
-	japanFiles, _ := t.findJapanFiles()
-	for _, jpFile := range japanFiles {
-		// バックアップ
-		t.backupFile(jpFile)
+	for _, item := range plan.Items {
+		color.Green("  ✓ %s: 削除完了", item.Path)
+	}
 
-		// 削除
-		if err := os.Remove(jpFile); err != nil {
-			color.Yellow("  ⚠️  削除失敗: %s - %v", filepath.Base(jpFile), err)
-		} else {
-			color.Green("  ✓ %s: 削除完了", filepath.Base(jpFile))
-			deletedCount++
-		}
-		totalFiles++
+	return plan, nil
+}
+
+// rollbackRemoval 削除処理をロールバックし、元のエラーを返す
+func (t *FM24Tool) rollbackRemoval(tx *removalTransaction, cause error) error {
+	t.discardBackup()
+
+	color.Yellow("\n⏪ エラーが発生したためロールバックします...")
+	if err := tx.rollback(); err != nil {
+		return fmt.Errorf("%v; %w", cause, err)
 	}
 
-	return totalFiles, deletedCount, nil
+	color.Green("✓ ロールバック完了: インストールは処理前の状態に戻りました")
+	return cause
 }
 
 // generateReport 処理結果レポートを生成
-func (t *FM24Tool) generateReport(totalFiles, deletedCount int) {
-	fmt.Println()
-	color.Cyan("==========================================================")
-	color.Cyan("📊 実名化処理レポート")
-	color.Cyan("==========================================================")
-	fmt.Printf("対象ファイル数: %d\n", totalFiles)
-	color.Green("削除成功: %d", deletedCount)
-	color.Yellow("削除失敗: %d", totalFiles-deletedCount)
-	fmt.Printf("バックアップ場所: %s\n", t.BackupDir)
-	color.Cyan("==========================================================")
+func (t *FM24Tool) generateReport(reports []*ApplyReport) error {
+	if t.Output == OutputJSON {
+		if t.multipleVersions() {
+			return printJSON(&VersionsApplyReport{Versions: reports})
+		}
+		return printJSON(reports[0])
+	}
+
+	for _, report := range reports {
+		logln()
+		color.Cyan("==========================================================")
+		if t.multipleVersions() {
+			color.Cyan("📊 実名化処理レポート（DB %s）", report.DBVersion)
+		} else {
+			color.Cyan("📊 実名化処理レポート")
+		}
+		color.Cyan("==========================================================")
+		logf("プロファイル: %s\n", report.Profile)
+		logf("対象ファイル数: %d\n", report.Total)
+		color.Green("削除成功: %d", report.Deleted)
+		color.Yellow("削除失敗: %d", report.Total-report.Deleted)
+		if report.BackupEnabled {
+			logf("バックアップ場所: %s\n", report.BackupLocation)
+		} else {
+			color.Yellow("バックアップ場所: なし（バックアップ無効）")
+		}
+		color.Cyan("==========================================================")
+	}
 
 	color.Green("\n✅ 実名化処理が完了しました")
 	color.Yellow("⚠️  ゲームを再起動して変更を反映してください")
 	color.Yellow("⚠️  アップデート後はファイルが復活する可能性があります")
-	color.White("    その場合は 'fm24-real --update' を実行してください")
+	color.White("    その場合は 'fm24-real update' を実行してください")
+
+	return nil
 }
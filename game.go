@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// defaultGameID ゲーム未指定時に使用するエディション
+const defaultGameID = "fm24"
+
+// GameProfile Football Manager の各エディションの定義
+type GameProfile struct {
+	ID          string   // 識別子（--game で指定）
+	Name        string   // 正式名称
+	Short       string   // 表示用の短縮名
+	SteamAppID  int      // Steam のアプリID
+	InstallDirs []string // インストールフォルダ名（Steam/App Store と Epic で異なる）
+	DBLayouts   []string // インストールフォルダからDBフォルダ（バージョンフォルダの親）への相対パス
+	Executables []string // ゲームの実行ファイル名（実行中か確認するため）
+	Rules       []Rule   // 組み込みの削除対象ルール
+}
+
+// commonDBLayouts 各エディション共通のDBフォルダの配置
+var commonDBLayouts = []string{
+	"data/database/db",
+	"database/data/db",         // macOS Steam版の一部
+	"Content/data/database/db", // Xbox（PC Game Pass）版
+}
+
+// gameExecutables 各エディション共通の実行ファイル名（Windows / Wine と macOS、インストールフォルダ内のもののみ対象）
+var gameExecutables = []string{"fm.exe", "fm"}
+
+// gameProfiles 組み込みのゲームプロファイル
+func gameProfiles() []*GameProfile {
+	return []*GameProfile{
+		{
+			ID:          "fm24",
+			Name:        "Football Manager 2024",
+			Short:       "FM24",
+			SteamAppID:  2252570,
+			InstallDirs: []string{"Football Manager 2024", "FootballManager2024"},
+			DBLayouts:   commonDBLayouts,
+			Executables: gameExecutables,
+			Rules:       fm24Rules(),
+		},
+		{
+			ID:          "fm23",
+			Name:        "Football Manager 2023",
+			Short:       "FM23",
+			SteamAppID:  1904540,
+			InstallDirs: []string{"Football Manager 2023", "FootballManager2023"},
+			DBLayouts:   commonDBLayouts,
+			Executables: gameExecutables,
+			Rules:       fm23Rules(),
+		},
+	}
+}
+
+// fm24Rules FM24 の組み込みルール
+func fm24Rules() []Rule {
+	return []Rule{
+		{Name: "lnc-all", Type: RuleDirContents, Path: "lnc/all", Description: "lnc/all (全ファイル)", Category: "licensing"},
+		{Name: "lnc-greek", Type: RuleDirContents, Path: "lnc/greek", Description: "lnc/greek (全ファイル)", Category: "greece", Optional: true},
+		{Name: "fake-edt", Type: RuleExact, Path: "edt/permanent/fake.edt", Description: "fake.edt", Category: "licensing"},
+		{Name: "brazil-kits", Type: RuleExact, Path: "dbc/permanent/brazil_kits.dbc", Description: "brazil_kits.dbc", Category: "brazil-kits", Optional: true},
+		{Name: "forbidden-names", Type: RuleExact, Path: "dbc/permanent/forbidden names.dbc", Description: "forbidden names.dbc", Category: "licensing"},
+		{Name: "license", Type: RuleExact, Path: "dbc/permanent/license.dbc", Description: "license.dbc", Category: "licensing"},
+		{Name: "j-league-non-player", Type: RuleExact, Path: "dbc/permanent/j league non player.dbc", Description: "j league non player.dbc", Category: "japan", Optional: true},
+		{Name: "japan-removed-clubs", Type: RuleExact, Path: "dbc/permanent/1_japan_removed_clubs.dbc", Description: "1_japan_removed_clubs.dbc", Category: "japan", Optional: true},
+		{Name: "japan-files", Type: RuleRegex, Path: "dbc/permanent", Pattern: "^japan", Description: "日本関連ファイル", Category: "japan"},
+		{Name: "licensing2", Type: RuleExact, Path: "language/Licensing2.dbc", Description: "Licensing2.dbc", Category: "language", Optional: true},
+		{Name: "licensing2-chn", Type: RuleExact, Path: "language/Licensing2_chn.dbc", Description: "Licensing2_chn.dbc", Category: "language", Optional: true},
+	}
+}
+
+// fm23Rules FM23 の組み込みルール（FM24 のルールから FM23 にないファイルを除いたもの）
+//
+// 試験的: FM23 のファイル構成を確認した資料はなく、FM24 のルールからの推定。
+// FM23 の実際の配置と異なる場合は設定ファイルの rules かルールパックで調整する。
+func fm23Rules() []Rule {
+	return withoutRules(fm24Rules(), "japan-removed-clubs")
+}
+
+// withoutRules 指定した名前のルールを除く
+func withoutRules(rules []Rule, names ...string) []Rule {
+	var filtered []Rule
+	for _, rule := range rules {
+		if !slices.Contains(names, rule.Name) {
+			filtered = append(filtered, rule)
+		}
+	}
+	return filtered
+}
+
+// findGame IDからゲームプロファイルを検索
+func findGame(id string) (*GameProfile, error) {
+	var ids []string
+	for _, game := range gameProfiles() {
+		if game.ID == id {
+			return game, nil
+		}
+		ids = append(ids, game.ID)
+	}
+	return nil, fmt.Errorf("不明なゲーム: %s（%s）", id, strings.Join(ids, ", "))
+}
+
+// SelectGame 使用するゲームを決定（--game > 設定ファイルの game > fm24）
+func (c *Config) SelectGame(id string) (*GameProfile, error) {
+	if id == "" {
+		id = c.Game
+	}
+	if id == "" {
+		id = defaultGameID
+	}
+	return findGame(id)
+}
+
+// gameID インストールパスのゲーム（省略時は fm24）
+func (p InstallPath) gameID() string {
+	if p.Game == "" {
+		return defaultGameID
+	}
+	return p.Game
+}
+
+// defaultRules 組み込みの削除対象ルール
+func (g *GameProfile) defaultRules() []Rule {
+	rules := make([]Rule, len(g.Rules))
+	copy(rules, g.Rules)
+	for i := range rules {
+		rules[i].Source = ruleSourceBuiltin
+	}
+	return rules
+}
+
+// backupDirName デフォルトのバックアップフォルダ名（例: FM24_Backup）
+func (g *GameProfile) backupDirName() string {
+	return g.Short + "_Backup"
+}
+
+// dbPaths ライブラリ等のフォルダ内でDBフォルダがありうるパス
+func (g *GameProfile) dbPaths(parent string) []string {
+	var paths []string
+	for _, dir := range g.InstallDirs {
+		paths = append(paths, g.dbLayoutPaths(filepath.Join(parent, dir))...)
+	}
+	return paths
+}
+
+// dbLayoutPaths インストールフォルダ内でDBフォルダがありうるパス
+func (g *GameProfile) dbLayoutPaths(installDir string) []string {
+	paths := make([]string, len(g.DBLayouts))
+	for i, layout := range g.DBLayouts {
+		paths[i] = filepath.Join(installDir, filepath.FromSlash(layout))
+	}
+	return paths
+}
+
+// defaultInstallPaths 設定ファイル生成時のインストールパス
+func (g *GameProfile) defaultInstallPaths() []InstallPath {
+	home, _ := os.UserHomeDir()
+	dir := g.InstallDirs[0]
+
+	return []InstallPath{
+		// Windows Steam
+		{
+			Name:        g.ID + "-windows-steam",
+			Path:        `C:\Program Files (x86)\Steam\steamapps\common\` + dir + `\data\database\db`,
+			Platform:    "windows",
+			Description: "Windows Steam版 " + g.Short,
+			Game:        g.ID,
+		},
+		// Windows Epic Games
+		{
+			Name:        g.ID + "-windows-epic",
+			Path:        `C:\Program Files\Epic Games\` + dir + `\data\database\db`,
+			Platform:    "windows",
+			Description: "Windows Epic Games版 " + g.Short,
+			Game:        g.ID,
+		},
+		// macOS Steam
+		{
+			Name:        g.ID + "-macos-steam",
+			Path:        filepath.Join(home, "Library/Application Support/Steam/steamapps/common", dir, "data/database/db"),
+			Platform:    "darwin",
+			Description: "macOS Steam版 " + g.Short,
+			Game:        g.ID,
+		},
+		// macOS App Store
+		{
+			Name:        g.ID + "-macos-appstore",
+			Path:        filepath.Join(home, "Library/Application Support/Sports Interactive", dir, "data/database/db"),
+			Platform:    "darwin",
+			Description: "macOS App Store版 " + g.Short,
+			Game:        g.ID,
+		},
+		// Linux Steam（Proton）
+		{
+			Name:        g.ID + "-linux-steam",
+			Path:        filepath.Join(home, ".local/share/Steam/steamapps/common", dir, "data/database/db"),
+			Platform:    "linux",
+			Description: "Linux Steam版 " + g.Short + "（Proton）",
+			Game:        g.ID,
+		},
+		// Linux Flatpak Steam（Proton）
+		{
+			Name:        g.ID + "-linux-steam-flatpak",
+			Path:        filepath.Join(home, ".var/app/com.valvesoftware.Steam/.local/share/Steam/steamapps/common", dir, "data/database/db"),
+			Platform:    "linux",
+			Description: "Linux Flatpak Steam版 " + g.Short + "（Proton）",
+			Game:        g.ID,
+		},
+	}
+}
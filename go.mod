@@ -5,11 +5,11 @@ go 1.21
 require (
 	github.com/fatih/color v1.16.0
 	github.com/spf13/pflag v1.0.5
+	golang.org/x/sys v0.14.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	golang.org/x/sys v0.14.0 // indirect
 )
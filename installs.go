@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// インストールパスの状態
+const (
+	InstallFound         = "found"          // DBバージョンフォルダあり
+	InstallMissing       = "missing"        // パスが存在しない
+	InstallNoDB          = "no-db"          // パスはあるがDBバージョンフォルダがない
+	InstallOtherPlatform = "other-platform" // 他のOS向け
+)
+
+// InstallStatus 設定ファイルのインストールパスの状態
+type InstallStatus struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Path        string   `json:"path"`
+	Platform    string   `json:"platform"`
+	Profile     string   `json:"profile,omitempty"`
+	State       string   `json:"state"`
+	DBVersions  []string `json:"db_versions,omitempty"`
+}
+
+// InstallsReport installs の出力
+type InstallsReport struct {
+	Game     string          `json:"game"`
+	Installs []InstallStatus `json:"installs"`
+	Detected *InstallInfo    `json:"detected"` // 自動スキャンの結果（見つからない場合はnull）
+}
+
+// ListInstalls 設定ファイルのインストールパスの状態と自動スキャンの結果を表示
+func (t *FM24Tool) ListInstalls() error {
+	if t.Output != OutputJSON {
+		color.Cyan("==========================================================")
+		color.Cyan("%s インストール一覧", t.Game.Short)
+		color.Cyan("==========================================================\n")
+	}
+
+	report := &InstallsReport{Game: t.Game.ID, Installs: []InstallStatus{}}
+
+	for _, installPath := range t.Config.InstallPaths {
+		if installPath.gameID() != t.Game.ID {
+			continue
+		}
+
+		status := InstallStatus{
+			Name:        installPath.Name,
+			Description: installPath.Description,
+			Path:        installPath.Path,
+			Platform:    installPath.Platform,
+			Profile:     installPath.Profile,
+		}
+		switch {
+		case installPath.Platform != runtime.GOOS:
+			status.State = InstallOtherPlatform
+		default:
+			if _, err := os.Stat(installPath.Path); err != nil {
+				status.State = InstallMissing
+			} else if versions, err := listVersionFolders(installPath.Path); err != nil {
+				status.State = InstallNoDB
+			} else {
+				status.State = InstallFound
+				status.DBVersions = versions
+			}
+		}
+		report.Installs = append(report.Installs, status)
+	}
+
+	if found, err := t.scanForInstallation(); err == nil {
+		report.Detected = found
+	}
+
+	if t.Output == OutputJSON {
+		return printJSON(report)
+	}
+	logln()
+
+	if len(report.Installs) == 0 {
+		logln("設定ファイルにインストールパスはありません")
+	}
+	for _, status := range report.Installs {
+		switch status.State {
+		case InstallFound:
+			color.Green("  ✓ %s (DB %s)", status.Name, strings.Join(status.DBVersions, ", "))
+		case InstallMissing:
+			color.Yellow("  ✗ %s（見つかりません）", status.Name)
+		case InstallNoDB:
+			color.Yellow("  ✗ %s（DBバージョンフォルダがありません）", status.Name)
+		default:
+			color.White("  - %s（%s 用）", status.Name, status.Platform)
+		}
+		logf("      %s\n", status.Path)
+	}
+
+	logln()
+	if report.Detected != nil {
+		color.Green("自動スキャン: %s", report.Detected.Path)
+	} else {
+		color.Yellow("自動スキャン: 見つかりません")
+	}
+
+	return nil
+}
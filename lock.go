@@ -0,0 +1,232 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// lockFileName バックアップディレクトリに作成するロックファイル
+const lockFileName = ".fm24-real.lock"
+
+// lockDirName インストールのロックファイルを作成する設定ディレクトリ内のフォルダ
+// （ゲームのインストールフォルダにはツールのファイルを残さない）
+const lockDirName = "locks"
+
+// errLocked 他のプロセスがロックを保持している
+var errLocked = errors.New("locked")
+
+// lockInfo ロックファイルに記録する保持者の情報
+type lockInfo struct {
+	Target    string    `json:"target"` // ロック対象のパス
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	Command   string    `json:"command"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// String 保持者の表示
+func (l *lockInfo) String() string {
+	return fmt.Sprintf("PID %d, ホスト %s, 開始 %s, コマンド '%s'", l.PID, l.Host, l.StartedAt.Local().Format("2006-01-02 15:04:05"), l.Command)
+}
+
+// fileLock 取得済みのアドバイザリロック（Unix は flock、Windows は LockFileEx）
+//
+// ロックファイルは削除せずに残し、解放時は内容のみを空にする
+// （削除すると、待機中のプロセスが削除済みのファイルをロックする競合が起きるため）。
+type fileLock struct {
+	path string
+	file *os.File
+}
+
+// acquireLock ロックを取得して保持者の情報を書き込む（他のプロセスが保持している場合は対象を示してエラー）
+//
+// kind はロック対象の種類（表示用）、target はロック対象のパス。
+//
+// 前の保持者の情報が残っている場合、そのプロセスは解放せずに終了している（OSがロックを解放済み）ため、
+// 古いロックとして警告を表示して引き継ぐ。
+func acquireLock(path, kind, target string) (*fileLock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("ロックファイル作成エラー: %w", err)
+	}
+
+	previous := readLockInfo(file)
+	if err := lockFile(file); err != nil {
+		file.Close()
+		if !errors.Is(err, errLocked) {
+			return nil, fmt.Errorf("ロック取得エラー: %s: %w", path, err)
+		}
+		if previous == nil {
+			return nil, fmt.Errorf("別の %s が%sを処理中です: %s\n  ロックファイル: %s", programName, kind, target, path)
+		}
+		message := fmt.Sprintf("別の %s が%sを処理中です: %s（%s）\n  ロックファイル: %s", programName, kind, target, previous, path)
+		if host, _ := os.Hostname(); previous.Host == host && !processAlive(previous.PID) {
+			message += fmt.Sprintf("\n  （PID %d は存在しません。別のコンテナ等から保持されている可能性があります）", previous.PID)
+		}
+		return nil, errors.New(message)
+	}
+
+	if previous != nil {
+		color.Yellow("⚠️  解放されていない古いロックを引き継ぎます（%s）", previous)
+	}
+
+	host, _ := os.Hostname()
+	info := lockInfo{
+		Target:    target,
+		PID:       os.Getpid(),
+		Host:      host,
+		Command:   strings.Join(append([]string{programName}, os.Args[1:]...), " "),
+		StartedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		unlockFile(file)
+		file.Close()
+		return nil, err
+	}
+	if err := writeLockContent(file, append(data, '\n')); err != nil {
+		unlockFile(file)
+		file.Close()
+		return nil, fmt.Errorf("ロックファイル書き込みエラー: %w", err)
+	}
+
+	return &fileLock{path: path, file: file}, nil
+}
+
+// release 保持者の情報を消去してロックを解放
+func (l *fileLock) release() {
+	if err := writeLockContent(l.file, nil); err != nil {
+		color.Yellow("⚠️  ロックファイルの消去に失敗しました: %s - %v", l.path, err)
+	}
+	unlockFile(l.file)
+	l.file.Close()
+}
+
+// readLockInfo ロックファイルの保持者の情報（空または解析できない場合はnil）
+func readLockInfo(file *os.File) *lockInfo {
+	data, err := io.ReadAll(io.NewSectionReader(file, 0, 1<<20))
+	if err != nil || len(strings.TrimSpace(string(data))) == 0 {
+		return nil
+	}
+	var info lockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil
+	}
+	return &info
+}
+
+// writeLockContent ロックファイルの内容を置き換え
+func writeLockContent(file *os.File, data []byte) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := file.WriteAt(data, 0); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// lock ロックを取得し、解放する関数を返す（同じツールで取得済みの場合は何もしない）
+func (t *FM24Tool) lock(path, kind, target string) (func(), error) {
+	if _, held := t.locks[path]; held {
+		return func() {}, nil
+	}
+
+	lock, err := acquireLock(path, kind, target)
+	if err != nil {
+		return nil, err
+	}
+	if t.locks == nil {
+		t.locks = make(map[string]*fileLock)
+	}
+	t.locks[path] = lock
+
+	return func() {
+		delete(t.locks, path)
+		lock.release()
+	}, nil
+}
+
+// installLockPath インストールのロックファイル（設定ディレクトリの locks にDBフォルダごとに作成）
+func installLockPath(dbRoot string) string {
+	if resolved, err := filepath.EvalSymlinks(dbRoot); err == nil {
+		dbRoot = resolved
+	}
+	key := filepath.Clean(dbRoot)
+	if runtime.GOOS == "windows" {
+		key = strings.ToLower(key)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(filepath.Dir(GetDefaultConfigPath()), lockDirName, "install-"+hex.EncodeToString(sum[:8])+".lock")
+}
+
+// lockInstall 検出したインストールのロック
+func (t *FM24Tool) lockInstall() (func(), error) {
+	path := installLockPath(t.DBRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("ロックディレクトリ作成エラー: %w", err)
+	}
+	return t.lock(path, "インストール", t.DBRoot)
+}
+
+// lockBackups バックアップディレクトリのロック（初回の実行が同時に起きた場合も排他するよう、ディレクトリを先に作成）
+func (t *FM24Tool) lockBackups() (func(), error) {
+	root, err := t.backupRoot()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("バックアップディレクトリ作成エラー: %w", err)
+	}
+	return t.lock(filepath.Join(root, lockFileName), "バックアップディレクトリ", root)
+}
+
+// lockForUpdate インストールとバックアップディレクトリを変更するためのロック
+func (t *FM24Tool) lockForUpdate() (func(), error) {
+	releaseInstall, err := t.lockInstall()
+	if err != nil {
+		return nil, err
+	}
+	if !t.backupEnabled() {
+		return releaseInstall, nil
+	}
+
+	releaseBackups, err := t.lockBackups()
+	if err != nil {
+		releaseInstall()
+		return nil, err
+	}
+	return func() {
+		releaseBackups()
+		releaseInstall()
+	}, nil
+}
+
+// lockForRestore 復元のためのロック（一覧の取得から復元まで、選択したバックアップが
+// 削除されないよう、バックアップが無効な設定でもバックアップディレクトリをロック）
+func (t *FM24Tool) lockForRestore() (func(), error) {
+	releaseInstall, err := t.lockInstall()
+	if err != nil {
+		return nil, err
+	}
+	releaseBackups, err := t.lockBackups()
+	if err != nil {
+		releaseInstall()
+		return nil, err
+	}
+	return func() {
+		releaseBackups()
+		releaseInstall()
+	}, nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// deadPID 存在しないプロセスのPID
+func deadPID(t *testing.T) int {
+	t.Helper()
+	for pid := 4000000; pid > 100000; pid -= 7919 {
+		if !processAlive(pid) {
+			return pid
+		}
+	}
+	t.Skip("存在しないPIDが見つかりません")
+	return 0
+}
+
+// writeLockInfo ロックファイルに保持者の情報を書き込む（ロックは取得しない）
+func writeLockInfo(t *testing.T, path string, info lockInfo) {
+	t.Helper()
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireLockHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), lockFileName)
+
+	held, err := acquireLock(path, "インストール", "/games/fm24/db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.release()
+
+	info := readLockInfo(held.file)
+	if info == nil || info.PID != os.Getpid() || info.Target != "/games/fm24/db" {
+		t.Fatalf("保持者の情報 = %+v", info)
+	}
+
+	_, err = acquireLock(path, "インストール", "/games/fm24/db")
+	if err == nil {
+		t.Fatal("保持中のロックを取得できました")
+	}
+	for _, want := range []string{"インストール", "/games/fm24/db", "PID " + strconv.Itoa(os.Getpid()), "ロックファイル: " + path} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("エラーに %q が含まれません: %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "存在しません") {
+		t.Errorf("実行中のプロセスが存在しないと表示されました: %v", err)
+	}
+}
+
+func TestAcquireLockHeldByDeadPID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), lockFileName)
+
+	held, err := acquireLock(path, "インストール", "/games/fm24/db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.release()
+
+	// 別のPID名前空間（コンテナ等）から保持されている状態
+	host, _ := os.Hostname()
+	pid := deadPID(t)
+	info, _ := json.Marshal(lockInfo{PID: pid, Host: host, Command: "fm24-real watch"})
+	if err := writeLockContent(held.file, info); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = acquireLock(path, "インストール", "/games/fm24/db")
+	if err == nil {
+		t.Fatal("保持中のロックを取得できました")
+	}
+	if !strings.Contains(err.Error(), "PID "+strconv.Itoa(pid)+" は存在しません") {
+		t.Errorf("存在しないPIDの案内がありません: %v", err)
+	}
+}
+
+func TestAcquireLockStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), lockFileName)
+
+	// 解放せずに終了したプロセスの情報が残っている
+	writeLockInfo(t, path, lockInfo{PID: deadPID(t), Host: "old-host", Command: "fm24-real apply", StartedAt: time.Now().Add(-time.Hour)})
+
+	lock, err := acquireLock(path, "インストール", "/games/fm24/db")
+	if err != nil {
+		t.Fatalf("古いロックを引き継げません: %v", err)
+	}
+	if info := readLockInfo(lock.file); info == nil || info.PID != os.Getpid() {
+		t.Errorf("保持者の情報が更新されていません: %+v", info)
+	}
+
+	lock.release()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 0 {
+		t.Errorf("解放後も保持者の情報が残っています: %s", data)
+	}
+}
+
+func TestReadLockInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantPID int
+	}{
+		{"空", "", 0},
+		{"空白", "\n  \n", 0},
+		{"不正なJSON", "{pid", 0},
+		{"保持者", `{"pid": 1234, "host": "deck", "command": "fm24-real watch"}`, 1234},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), lockFileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			info := readLockInfo(file)
+			switch {
+			case tt.wantPID == 0 && info != nil:
+				t.Errorf("readLockInfo() = %+v, want nil", info)
+			case tt.wantPID != 0 && (info == nil || info.PID != tt.wantPID):
+				t.Errorf("readLockInfo() = %+v, want PID %d", info, tt.wantPID)
+			}
+		})
+	}
+}
+
+func TestLockInfoString(t *testing.T) {
+	info := &lockInfo{PID: 42, Host: "deck", Command: "fm24-real update --yes", StartedAt: time.Date(2024, 1, 18, 5, 30, 22, 0, time.UTC)}
+	got := info.String()
+	for _, want := range []string{"PID 42", "ホスト deck", "'fm24-real update --yes'", info.StartedAt.Local().Format("2006-01-02 15:04:05")} {
+		if !strings.Contains(got, want) {
+			t.Errorf("String() = %q, %q が含まれません", got, want)
+		}
+	}
+}
+
+func TestLockInstall(t *testing.T) {
+	tool := newTestTool(t)
+
+	release, err := tool.lockInstall()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ゲームのフォルダにはロックファイルを作成しない
+	entries, err := os.ReadDir(tool.DBRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != "2400" {
+			t.Errorf("DBフォルダにツールのファイルが作成されました: %s", entry.Name())
+		}
+	}
+
+	// 同じツールでは再取得できる（何もしない）
+	again, err := tool.lockInstall()
+	if err != nil {
+		t.Fatalf("取得済みのロックを再取得できません: %v", err)
+	}
+	again()
+
+	// 別のツール（別のプロセス相当）からは取得できない
+	other := &FM24Tool{DBRoot: tool.DBRoot}
+	if _, err := other.lockInstall(); err == nil {
+		t.Error("保持中のインストールのロックを取得できました")
+	}
+
+	release()
+	releaseOther, err := other.lockInstall()
+	if err != nil {
+		t.Fatalf("解放後にロックを取得できません: %v", err)
+	}
+	releaseOther()
+}
+
+func TestInstallLockPath(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	a := installLockPath("/games/fm24/db")
+	if a != installLockPath("/games/fm24/db/") {
+		t.Error("同じDBフォルダで異なるロックファイルになりました")
+	}
+	if a == installLockPath("/games/fm23/db") {
+		t.Error("異なるDBフォルダで同じロックファイルになりました")
+	}
+	if filepath.Dir(a) != filepath.Join(filepath.Dir(GetDefaultConfigPath()), lockDirName) {
+		t.Errorf("ロックファイルが設定ディレクトリにありません: %s", a)
+	}
+}
+
+func TestLockBackupsCreatesRoot(t *testing.T) {
+	tool := newTestTool(t)
+	root := filepath.Join(t.TempDir(), "backups", "FM24_Backup")
+	tool.Config.Backup.Directory = root
+
+	release, err := tool.lockBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	if _, err := os.Stat(filepath.Join(root, lockFileName)); err != nil {
+		t.Errorf("バックアップディレクトリのロックファイルがありません: %v", err)
+	}
+	other := &FM24Tool{Config: tool.Config, Game: tool.Game}
+	if _, err := other.lockBackups(); err == nil {
+		t.Error("初回の実行同士が排他されていません")
+	}
+}
@@ -0,0 +1,48 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// stillActive 実行中のプロセスの終了コード（STILL_ACTIVE）
+const stillActive = 259
+
+// lockRegion ロックするバイト範囲（ファイル末尾より後ろをロックし、保持者の情報は他のプロセスから読めるようにする）
+var lockRegion = windows.Overlapped{Offset: 0, OffsetHigh: 0x40000000}
+
+// lockFile LockFileEx で排他ロックを取得（待たずに失敗）
+func lockFile(file *os.File) error {
+	overlapped := lockRegion
+	err := windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &overlapped)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLocked
+	}
+	return err
+}
+
+// unlockFile LockFileEx のロックを解放
+func unlockFile(file *os.File) error {
+	overlapped := lockRegion
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, &overlapped)
+}
+
+// processAlive プロセスが存在するか（終了コードが STILL_ACTIVE か確認）
+func processAlive(pid int) bool {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// アクセスが拒否された場合はプロセスが存在する
+		return errors.Is(err, windows.ERROR_ACCESS_DENIED)
+	}
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
@@ -1,111 +1,650 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/pflag"
 )
 
-var (
-	checkFlag   bool
-	applyFlag   bool
-	updateFlag  bool
-	initFlag    bool
-	configPath  string
-	customPath  string
-	showVersion bool
-	version     = "1.0.0"
-)
+var version = "1.0.0"
+
+func main() {
+	args, err := translateLegacyArgs(os.Args[1:])
+	if err != nil {
+		exitWithError(err)
+	}
 
-func init() {
-	pflag.BoolVarP(&checkFlag, "check", "c", false, "実名化対応されているかチェック")
-	pflag.BoolVarP(&applyFlag, "apply", "a", false, "実名化対応を実施")
-	pflag.BoolVarP(&updateFlag, "update", "u", false, "実名化対応を更新（再適用）")
-	pflag.BoolVarP(&initFlag, "init", "i", false, "デフォルト設定ファイルを生成")
-	pflag.StringVar(&configPath, "config", "", "設定ファイルパス（デフォルト: ~/.config/fm24-real/config.yaml）")
-	pflag.StringVarP(&customPath, "path", "p", "", "FM24データベースのカスタムパス")
-	pflag.BoolVarP(&showVersion, "version", "v", false, "バージョン情報を表示")
+	root := commands()
+	if len(args) > 0 && (args[0] == "--version" || args[0] == "-v") {
+		args = []string{"version"}
+	}
 
-	pflag.Usage = printUsage
+	if err := execute(root, args); err != nil {
+		exitWithError(err)
+	}
 }
 
-func main() {
-	pflag.Parse()
+// exitWithError エラーを表示し、エラー種別に応じた終了コードで終了
+func exitWithError(err error) {
+	var status *StatusError
+	if errors.As(err, &status) && status.Message == "" {
+		// 結果は表示済み（終了コードのみ）
+		os.Exit(status.Code)
+	}
+	if errors.Is(err, errCancelled) {
+		color.Red("❌ %v", err)
+	} else {
+		color.Red("❌ エラー: %v", err)
+	}
+	os.Exit(exitCodeFor(err))
+}
 
-	// バージョン表示
-	if showVersion {
-		fmt.Printf("fm24-real version %s\n", version)
-		os.Exit(0)
+// loadConfig 設定ファイルを読み込み（失敗した場合は生成方法を案内するエラー）
+func loadConfig(opts *options) (*Config, error) {
+	config, err := LoadConfig(opts.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w\n💡 '%s config init' でデフォルト設定ファイルを生成できます", err, programName)
 	}
+	return config, nil
+}
 
-	// init コマンド（設定ファイル生成）
-	if initFlag {
-		if err := GenerateDefaultConfig(); err != nil {
-			color.Red("❌ エラー: %v", err)
-			os.Exit(1)
+// newTool 設定ファイルとオプションからツールを作成
+func newTool(opts *options) (*FM24Tool, error) {
+	config, err := loadConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.profileName != "" {
+		if _, err := config.findProfile(opts.profileName); err != nil {
+			return nil, err
 		}
-		os.Exit(0)
 	}
 
-	// ヘルプまたは引数なし
-	if pflag.NFlag() == 0 {
-		printUsage()
-		os.Exit(0)
+	// --install で指定したインストールのゲームを使用（--game が優先）
+	gameID := opts.gameID
+	if opts.installName != "" {
+		installPath, err := config.findInstallPath(opts.installName)
+		if err != nil {
+			return nil, err
+		}
+		if gameID == "" {
+			gameID = installPath.gameID()
+		}
 	}
 
-	// 設定ファイル読み込み
-	if configPath == "" {
-		configPath = GetDefaultConfigPath()
+	game, err := config.SelectGame(gameID)
+	if err != nil {
+		return nil, err
 	}
 
-	config, err := LoadConfig(configPath)
+	tool, err := NewFM24Tool(config, game)
 	if err != nil {
-		color.Red("❌ 設定ファイル読み込みエラー: %v", err)
-		color.Yellow("💡 'fm24-real --init' でデフォルト設定ファイルを生成できます")
-		os.Exit(1)
+		return nil, err
 	}
+	tool.AssumeYes = opts.yes
+	tool.Output = opts.output
+	tool.Profile = opts.profileName
+	tool.DBVersion = opts.dbVersion
+	tool.AllDBVersions = opts.allVersions
+	tool.InstallName = opts.installName
+	tool.WaitForExit = opts.waitForExit
 
-	tool := NewFM24Tool(config)
+	return tool, nil
+}
 
-	// コマンド実行（優先順位: check > apply > update）
-	if checkFlag {
-		if err := tool.CheckStatus(customPath); err != nil {
-			color.Red("❌ エラー: %v", err)
-			os.Exit(1)
-		}
-	} else if applyFlag {
-		if err := tool.Apply(customPath); err != nil {
-			color.Red("❌ エラー: %v", err)
-			os.Exit(1)
-		}
-	} else if updateFlag {
-		if err := tool.Update(customPath); err != nil {
-			color.Red("❌ エラー: %v", err)
-			os.Exit(1)
-		}
+// validateSelection インストールとDBバージョンの指定を検証
+func validateSelection(fs *pflag.FlagSet, opts *options) error {
+	if err := rejectTogether(fs, "path", "install"); err != nil {
+		return err
+	}
+	return rejectTogether(fs, "db-version", "all-db-versions")
+}
+
+// commands コマンドの一覧
+func commands() *command {
+	root := &command{}
+	root.Children = []*command{
+		{
+			Name:    "check",
+			Summary: "実名化対応されているかチェック",
+			Examples: []string{
+				"fm24-real check                       # 現在の状態を確認",
+				"fm24-real check -o json               # 状態をJSONで出力",
+				"fm24-real check --all-db-versions     # 全DBバージョンの状態を表示",
+			},
+			Flags: func(fs *pflag.FlagSet, opts *options) {
+				configFlag(fs, opts)
+				gameFlag(fs, opts)
+				installFlags(fs, opts)
+				dbVersionFlags(fs, opts, true)
+				profileFlag(fs, opts)
+				outputFlag(fs, opts)
+			},
+			Validate: validateSelection,
+			Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+				tool, err := newTool(opts)
+				if err != nil {
+					return err
+				}
+				status, err := tool.CheckStatus(opts.customPath)
+				if err != nil {
+					return err
+				}
+				if status.ExitCode() != ExitOK {
+					return &StatusError{Code: status.ExitCode()}
+				}
+				return nil
+			},
+		},
+		{
+			Name:    "apply",
+			Summary: "実名化対応を実施",
+			Examples: []string{
+				"fm24-real apply                       # 実名化を適用",
+				"fm24-real apply --dry-run             # 実行計画のみ表示（変更なし）",
+				"fm24-real apply --profile japan-only  # 日本関連のみ実名化",
+				"fm24-real apply --game fm23           # FM23に実名化を適用",
+				"fm24-real apply --install my-fm24     # 設定ファイルのインストールを指定",
+			},
+			Flags:    applyFlags,
+			Validate: validateSelection,
+			Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+				tool, err := newTool(opts)
+				if err != nil {
+					return err
+				}
+				if opts.dryRun {
+					return tool.DryRun(opts.customPath)
+				}
+				return tool.Apply(opts.customPath)
+			},
+		},
+		{
+			Name:    "update",
+			Summary: "実名化対応を更新（ゲームのアップデート後に再適用）",
+			Examples: []string{
+				"fm24-real update --yes                # 確認なしで再適用（スクリプト用）",
+			},
+			Flags:    applyFlags,
+			Validate: validateSelection,
+			Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+				tool, err := newTool(opts)
+				if err != nil {
+					return err
+				}
+				if opts.dryRun {
+					return tool.DryRun(opts.customPath)
+				}
+				return tool.Update(opts.customPath)
+			},
+		},
+		{
+			Name:    "watch",
+			Summary: "DBフォルダを監視し、アップデートで復活したファイルを自動で再適用",
+			Examples: []string{
+				"fm24-real watch                       # アップデートを監視して自動で再適用",
+				"fm24-real watch --quiet-period 2m     # 書き込みが2分止まってから再適用",
+			},
+			Flags: func(fs *pflag.FlagSet, opts *options) {
+				configFlag(fs, opts)
+				gameFlag(fs, opts)
+				installFlags(fs, opts)
+				dbVersionFlags(fs, opts, true)
+				profileFlag(fs, opts)
+				fs.DurationVar(&opts.quietPeriod, "quiet-period", 0, "変更の検出後、再適用まで待つ変更のない時間（デフォルト: watch.quiet_period または 60s）")
+				fs.DurationVar(&opts.pollInterval, "poll-interval", 0, "ポーリングの確認間隔（デフォルト: watch.poll_interval または 30s）")
+				fs.BoolVar(&opts.poll, "poll", false, "変更通知を使わずポーリングで監視")
+			},
+			Validate: func(fs *pflag.FlagSet, opts *options) error {
+				if err := validateSelection(fs, opts); err != nil {
+					return err
+				}
+				if opts.quietPeriod < 0 || opts.pollInterval < 0 {
+					return fmt.Errorf("--quiet-period と --poll-interval には0以上の時間を指定してください")
+				}
+				return nil
+			},
+			Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+				tool, err := newTool(opts)
+				if err != nil {
+					return err
+				}
+				settings := tool.Config.Watch
+				if fs.Changed("quiet-period") {
+					settings.QuietPeriod = opts.quietPeriod
+				}
+				if fs.Changed("poll-interval") {
+					settings.PollInterval = opts.pollInterval
+				}
+				if opts.poll {
+					settings.Poll = true
+				}
+				return tool.Watch(opts.customPath, settings)
+			},
+		},
+		{
+			Name:    "service",
+			Summary: "watch をログイン時に起動するサービスの管理（systemd, launchd, Task Scheduler）",
+			Children: []*command{
+				{
+					Name:    "install",
+					Summary: "監視サービスをインストールして起動",
+					Examples: []string{
+						"fm24-real service install             # 自動検出したインストールを監視するサービスを登録",
+						"fm24-real service install --install my-fm24 --install my-fm23  # インストールごとに登録",
+						"fm24-real service install --dry-run --platform darwin  # 生成する定義ファイルを表示",
+					},
+					Flags: func(fs *pflag.FlagSet, opts *options) {
+						configFlag(fs, opts)
+						gameFlag(fs, opts)
+						serviceInstallFlag(fs, opts)
+						dbVersionFlags(fs, opts, true)
+						profileFlag(fs, opts)
+						fs.BoolVarP(&opts.dryRun, "dry-run", "n", false, "定義ファイルを書き込まず内容を表示")
+						fs.StringVar(&opts.platform, "platform", "", "生成する定義ファイルのプラットフォーム（--dry-run のみ、windows, darwin, linux）")
+					},
+					Validate: func(fs *pflag.FlagSet, opts *options) error {
+						if err := rejectTogether(fs, "db-version", "all-db-versions"); err != nil {
+							return err
+						}
+						if opts.platform != "" && !opts.dryRun {
+							return fmt.Errorf("--platform は --dry-run と組み合わせて指定してください")
+						}
+						if opts.platform != "" && !slices.Contains(knownPlatforms, opts.platform) {
+							return fmt.Errorf("不明なプラットフォーム: %s（%s）", opts.platform, strings.Join(knownPlatforms, ", "))
+						}
+						return nil
+					},
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						return InstallService(serviceOptions(opts))
+					},
+				},
+				{
+					Name:     "uninstall",
+					Summary:  "監視サービスを停止して削除",
+					Examples: []string{"fm24-real service uninstall --install my-fm24  # サービスを削除"},
+					Flags: func(fs *pflag.FlagSet, opts *options) {
+						gameFlag(fs, opts)
+						serviceInstallFlag(fs, opts)
+						configFlag(fs, opts)
+					},
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						return UninstallService(serviceOptions(opts))
+					},
+				},
+				{
+					Name:     "status",
+					Summary:  "インストール済みの監視サービスと状態を表示",
+					Examples: []string{"fm24-real service status              # 監視サービスの状態を表示"},
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						return ServiceStatus()
+					},
+				},
+			},
+		},
+		{
+			Name:    "restore",
+			Summary: "バックアップから復元",
+			Examples: []string{
+				"fm24-real restore                     # 最新のバックアップから復元",
+				"fm24-real restore --snapshot 20240118_143022  # 指定したバックアップから復元",
+			},
+			Flags: func(fs *pflag.FlagSet, opts *options) {
+				configFlag(fs, opts)
+				gameFlag(fs, opts)
+				installFlags(fs, opts)
+				dbVersionFlags(fs, opts, false)
+				fs.StringVar(&opts.snapshotID, "snapshot", "", "復元するバックアップID（例: 20240118_143022、デフォルト: 最新）")
+				fs.BoolVar(&opts.force, "force", false, "内容が異なるファイルも上書き")
+				waitForExitFlag(fs, opts)
+				yesFlags(fs, opts)
+			},
+			Validate: validateSelection,
+			Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+				tool, err := newTool(opts)
+				if err != nil {
+					return err
+				}
+				return tool.Restore(opts.customPath, opts.snapshotID, opts.force)
+			},
+		},
+		{
+			Name:    "backups",
+			Summary: "バックアップの一覧・整理・検証",
+			Children: []*command{
+				{
+					Name:     "list",
+					Summary:  "バックアップ一覧を表示",
+					Examples: []string{"fm24-real backups list                # バックアップ一覧を表示"},
+					Flags: func(fs *pflag.FlagSet, opts *options) {
+						configFlag(fs, opts)
+						gameFlag(fs, opts)
+					},
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						tool, err := newTool(opts)
+						if err != nil {
+							return err
+						}
+						return tool.ListBackups()
+					},
+				},
+				{
+					Name:     "prune",
+					Summary:  "保持ルールに従って古いバックアップを削除",
+					Examples: []string{"fm24-real backups prune --keep-last 5 # 最新5件と最初のバックアップ以外を削除"},
+					Flags: func(fs *pflag.FlagSet, opts *options) {
+						configFlag(fs, opts)
+						gameFlag(fs, opts)
+						fs.IntVar(&opts.keepLast, "keep-last", 0, "インストールごとに保持する最新バックアップ数（デフォルト: backup.retention.keep_last）")
+						fs.IntVar(&opts.keepDays, "keep-days", 0, "指定日数以内のバックアップを保持（デフォルト: backup.retention.keep_days）")
+						yesFlags(fs, opts)
+					},
+					Validate: func(fs *pflag.FlagSet, opts *options) error {
+						if opts.keepLast < 0 || opts.keepDays < 0 {
+							return fmt.Errorf("--keep-last と --keep-days には0以上を指定してください")
+						}
+						return nil
+					},
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						tool, err := newTool(opts)
+						if err != nil {
+							return err
+						}
+						retention := tool.Config.Backup.Retention
+						if fs.Changed("keep-last") {
+							retention.KeepLast = opts.keepLast
+						}
+						if fs.Changed("keep-days") {
+							retention.KeepDays = opts.keepDays
+						}
+						return tool.PruneBackups(retention, true)
+					},
+				},
+				{
+					Name:    "verify",
+					Args:    "[バックアップID]",
+					Summary: "バックアップをマニフェストと照合（省略時は全件）",
+					Examples: []string{
+						"fm24-real backups verify              # 全バックアップをマニフェストと照合",
+						"fm24-real backups verify 20240118_143022  # 指定したバックアップを照合",
+					},
+					MaxArgs: 1,
+					Flags: func(fs *pflag.FlagSet, opts *options) {
+						configFlag(fs, opts)
+						gameFlag(fs, opts)
+					},
+					Complete: func(opts *options, args []string) []string {
+						if len(args) > 0 {
+							return nil
+						}
+						return completeBackupIDs(opts)
+					},
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						tool, err := newTool(opts)
+						if err != nil {
+							return err
+						}
+						snapshotID := ""
+						if len(args) > 0 {
+							snapshotID = args[0]
+						}
+						return tool.VerifyBackups(snapshotID)
+					},
+				},
+			},
+		},
+		{
+			Name:     "installs",
+			Summary:  "設定ファイルのインストールパスの状態と自動スキャンの結果を表示",
+			Examples: []string{"fm24-real installs                    # 検出できるインストールを表示"},
+			Flags: func(fs *pflag.FlagSet, opts *options) {
+				configFlag(fs, opts)
+				gameFlag(fs, opts)
+				outputFlag(fs, opts)
+			},
+			Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+				tool, err := newTool(opts)
+				if err != nil {
+					return err
+				}
+				return tool.ListInstalls()
+			},
+		},
+		{
+			Name:    "config",
+			Summary: "設定ファイルの生成・検証・表示・編集",
+			Children: []*command{
+				{
+					Name:     "init",
+					Summary:  "デフォルト設定ファイルを生成",
+					Examples: []string{"fm24-real config init                 # 設定ファイルを生成"},
+					Flags: func(fs *pflag.FlagSet, opts *options) {
+						configFlag(fs, opts)
+						yesFlags(fs, opts)
+					},
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						return GenerateDefaultConfig(opts.configPath, opts.yes)
+					},
+				},
+				{
+					Name:     "validate",
+					Summary:  "設定ファイルとパスの存在を検証",
+					Examples: []string{"fm24-real config validate             # 設定ファイルを検証"},
+					Flags:    configFlag,
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						return ValidateConfig(opts.configPath)
+					},
+				},
+				{
+					Name:     "show",
+					Summary:  "有効な設定と各値の定義元を表示",
+					Examples: []string{"fm24-real config show                 # 有効な設定と定義元を表示"},
+					Flags: func(fs *pflag.FlagSet, opts *options) {
+						configFlag(fs, opts)
+						gameFlag(fs, opts)
+						profileFlag(fs, opts)
+						outputFlag(fs, opts)
+					},
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						return ShowConfig(opts.configPath, opts.gameID, opts.profileName, opts.output)
+					},
+				},
+				{
+					Name:     "add-install",
+					Args:     "<名前> <パス>",
+					Summary:  "インストールパスを追加（コメントと書式は保持）",
+					Examples: []string{"fm24-real config add-install my-fm24 /path/to/db  # インストールパスを追加"},
+					MinArgs:  2,
+					MaxArgs:  2,
+					Flags: func(fs *pflag.FlagSet, opts *options) {
+						configFlag(fs, opts)
+						fs.StringVar(&opts.platform, "platform", "", "プラットフォーム（windows, darwin, linux、デフォルト: 現在のOS）")
+						gameFlag(fs, opts)
+						profileFlag(fs, opts)
+						fs.StringVar(&opts.description, "description", "", "インストールパスの説明")
+					},
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						return AddInstallPath(opts.configPath, InstallPath{
+							Name:        args[0],
+							Path:        args[1],
+							Platform:    opts.platform,
+							Description: opts.description,
+							Game:        opts.gameID,
+							Profile:     opts.profileName,
+						})
+					},
+				},
+				{
+					Name:     "remove-install",
+					Args:     "<名前>",
+					Summary:  "インストールパスを削除（コメントと書式は保持）",
+					Examples: []string{"fm24-real config remove-install my-fm24  # インストールパスを削除"},
+					MinArgs:  1,
+					MaxArgs:  1,
+					Flags:    configFlag,
+					Complete: func(opts *options, args []string) []string {
+						if len(args) > 0 {
+							return nil
+						}
+						return completeInstalls(opts)
+					},
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						return RemoveInstallPath(opts.configPath, args[0])
+					},
+				},
+			},
+		},
+		{
+			Name:    "rules",
+			Summary: "削除対象ルールとルールパックの管理",
+			Children: []*command{
+				{
+					Name:     "list",
+					Summary:  "ルールパックと有効なルールを表示",
+					Examples: []string{"fm24-real rules list                  # ルールパックと有効なルールを表示"},
+					Flags: func(fs *pflag.FlagSet, opts *options) {
+						configFlag(fs, opts)
+						gameFlag(fs, opts)
+					},
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						// 検証に失敗したルールパックがあっても実行できるようにする
+						config, game, err := loadConfigAndGame(opts)
+						if err != nil {
+							return err
+						}
+						return ListRules(config, game)
+					},
+				},
+				{
+					Name:     "import",
+					Args:     "<ルールパック>",
+					Summary:  "署名付きルールパックをインポート",
+					Examples: []string{"fm24-real rules import fm24-2430.yaml # 署名付きルールパックをインポート"},
+					MinArgs:  1,
+					MaxArgs:  1,
+					Flags: func(fs *pflag.FlagSet, opts *options) {
+						configFlag(fs, opts)
+						gameFlag(fs, opts)
+						fs.BoolVar(&opts.force, "force", false, "インポート済みのものより古いバージョンで上書き")
+					},
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						config, game, err := loadConfigAndGame(opts)
+						if err != nil {
+							return err
+						}
+						return ImportRulePack(config, game, args[0], opts.force)
+					},
+				},
+				{
+					Name:     "keygen",
+					Args:     "<鍵ファイル名>",
+					Summary:  "ルールパック署名用の鍵ペアを生成",
+					Examples: []string{"fm24-real rules keygen mykey          # mykey.key と mykey.pub を生成"},
+					MinArgs:  1,
+					MaxArgs:  1,
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						return GenerateSigningKey(args[0])
+					},
+				},
+				{
+					Name:     "sign",
+					Args:     "<ルールパック>",
+					Summary:  "ルールパックに署名",
+					Examples: []string{"fm24-real rules sign pack.yaml --key mykey.key  # ルールパックに署名"},
+					MinArgs:  1,
+					MaxArgs:  1,
+					Flags: func(fs *pflag.FlagSet, opts *options) {
+						fs.StringVar(&opts.keyPath, "key", "", "署名に使う秘密鍵ファイル（必須）")
+					},
+					Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+						return SignRulePack(args[0], opts.keyPath)
+					},
+				},
+			},
+		},
+		{
+			Name:     "completion",
+			Args:     "<bash|zsh|fish|powershell>",
+			Summary:  "シェルの補完スクリプトを出力",
+			Examples: []string{"source <(fm24-real completion bash)   # bash で補完を有効化"},
+			MinArgs:  1,
+			MaxArgs:  1,
+			Complete: func(opts *options, args []string) []string {
+				if len(args) > 0 {
+					return nil
+				}
+				return completionShells
+			},
+			Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+				script, err := completionScript(args[0])
+				if err != nil {
+					return err
+				}
+				fmt.Print(script)
+				return nil
+			},
+		},
+		{
+			Name:    "version",
+			Summary: "バージョン情報を表示",
+			Run: func(fs *pflag.FlagSet, opts *options, args []string) error {
+				fmt.Printf("fm24-real version %s\n", version)
+				return nil
+			},
+		},
+	}
+	root.Children = append(root.Children, helpCommand(root), completeCommand(root))
+	root.link()
+	return root
+}
+
+// applyFlags apply と update のオプション
+func applyFlags(fs *pflag.FlagSet, opts *options) {
+	configFlag(fs, opts)
+	gameFlag(fs, opts)
+	installFlags(fs, opts)
+	dbVersionFlags(fs, opts, true)
+	profileFlag(fs, opts)
+	fs.BoolVarP(&opts.dryRun, "dry-run", "n", false, "実行計画のみ表示（ファイルは変更しない）")
+	outputFlag(fs, opts)
+	waitForExitFlag(fs, opts)
+	yesFlags(fs, opts)
+}
+
+// serviceInstallFlag 監視するインストール（複数指定可）
+func serviceInstallFlag(fs *pflag.FlagSet, opts *options) {
+	fs.StringArrayVar(&opts.installs, "install", nil, "監視する設定ファイルのインストールパス名（複数指定でインストールごとにサービスを作成）")
+}
+
+// serviceOptions コマンドラインオプションから service コマンドのオプションを作成
+func serviceOptions(opts *options) ServiceOptions {
+	return ServiceOptions{
+		ConfigPath:    opts.configPath,
+		Game:          opts.gameID,
+		Installs:      opts.installs,
+		Profile:       opts.profileName,
+		DBVersion:     opts.dbVersion,
+		AllDBVersions: opts.allVersions,
+		Platform:      opts.platform,
+		DryRun:        opts.dryRun,
 	}
 }
 
-func printUsage() {
-	fmt.Printf("Football Manager 2024 実名化ツール v%s\n\n", version)
-	fmt.Println("使用方法:")
-	fmt.Println("  fm24-real [オプション]")
-	fmt.Println()
-	fmt.Println("オプション:")
-	pflag.PrintDefaults()
-	fmt.Println()
-	fmt.Println("例:")
-	fmt.Println("  fm24-real --init                      # 設定ファイルを生成")
-	fmt.Println("  fm24-real --check                     # 現在の状態を確認")
-	fmt.Println("  fm24-real -c                          # 現在の状態を確認（短縮形）")
-	fmt.Println("  fm24-real --apply                     # 実名化を適用")
-	fmt.Println("  fm24-real --update                    # アップデート後に再適用")
-	fmt.Println("  fm24-real --apply --path /path/to/db  # カスタムパスで実名化")
-	fmt.Println("  fm24-real --config custom.yaml -c     # カスタム設定ファイル使用")
-	fmt.Println()
-	fmt.Println("設定ファイル:")
-	fmt.Printf("  デフォルト: %s\n", GetDefaultConfigPath())
-	fmt.Println()
+// loadConfigAndGame 設定ファイルを読み込み、対象のゲームを決定
+func loadConfigAndGame(opts *options) (*Config, *GameProfile, error) {
+	config, err := loadConfig(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	game, err := config.SelectGame(opts.gameID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return config, game, nil
 }
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// manifestFileName スナップショット内のマニフェストファイル名
+const manifestFileName = "manifest.json"
+
+// BackupManifest バックアップスナップショットのマニフェスト
+type BackupManifest struct {
+	ToolVersion string          `json:"tool_version"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Game        string          `json:"game,omitempty"`
+	SourcePath  string          `json:"source_path"`
+	DBVersion   string          `json:"db_version"`
+	Files       []ManifestEntry `json:"files"`
+}
+
+// ManifestEntry マニフェストに記録するファイル情報
+type ManifestEntry struct {
+	Path    string      `json:"path"`
+	Size    int64       `json:"size"`
+	Mode    os.FileMode `json:"mode"`
+	ModTime time.Time   `json:"mtime"`
+	SHA256  string      `json:"sha256"`
+}
+
+// VerifyResult マニフェスト検証の結果
+type VerifyResult struct {
+	OK        []string
+	Missing   []string
+	Corrupted []string
+	Unlisted  []string
+}
+
+// HasProblems 検証で問題が見つかったか
+func (r *VerifyResult) HasProblems() bool {
+	return len(r.Missing) > 0 || len(r.Corrupted) > 0
+}
+
+// newBackupManifest 現在のインストールに対するマニフェストを作成
+func (t *FM24Tool) newBackupManifest() *BackupManifest {
+	return &BackupManifest{
+		ToolVersion: version,
+		CreatedAt:   time.Now(),
+		Game:        t.Game.ID,
+		SourcePath:  t.DBBasePath,
+		DBVersion:   filepath.Base(t.DBBasePath),
+	}
+}
+
+// add マニフェストにファイルを追加
+func (m *BackupManifest) add(entry ManifestEntry) {
+	m.Files = append(m.Files, entry)
+}
+
+// marshalManifest マニフェストをJSONに変換
+func marshalManifest(manifest *BackupManifest) ([]byte, error) {
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("マニフェスト生成エラー: %w", err)
+	}
+
+	return data, nil
+}
+
+// parseManifest JSONからマニフェストを読み込み
+func parseManifest(data []byte) (*BackupManifest, error) {
+	var manifest BackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("マニフェスト解析エラー: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// writeManifest マニフェストをスナップショットに書き込み
+func writeManifest(snapshotPath string, manifest *BackupManifest) error {
+	data, err := marshalManifest(manifest)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(snapshotPath, manifestFileName), data, 0644); err != nil {
+		return fmt.Errorf("マニフェスト保存エラー: %w", err)
+	}
+
+	return nil
+}
+
+// readManifest スナップショットのマニフェストを読み込み（存在しない場合はnil）
+func readManifest(snapshotPath string) (*BackupManifest, error) {
+	data, err := os.ReadFile(filepath.Join(snapshotPath, manifestFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return parseManifest(data)
+}
+
+// copyFileWithHash ファイルをコピーし、サイズとSHA-256を返す
+func copyFileWithHash(srcPath, dstPath string, mode os.FileMode) (int64, string, error) {
+	dst, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return 0, "", err
+	}
+
+	size, sum, err := copyWithHash(dst, srcPath)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	return size, sum, nil
+}
+
+// writeFileAtomic 同じディレクトリの一時ファイルに書き込んでから置き換える
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.partial")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if syncErr := tmp.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// copyWithHash ファイル内容を書き込み先にコピーし、サイズとSHA-256を返す
+func copyWithHash(dst io.Writer, srcPath string) (int64, string, error) {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return 0, "", err
+	}
+	defer src.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(dst, hash), src)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// sha256Hex データのSHA-256を16進文字列で取得
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifySnapshot スナップショットをマニフェストと照合
+func verifySnapshot(reader snapshotReader, manifest *BackupManifest) (*VerifyResult, error) {
+	result := &VerifyResult{}
+	listed := make(map[string]bool)
+
+	for _, entry := range manifest.Files {
+		listed[entry.Path] = true
+
+		data, err := reader.ReadFile(entry.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			result.Missing = append(result.Missing, entry.Path)
+			continue
+		}
+		if err != nil || int64(len(data)) != entry.Size || sha256Hex(data) != entry.SHA256 {
+			result.Corrupted = append(result.Corrupted, entry.Path)
+			continue
+		}
+
+		result.OK = append(result.OK, entry.Path)
+	}
+
+	// マニフェストに記載のないファイル
+	files, err := reader.Files()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range files {
+		if !listed[path] {
+			result.Unlisted = append(result.Unlisted, path)
+		}
+	}
+
+	return result, nil
+}
+
+// VerifyBackups バックアップスナップショットをマニフェストと照合（IDが空の場合は全件）
+func (t *FM24Tool) VerifyBackups(snapshotID string) error {
+	color.Cyan("==========================================================")
+	color.Cyan("%s バックアップ検証", t.Game.Short)
+	color.Cyan("==========================================================\n")
+
+	root, err := t.backupRoot()
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := t.gameSnapshots(root)
+	if err != nil {
+		return fmt.Errorf("バックアップ一覧取得エラー: %w", err)
+	}
+
+	if snapshotID != "" {
+		var selected []BackupSnapshot
+		for _, snapshot := range snapshots {
+			if snapshot.ID == snapshotID {
+				selected = append(selected, snapshot)
+			}
+		}
+		if len(selected) == 0 {
+			return notFoundf("指定されたバックアップが見つかりません: %s", snapshotID)
+		}
+		snapshots = selected
+	}
+
+	if len(snapshots) == 0 {
+		return notFoundf("バックアップが見つかりません: %s", root)
+	}
+
+	failed := 0
+	for i := range snapshots {
+		snapshot := &snapshots[i]
+		fmt.Printf("📦 %s\n", snapshot.ID)
+
+		reader, err := openSnapshot(snapshot)
+		if err != nil {
+			color.Red("  ❌ %v", err)
+			failed++
+			continue
+		}
+
+		manifest, err := reader.Manifest()
+		if err != nil || manifest == nil {
+			if err != nil {
+				color.Red("  ❌ %v", err)
+			} else {
+				color.Yellow("  ⊘ マニフェストがありません（検証できません）")
+			}
+			reader.Close()
+			failed++
+			continue
+		}
+
+		result, err := verifySnapshot(reader, manifest)
+		reader.Close()
+		if err != nil {
+			color.Red("  ❌ 検証エラー: %v", err)
+			failed++
+			continue
+		}
+
+		for _, path := range result.Missing {
+			color.Red("  ✗ %s: 欠落", path)
+		}
+		for _, path := range result.Corrupted {
+			color.Red("  ✗ %s: 破損（ハッシュ不一致）", path)
+		}
+		for _, path := range result.Unlisted {
+			color.Yellow("  ⊘ %s: マニフェストに記載なし", path)
+		}
+
+		if result.HasProblems() {
+			failed++
+			color.Red("  ❌ 検証失敗: 正常 %d / 欠落 %d / 破損 %d", len(result.OK), len(result.Missing), len(result.Corrupted))
+		} else {
+			color.Green("  ✓ 検証成功: %d個のファイル (DB %s, %s)", len(result.OK), manifest.DBVersion, manifest.SourcePath)
+		}
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("%d個のバックアップで問題が見つかりました", failed)
+	}
+
+	color.Green("✅ すべてのバックアップが正常です")
+	return nil
+}
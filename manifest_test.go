@@ -0,0 +1,150 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Date(2024, 1, 18, 14, 30, 22, 0, time.UTC)
+	manifest := &BackupManifest{
+		ToolVersion: "1.2.0",
+		CreatedAt:   modTime,
+		Game:        "fm24",
+		SourcePath:  "/games/fm24/db/2400",
+		DBVersion:   "2400",
+		Files: []ManifestEntry{
+			{Path: "lnc/all/a.lnc", Size: 5, Mode: 0644, ModTime: modTime, SHA256: sha256Hex([]byte("lnc a"))},
+			{Path: "dbc/permanent/license.dbc", Size: 7, Mode: 0600, ModTime: modTime, SHA256: sha256Hex([]byte("license"))},
+		},
+	}
+
+	if err := writeManifest(dir, manifest); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ファイルはパス順に保存される
+	if got.Files[0].Path != "dbc/permanent/license.dbc" {
+		t.Errorf("ファイルがパス順になっていません: %q", got.Files[0].Path)
+	}
+	if !reflect.DeepEqual(got, manifest) {
+		t.Errorf("readManifest() = %+v\nwant %+v", got, manifest)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // 空の場合はマニフェストなし
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "マニフェストなし", wantNil: true},
+		{name: "不正なJSON", content: "{files", wantErr: true},
+		{name: "ゲームなし（旧形式）", content: `{"tool_version": "1.0.0", "db_version": "2400", "files": []}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.content != "" {
+				writeTree(t, dir, map[string]string{manifestFileName: tt.content})
+			}
+
+			manifest, err := readManifest(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (manifest == nil) != tt.wantNil {
+				t.Errorf("readManifest() = %+v, wantNil %v", manifest, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestVerifySnapshot(t *testing.T) {
+	files := map[string]string{
+		"dbc/permanent/license.dbc": "license",
+		"edt/permanent/fake.edt":    "fake",
+		"lnc/all/a.lnc":             "lnc a",
+	}
+
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, dir string)
+		want   VerifyResult
+	}{
+		{
+			name: "正常",
+			want: VerifyResult{OK: sortedPaths(files)},
+		},
+		{
+			name: "ファイルの欠落",
+			modify: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, "edt", "permanent", "fake.edt")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: VerifyResult{OK: []string{"dbc/permanent/license.dbc", "lnc/all/a.lnc"}, Missing: []string{"edt/permanent/fake.edt"}},
+		},
+		{
+			name: "内容の破損（サイズ同じ）",
+			modify: func(t *testing.T, dir string) {
+				writeTree(t, dir, map[string]string{"lnc/all/a.lnc": "lnc b"})
+			},
+			want: VerifyResult{OK: []string{"dbc/permanent/license.dbc", "edt/permanent/fake.edt"}, Corrupted: []string{"lnc/all/a.lnc"}},
+		},
+		{
+			name: "サイズの不一致",
+			modify: func(t *testing.T, dir string) {
+				writeTree(t, dir, map[string]string{"dbc/permanent/license.dbc": "license!"})
+			},
+			want: VerifyResult{OK: []string{"edt/permanent/fake.edt", "lnc/all/a.lnc"}, Corrupted: []string{"dbc/permanent/license.dbc"}},
+		},
+		{
+			name: "マニフェストにないファイル",
+			modify: func(t *testing.T, dir string) {
+				writeTree(t, dir, map[string]string{"dbc/permanent/extra.dbc": "extra"})
+			},
+			want: VerifyResult{OK: sortedPaths(files), Unlisted: []string{"dbc/permanent/extra.dbc"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := writeTestSnapshot(t, BackupFormatDir, files)
+			if tt.modify != nil {
+				tt.modify(t, snapshot.Path)
+			}
+
+			reader, err := openSnapshot(snapshot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+
+			got, err := verifySnapshot(reader, snapshot.Manifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, list := range [][]string{got.OK, got.Missing, got.Corrupted, got.Unlisted} {
+				slices.Sort(list)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("verifySnapshot() = %+v\nwant %+v", *got, tt.want)
+			}
+			if wantProblems := len(tt.want.Missing)+len(tt.want.Corrupted) > 0; got.HasProblems() != wantProblems {
+				t.Errorf("HasProblems() = %v, want %v", got.HasProblems(), wantProblems)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+)
+
+// 出力形式
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// validateOutput 出力形式を検証
+func validateOutput(output string) error {
+	switch output {
+	case "", OutputTable, OutputJSON:
+		return nil
+	default:
+		return fmt.Errorf("不明な出力形式です: %s (table, json のいずれかを指定してください)", output)
+	}
+}
+
+// logf 進行状況を表示（JSON出力時は color.Output が標準エラー出力になる）
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(color.Output, format, args...)
+}
+
+// logln 進行状況を1行表示
+func logln(args ...interface{}) {
+	fmt.Fprintln(color.Output, args...)
+}
+
+// printJSON 結果をJSONで標準出力に出力
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// confirm y/n の確認を求める（assumeYes の場合は確認せずに続行、入力がない場合は続行しない）
+func confirm(prompt string, assumeYes bool) bool {
+	logf("%s", prompt)
+	if assumeYes {
+		logln("y (--yes)")
+		return true
+	}
+
+	var response string
+	if _, err := fmt.Scanln(&response); errors.Is(err, io.EOF) {
+		logln()
+		color.Yellow("⚠️  入力がないため続行しません（確認せずに実行するには --yes を指定してください）")
+		return false
+	}
+	return response == "y" || response == "Y"
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// PlanItem 実名化処理でバックアップ・削除されるパス
+type PlanItem struct {
+	Path        string `json:"path"`
+	FullPath    string `json:"full_path"`
+	IsDirectory bool   `json:"is_directory"`
+	Size        int64  `json:"size"`
+	Files       int    `json:"files"`
+	Rule        string `json:"rule"`
+	Backup      string `json:"backup,omitempty"`
+}
+
+// PlanSkip 対象が見つからなかったルール
+type PlanSkip struct {
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+}
+
+// Plan 実名化処理の実行計画
+type Plan struct {
+	Game           string     `json:"game"`
+	DBBasePath     string     `json:"db_base_path"`
+	DBVersion      string     `json:"db_version"`
+	Profile        string     `json:"profile"`
+	BackupEnabled  bool       `json:"backup_enabled"`
+	BackupFormat   string     `json:"backup_format,omitempty"`
+	BackupLocation string     `json:"backup_location,omitempty"`
+	Items          []PlanItem `json:"items"`
+	Skipped        []PlanSkip `json:"skipped"`
+	TotalSize      int64      `json:"total_size"`
+	TotalFiles     int        `json:"total_files"`
+}
+
+// buildPlan 現在のインストールに対する実行計画を作成（ファイルは変更しない）
+func (t *FM24Tool) buildPlan(backupLocation string) (*Plan, error) {
+	profile, err := t.selectProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		Game:           t.Game.ID,
+		DBBasePath:     t.DBBasePath,
+		DBVersion:      filepath.Base(t.DBBasePath),
+		Profile:        profile.Name,
+		BackupEnabled:  backupLocation != "",
+		BackupLocation: backupLocation,
+		Items:          []PlanItem{},
+		Skipped:        []PlanSkip{},
+	}
+	if plan.BackupEnabled {
+		plan.BackupFormat = t.backupFormat()
+	}
+
+	// ルールごとの一致を先に集め、重複・入れ子になったパスを除いてから計画に追加する
+	type ruleMatches struct {
+		rule  Rule
+		paths []string
+	}
+	var matches []ruleMatches
+	candidates := make(map[string]bool)
+	for _, rule := range t.profileRules(profile) {
+		match, err := rule.match(t.DBBasePath)
+		if err != nil {
+			return nil, fmt.Errorf("ルール %s: %w", rule.Name, err)
+		}
+		if len(match.Paths) == 0 {
+			plan.Skipped = append(plan.Skipped, PlanSkip{Rule: rule.Description, Reason: match.Reason})
+			continue
+		}
+		matches = append(matches, ruleMatches{rule: rule, paths: match.Paths})
+		for _, path := range match.Paths {
+			candidates[filepath.Clean(path)] = true
+		}
+	}
+
+	planned := make(map[string]bool)
+	for _, m := range matches {
+		added := 0
+		for _, path := range m.paths {
+			path = filepath.Clean(path)
+			if planned[path] || coveredByAncestor(path, t.DBBasePath, candidates) {
+				continue
+			}
+			if err := plan.add(path, m.rule.Description); err != nil {
+				return nil, err
+			}
+			planned[path] = true
+			added++
+		}
+		if added == 0 {
+			plan.Skipped = append(plan.Skipped, PlanSkip{Rule: m.rule.Description, Reason: "他のルールの対象に含まれます"})
+		}
+	}
+
+	return plan, nil
+}
+
+// coveredByAncestor 親ディレクトリのいずれかが削除対象か（ディレクトリごと削除されるパスは個別に処理しない）
+func coveredByAncestor(path, basePath string, targets map[string]bool) bool {
+	basePath = filepath.Clean(basePath)
+	for dir := filepath.Dir(path); dir != basePath && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if targets[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// add 実行計画にパスを追加
+func (p *Plan) add(fullPath, rule string) error {
+	relPath, err := filepath.Rel(p.DBBasePath, fullPath)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return err
+	}
+
+	item := PlanItem{
+		Path:        filepath.ToSlash(relPath),
+		FullPath:    fullPath,
+		IsDirectory: info.IsDir(),
+		Rule:        rule,
+	}
+
+	if info.IsDir() {
+		filepath.Walk(fullPath, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				item.Size += info.Size()
+				item.Files++
+			}
+			return nil
+		})
+	} else {
+		item.Size = info.Size()
+		item.Files = 1
+	}
+
+	if p.BackupEnabled {
+		item.Backup = snapshotEntryLocation(p.BackupFormat, p.BackupLocation, item.Path)
+	}
+
+	p.Items = append(p.Items, item)
+	p.TotalSize += item.Size
+	p.TotalFiles += item.Files
+
+	return nil
+}
+
+// plannedBackupLocation 実行した場合のバックアップ先（バックアップ無効の場合は空）
+func (t *FM24Tool) plannedBackupLocation() (string, error) {
+	if !t.backupEnabled() {
+		return "", nil
+	}
+
+	root, err := t.backupRoot()
+	if err != nil {
+		return "", err
+	}
+
+	return snapshotLocation(root, t.snapshotID(time.Now().Format(backupTimestampFormat)), t.backupFormat())
+}
+
+// DryRun 実名化処理の実行計画を表示（ファイルは変更しない）
+func (t *FM24Tool) DryRun(customPath string) error {
+	if err := t.DetectInstallation(customPath); err != nil {
+		return err
+	}
+
+	var plans []*Plan
+	for _, version := range t.DBVersions {
+		t.useDBVersion(version)
+
+		location, err := t.plannedBackupLocation()
+		if err != nil {
+			return err
+		}
+
+		plan, err := t.buildPlan(location)
+		if err != nil {
+			return fmt.Errorf("実行計画の作成エラー: %w", err)
+		}
+		plans = append(plans, plan)
+	}
+
+	if t.Output == OutputJSON {
+		if t.multipleVersions() {
+			return printJSON(struct {
+				Versions []*Plan `json:"versions"`
+			}{plans})
+		}
+		return printJSON(plans[0])
+	}
+
+	for _, plan := range plans {
+		printPlanTable(plan)
+	}
+	return nil
+}
+
+// printPlanTable 実行計画を表形式で出力
+func printPlanTable(plan *Plan) {
+	color.Cyan("==========================================================")
+	color.Cyan("%s 実名化 実行計画（ドライラン）", strings.ToUpper(plan.Game))
+	color.Cyan("==========================================================\n")
+
+	fmt.Printf("データベース: %s (DB %s)\n", plan.DBBasePath, plan.DBVersion)
+	fmt.Printf("プロファイル: %s\n", plan.Profile)
+	if plan.BackupEnabled {
+		fmt.Printf("バックアップ先: %s (%s)\n", plan.BackupLocation, plan.BackupFormat)
+	} else {
+		color.Yellow("バックアップ: 無効（backup.enabled: false）")
+	}
+	fmt.Println()
+
+	if len(plan.Items) == 0 {
+		color.Green("✓ バックアップ・削除の対象はありません")
+	} else {
+		fmt.Println("🗑️  バックアップして削除するパス:")
+		fmt.Println()
+		for _, item := range plan.Items {
+			kind := "ファイル"
+			if item.IsDirectory {
+				kind = fmt.Sprintf("ディレクトリ (%d個のファイル)", item.Files)
+			}
+			fmt.Printf("  %s\n", item.Path)
+			fmt.Printf("      種別: %s / サイズ: %s\n", kind, formatSize(item.Size))
+			fmt.Printf("      ルール: %s\n", item.Rule)
+			if item.Backup != "" {
+				fmt.Printf("      バックアップ: %s\n", item.Backup)
+			}
+		}
+	}
+
+	if len(plan.Skipped) > 0 {
+		fmt.Println()
+		fmt.Println("⊘ 対象が見つからないルール:")
+		for _, skip := range plan.Skipped {
+			fmt.Printf("  %s: %s\n", skip.Rule, skip.Reason)
+		}
+	}
+
+	fmt.Println()
+	color.Cyan("==========================================================")
+	fmt.Printf("合計: %d個のパス / %d個のファイル / %s\n", len(plan.Items), plan.TotalFiles, formatSize(plan.TotalSize))
+	color.Yellow("ドライランのため、ファイルは変更されていません")
+	color.Cyan("==========================================================")
+}
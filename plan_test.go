@@ -0,0 +1,145 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestBuildPlanDedup(t *testing.T) {
+	const covered = "他のルールの対象に含まれます"
+
+	tests := []struct {
+		name        string
+		rules       []Rule
+		wantItems   map[string]string // パス → ルール
+		wantSkipped map[string]string // ルール → 理由（空の場合は一致しない理由）
+	}{
+		{
+			name: "ディレクトリとその中のグロブ",
+			rules: []Rule{
+				{Name: "files", Description: "files", Type: RuleGlob, Path: "lnc/all/*.lnc"},
+				{Name: "dir", Description: "dir", Type: RuleExact, Path: "lnc/all"},
+			},
+			wantItems:   map[string]string{"lnc/all": "dir"},
+			wantSkipped: map[string]string{"files": covered},
+		},
+		{
+			name: "同じパスを複数のルールで指定",
+			rules: []Rule{
+				{Name: "a", Description: "a", Type: RuleExact, Path: "dbc/permanent/license.dbc"},
+				{Name: "b", Description: "b", Type: RuleGlob, Path: "dbc/permanent/lic*.dbc"},
+				{Name: "c", Description: "c", Type: RuleRegex, Path: "dbc/permanent", Pattern: `^license\.dbc$`},
+			},
+			wantItems:   map[string]string{"dbc/permanent/license.dbc": "a"},
+			wantSkipped: map[string]string{"b": covered, "c": covered},
+		},
+		{
+			name: "入れ子の dir-contents",
+			rules: []Rule{
+				{Name: "sub", Description: "sub", Type: RuleDirContents, Path: "lnc/all/sub"},
+				{Name: "all", Description: "all", Type: RuleDirContents, Path: "lnc/all"},
+			},
+			wantItems: map[string]string{
+				"lnc/all/a.lnc": "all",
+				"lnc/all/sub":   "all",
+				"lnc/all/empty": "all",
+			},
+			wantSkipped: map[string]string{"sub": covered},
+		},
+		{
+			name: "一部のみ重複",
+			rules: []Rule{
+				{Name: "japan", Description: "japan", Type: RuleRegex, Path: "dbc/permanent", Pattern: "^(japan|keep)"},
+				{Name: "keep", Description: "keep", Type: RuleExact, Path: "dbc/permanent/keep.dbc"},
+				{Name: "missing", Description: "missing", Type: RuleExact, Path: "dbc/permanent/missing.dbc"},
+			},
+			wantItems: map[string]string{
+				"dbc/permanent/japan_clubs.dbc": "japan",
+				"dbc/permanent/keep.dbc":        "japan",
+			},
+			wantSkipped: map[string]string{"keep": covered, "missing": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := newTestTool(t)
+			tool.Rules = tt.rules
+
+			plan, err := tool.buildPlan("")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			items := make(map[string]string)
+			for _, item := range plan.Items {
+				if _, ok := items[item.Path]; ok {
+					t.Errorf("%s が重複しています", item.Path)
+				}
+				items[item.Path] = item.Rule
+			}
+			if !maps.Equal(items, tt.wantItems) {
+				t.Errorf("Items = %v, want %v", items, tt.wantItems)
+			}
+
+			if len(plan.Skipped) != len(tt.wantSkipped) {
+				t.Errorf("Skipped = %+v, want %v", plan.Skipped, tt.wantSkipped)
+			}
+			for _, skip := range plan.Skipped {
+				want, ok := tt.wantSkipped[skip.Rule]
+				switch {
+				case !ok:
+					t.Errorf("%s がスキップされました: %s", skip.Rule, skip.Reason)
+				case want == "" && (skip.Reason == "" || skip.Reason == covered):
+					t.Errorf("%s: 一致しない理由 = %q", skip.Rule, skip.Reason)
+				case want != "" && skip.Reason != want:
+					t.Errorf("%s: 理由 = %q, want %q", skip.Rule, skip.Reason, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildPlanTotals(t *testing.T) {
+	tool := newTestTool(t)
+
+	plan, err := tool.buildPlan("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	var size int64
+	var files int
+	for _, item := range plan.Items {
+		paths = append(paths, item.Path)
+		size += item.Size
+		files += item.Files
+		if item.Backup != "" {
+			t.Errorf("%s: バックアップ無効でもバックアップ先があります", item.Path)
+		}
+	}
+	slices.Sort(paths)
+
+	// 組み込みルール（all プロファイル）では keep.dbc 以外がすべて対象
+	want := []string{
+		"dbc/permanent/forbidden names.dbc",
+		"dbc/permanent/japan_clubs.dbc",
+		"dbc/permanent/license.dbc",
+		"edt/permanent/fake.edt",
+		"language/Licensing2.dbc",
+		"lnc/all/a.lnc",
+		"lnc/all/empty",
+		"lnc/all/sub",
+	}
+	if !slices.Equal(paths, want) {
+		t.Errorf("Items = %q, want %q", paths, want)
+	}
+	if plan.TotalSize != size || plan.TotalFiles != files || files != 7 {
+		t.Errorf("合計 = %d bytes / %d files, want %d / %d (7 files)", plan.TotalSize, plan.TotalFiles, size, files)
+	}
+	if plan.DBVersion != "2400" || plan.Profile != defaultProfileName || plan.BackupEnabled {
+		t.Errorf("plan = %+v", plan)
+	}
+}
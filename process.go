@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// gameExitPollInterval --wait-for-exit でゲームの終了を確認する間隔
+const gameExitPollInterval = 2 * time.Second
+
+// processInfo 実行中のプロセス
+type processInfo struct {
+	PID   int
+	Paths []string // 実行ファイルのパス（Linux は /proc/<pid>/exe と cmdline の先頭、パスが分からない場合はプロセス名）
+}
+
+// listProcFS /proc から各プロセスの実行ファイルとコマンドラインの先頭を取得
+//
+// Wine / Proton で実行中のゲームは exe が wine(64)-preloader になるため、
+// cmdline の先頭（Z:\...\fm.exe 等の Windows パス）も確認する。
+func listProcFS(root string) ([]processInfo, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("プロセス一覧取得エラー: %w", err)
+	}
+
+	var processes []processInfo
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+
+		// 他のユーザーのプロセス等、読めないものは無視
+		process := processInfo{PID: pid}
+		if exe, err := os.Readlink(filepath.Join(root, entry.Name(), "exe")); err == nil {
+			process.Paths = append(process.Paths, exe)
+		}
+		if cmdline, err := os.ReadFile(filepath.Join(root, entry.Name(), "cmdline")); err == nil {
+			if argv0, _, _ := bytes.Cut(cmdline, []byte{0}); len(argv0) > 0 {
+				process.Paths = append(process.Paths, string(argv0))
+			}
+		}
+		if len(process.Paths) > 0 {
+			processes = append(processes, process)
+		}
+	}
+	return processes, nil
+}
+
+// listPS ps から実行ファイルのパスを取得（macOS の comm は実行ファイルのフルパス）
+func listPS() ([]processInfo, error) {
+	output, err := exec.Command("ps", "-A", "-o", "pid=", "-o", "comm=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("ps 実行エラー: %w", err)
+	}
+
+	var processes []processInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if len(fields) < 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		processes = append(processes, processInfo{PID: pid, Paths: []string{strings.TrimSpace(fields[1])}})
+	}
+	return processes, nil
+}
+
+// installRoot 検出したDBフォルダを含むゲームのインストールフォルダ（配置が分からない場合は空）
+func (t *FM24Tool) installRoot() string {
+	for _, layout := range t.Game.DBLayouts {
+		suffix := string(filepath.Separator) + filepath.FromSlash(layout)
+		if strings.HasSuffix(t.DBRoot, suffix) {
+			return strings.TrimSuffix(t.DBRoot, suffix)
+		}
+	}
+	return ""
+}
+
+// normalizeProcessPath パスを比較用に揃える（区切り文字を / に、小文字に）
+func normalizeProcessPath(path string) string {
+	return strings.ToLower(strings.ReplaceAll(path, `\`, "/"))
+}
+
+// isGameProcess 実行ファイルのパスがゲームのものか
+//
+// 実行ファイル名が一致し、検出したインストールフォルダ（installRoot）またはエディションの
+// インストールフォルダ名（Football Manager 2024 等）の中にある場合にゲームとみなす。
+// 同名の無関係なプロセス（fm 等）と区別できないため、パスの分からないプロセス名のみでは判定しない。
+func (g *GameProfile) isGameProcess(path, installRoot string) bool {
+	normalized := normalizeProcessPath(path)
+	slash := strings.LastIndex(normalized, "/")
+	if slash < 0 {
+		return false
+	}
+
+	name := normalized[slash+1:]
+	matched := false
+	for _, executable := range g.Executables {
+		if name == strings.ToLower(executable) {
+			matched = true
+		}
+	}
+	if !matched {
+		return false
+	}
+
+	if installRoot != "" {
+		root := strings.TrimSuffix(normalizeProcessPath(installRoot), "/") + "/"
+		// Wine / Proton ではホストのルートが Z: ドライブになる
+		if strings.HasPrefix(normalized, root) || strings.HasPrefix(normalized, "z:"+root) {
+			return true
+		}
+	}
+	for _, dir := range g.InstallDirs {
+		if strings.Contains(normalized, "/"+strings.ToLower(dir)+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// findGameProcesses 実行中のゲームのプロセス
+func findGameProcesses(game *GameProfile, installRoot string) ([]processInfo, error) {
+	processes, err := listProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	var found []processInfo
+	for _, process := range processes {
+		for _, path := range process.Paths {
+			if game.isGameProcess(path, installRoot) {
+				found = append(found, processInfo{PID: process.PID, Paths: []string{path}})
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+// describeProcesses プロセスの表示（PID とパス）
+func describeProcesses(processes []processInfo) string {
+	var parts []string
+	for _, process := range processes {
+		parts = append(parts, fmt.Sprintf("PID %d: %s", process.PID, process.Paths[0]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ensureGameNotRunning ゲームの実行中はファイルを変更しない（WaitForExit の場合は終了まで待つ）
+//
+// 読み込まれているライセンスファイルを削除・復元するとゲーム内の表示がおかしくなるため、
+// 削除・復元の前に確認する。プロセス一覧を取得できない場合は、--yes の場合のみ警告を表示して続行する。
+func (t *FM24Tool) ensureGameNotRunning() error {
+	installRoot := t.installRoot()
+	processes, err := findGameProcesses(t.Game, installRoot)
+	if err != nil {
+		if !t.AssumeYes {
+			return fmt.Errorf("%s が実行中か確認できません: %w（確認せずに続行するには --yes を指定してください）", t.Game.Short, err)
+		}
+		color.Yellow("⚠️  %s が実行中か確認できません。確認せずに続行します (--yes): %v", t.Game.Short, err)
+		return nil
+	}
+	if len(processes) == 0 {
+		return nil
+	}
+
+	if !t.WaitForExit {
+		return fmt.Errorf("%s が実行中です（%s）。ゲームを終了してから実行するか、--wait-for-exit で終了を待ってください",
+			t.Game.Name, describeProcesses(processes))
+	}
+
+	color.Yellow("⏳ %s の終了を待っています（%s）...", t.Game.Name, describeProcesses(processes))
+	for len(processes) > 0 {
+		time.Sleep(gameExitPollInterval)
+		if processes, err = findGameProcesses(t.Game, installRoot); err != nil {
+			return err
+		}
+	}
+	color.Green("✓ %s が終了しました\n", t.Game.Name)
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsGameProcess(t *testing.T) {
+	fm24, err := findGame("fm24")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		installRoot string
+		want        bool
+	}{
+		{"Proton の cmdline", `Z:\home\deck\.local\share\Steam\steamapps\common\Football Manager 2024\fm.exe`, "", true},
+		{"Windows Steam", `C:\Program Files (x86)\Steam\steamapps\common\Football Manager 2024\fm.exe`, "", true},
+		{"Epic", `D:\Epic Games\FootballManager2024\fm.exe`, "", true},
+		{"macOS", "/Users/user/Library/Application Support/Steam/steamapps/common/Football Manager 2024/fm.app/Contents/MacOS/fm", "", true},
+		{"インストールフォルダ（名前を変更）", "/games/fm-custom/fm.exe", "/games/fm-custom", true},
+		{"インストールフォルダ（Z: ドライブ）", `Z:\games\fm-custom\fm.exe`, "/games/fm-custom", true},
+		{"FM23", `Z:\home\deck\.local\share\Steam\steamapps\common\Football Manager 2023\fm.exe`, "", false},
+		{"無関係な fm", "/usr/bin/fm", "", false},
+		{"無関係な fm（ホーム）", "/home/deck/go/bin/fm", "/home/deck/.local/share/Steam/steamapps/common/Football Manager 2024", false},
+		{"プロセス名のみ", "fm", "", false},
+		{"プロセス名のみ（Windows）", "fm.exe", "", false},
+		{"別の実行ファイル", `Z:\home\deck\.local\share\Steam\steamapps\common\Football Manager 2024\crash_reporter.exe`, "", false},
+		{"前方一致のみのフォルダ", "/games/fm-custom-old/fm.exe", "/games/fm-custom", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fm24.isGameProcess(tt.path, tt.installRoot); got != tt.want {
+				t.Errorf("isGameProcess(%q, %q) = %v, want %v", tt.path, tt.installRoot, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallRoot(t *testing.T) {
+	tool := newTestTool(t)
+	if got := tool.installRoot(); got != "" {
+		t.Errorf("installRoot() = %q, want 空（配置が不明）", got)
+	}
+
+	install := filepath.Join(t.TempDir(), "Football Manager 2024")
+	tool.DBRoot = filepath.Join(install, "data", "database", "db")
+	if got := tool.installRoot(); got != install {
+		t.Errorf("installRoot() = %q, want %q", got, install)
+	}
+}
+
+func TestListProcFS(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"100/cmdline":  "Z:\\games\\Football Manager 2024\\fm.exe\x00-windowed\x00",
+		"200/cmdline":  "",
+		"self/cmdline": "/bin/true\x00",
+	})
+	if err := os.Symlink("/usr/bin/wine64-preloader", filepath.Join(root, "100", "exe")); err != nil {
+		t.Skip(err)
+	}
+
+	processes, err := listProcFS(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(processes) != 1 || processes[0].PID != 100 {
+		t.Fatalf("listProcFS() = %+v", processes)
+	}
+	want := []string{"/usr/bin/wine64-preloader", `Z:\games\Football Manager 2024\fm.exe`}
+	if len(processes[0].Paths) != 2 || processes[0].Paths[0] != want[0] || processes[0].Paths[1] != want[1] {
+		t.Errorf("Paths = %q, want %q", processes[0].Paths, want)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+import "runtime"
+
+// listProcesses 実行中のプロセスの一覧（Linux は /proc、その他は ps）
+func listProcesses() ([]processInfo, error) {
+	if runtime.GOOS == "linux" {
+		return listProcFS("/proc")
+	}
+	return listPS()
+}
@@ -0,0 +1,63 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// listProcesses 実行中のプロセスの一覧（Toolhelp でプロセスを列挙し、実行ファイルのフルパスを取得）
+func listProcesses() ([]processInfo, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, fmt.Errorf("プロセス一覧取得エラー: %w", err)
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	if err := windows.Process32First(snapshot, &entry); err != nil {
+		return nil, fmt.Errorf("プロセス一覧取得エラー: %w", err)
+	}
+
+	var processes []processInfo
+	for {
+		if pid := int(entry.ProcessID); pid != 0 {
+			// 権限がなくパスを取得できないプロセスはプロセス名のみ
+			path := processImagePath(entry.ProcessID)
+			if path == "" {
+				path = windows.UTF16ToString(entry.ExeFile[:])
+			}
+			processes = append(processes, processInfo{PID: pid, Paths: []string{path}})
+		}
+
+		err := windows.Process32Next(snapshot, &entry)
+		if errors.Is(err, windows.ERROR_NO_MORE_FILES) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("プロセス一覧取得エラー: %w", err)
+		}
+	}
+	return processes, nil
+}
+
+// processImagePath プロセスの実行ファイルのフルパス（取得できない場合は空）
+func processImagePath(pid uint32) string {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(handle)
+
+	buffer := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buffer))
+	if err := windows.QueryFullProcessImageName(handle, 0, &buffer[0], &size); err != nil {
+		return ""
+	}
+	return windows.UTF16ToString(buffer[:size])
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultProfileName プロファイル未指定時に使用するプロファイル
+const defaultProfileName = "all"
+
+// Profile カテゴリ単位で削除対象ルールを選択するプロファイル
+type Profile struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Categories  []string `yaml:"categories,omitempty" json:"categories,omitempty"` // 対象カテゴリ（省略時は全カテゴリ）
+	Exclude     []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`       // 除外カテゴリ
+}
+
+// defaultProfiles 組み込みプロファイル
+func defaultProfiles() []Profile {
+	return []Profile{
+		{Name: "all", Description: "全ての削除対象ルール"},
+		{Name: "japan-only", Description: "日本関連（Jリーグ）のみ", Categories: []string{"japan"}},
+		{Name: "everything-except-greece", Description: "ギリシャ（lnc/greek）以外の全て", Exclude: []string{"greece"}},
+		{Name: "licensing-only", Description: "全体のライセンスファイルのみ", Categories: []string{"licensing"}},
+	}
+}
+
+// TargetProfiles 組み込みプロファイルと設定ファイルのプロファイル（同じ名前は置き換え）
+func (c *Config) TargetProfiles() []Profile {
+	profiles := defaultProfiles()
+
+	index := make(map[string]int, len(profiles))
+	for i, profile := range profiles {
+		index[profile.Name] = i
+	}
+
+	for _, profile := range c.Profiles {
+		if i, ok := index[profile.Name]; ok {
+			profiles[i] = profile
+			continue
+		}
+		index[profile.Name] = len(profiles)
+		profiles = append(profiles, profile)
+	}
+
+	return profiles
+}
+
+// findProfile 名前からプロファイルを検索
+func (c *Config) findProfile(name string) (*Profile, error) {
+	profiles := c.TargetProfiles()
+	names := make([]string, 0, len(profiles))
+	for i := range profiles {
+		if profiles[i].Name == name {
+			return &profiles[i], nil
+		}
+		names = append(names, profiles[i].Name)
+	}
+
+	return nil, fmt.Errorf("不明なプロファイル: %s（%s）", name, strings.Join(names, ", "))
+}
+
+// validateProfiles プロファイル定義とインストールパスのプロファイル指定を検証
+func (c *Config) validateProfiles(game *GameProfile, packs []*RulePack) error {
+	// 無効化されたルールのカテゴリも指定できるようにする
+	categories := make(map[string]bool)
+	for _, rule := range c.mergedRules(game, packs) {
+		if rule.Category == "" {
+			rule.Category = ruleCategoryCustom
+		}
+		categories[rule.Category] = true
+	}
+
+	for i, profile := range c.Profiles {
+		if profile.Name == "" {
+			return fmt.Errorf("profiles[%d]: name を指定してください", i)
+		}
+		for _, category := range append(append([]string{}, profile.Categories...), profile.Exclude...) {
+			if !categories[category] {
+				return fmt.Errorf("プロファイル %q: 不明なカテゴリ: %s（%s）", profile.Name, category, strings.Join(sortedKeys(categories), ", "))
+			}
+		}
+	}
+
+	for _, installPath := range c.InstallPaths {
+		if installPath.Profile == "" {
+			continue
+		}
+		if _, err := c.findProfile(installPath.Profile); err != nil {
+			return fmt.Errorf("インストールパス %q: %w", installPath.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// includes カテゴリがプロファイルの対象か判定
+func (p *Profile) includes(category string) bool {
+	for _, excluded := range p.Exclude {
+		if excluded == category {
+			return false
+		}
+	}
+
+	if len(p.Categories) == 0 {
+		return true
+	}
+	for _, included := range p.Categories {
+		if included == category {
+			return true
+		}
+	}
+	return false
+}
+
+// selectProfile 使用するプロファイルを決定（--profile > インストールパスの profile > all）
+func (t *FM24Tool) selectProfile() (*Profile, error) {
+	name := t.Profile
+	if name == "" && t.Install != nil && t.Install.Name != "" {
+		for _, installPath := range t.Config.InstallPaths {
+			if installPath.Name == t.Install.Name {
+				name = installPath.Profile
+				break
+			}
+		}
+	}
+	if name == "" {
+		name = defaultProfileName
+	}
+
+	return t.Config.findProfile(name)
+}
+
+// profileRules プロファイルと検出したDBバージョンの対象となるルール
+func (t *FM24Tool) profileRules(profile *Profile) []Rule {
+	dbVersion := filepath.Base(t.DBBasePath)
+
+	var rules []Rule
+	for _, rule := range t.Rules {
+		if profile.includes(rule.Category) && rule.appliesTo(dbVersion) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// sortedKeys マップのキーをソートして返す
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
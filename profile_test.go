@@ -0,0 +1,165 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProfileIncludes(t *testing.T) {
+	tests := []struct {
+		profile  Profile
+		category string
+		want     bool
+	}{
+		{Profile{Name: "all"}, "licensing", true},
+		{Profile{Name: "all"}, ruleCategoryCustom, true},
+		{Profile{Categories: []string{"japan"}}, "japan", true},
+		{Profile{Categories: []string{"japan"}}, "licensing", false},
+		{Profile{Exclude: []string{"greece"}}, "greece", false},
+		{Profile{Exclude: []string{"greece"}}, "japan", true},
+		// 除外が優先
+		{Profile{Categories: []string{"japan", "greece"}, Exclude: []string{"greece"}}, "greece", false},
+		{Profile{Categories: []string{"japan", "greece"}, Exclude: []string{"greece"}}, "japan", true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.profile.includes(tt.category); got != tt.want {
+			t.Errorf("%+v.includes(%q) = %v, want %v", tt.profile, tt.category, got, tt.want)
+		}
+	}
+}
+
+func TestSelectProfile(t *testing.T) {
+	config := &Config{
+		Profiles: []Profile{
+			{Name: "mine", Categories: []string{"japan"}},
+			{Name: "all", Exclude: []string{"language"}}, // 組み込みプロファイルの置き換え
+		},
+		InstallPaths: []InstallPath{
+			{Name: "steam", Path: "/games/steam"},
+			{Name: "epic", Path: "/games/epic", Profile: "licensing-only"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		flag        string
+		install     *InstallInfo
+		wantProfile string
+		wantExclude []string
+		wantErr     string
+	}{
+		{name: "未指定", wantProfile: "all", wantExclude: []string{"language"}},
+		{name: "インストールの profile なし", install: &InstallInfo{Name: "steam"}, wantProfile: "all", wantExclude: []string{"language"}},
+		{name: "インストールの profile", install: &InstallInfo{Name: "epic"}, wantProfile: "licensing-only"},
+		{name: "--profile が優先", flag: "mine", install: &InstallInfo{Name: "epic"}, wantProfile: "mine"},
+		{name: "設定ファイルにないインストール", install: &InstallInfo{Path: "/scan/fm24"}, wantProfile: "all", wantExclude: []string{"language"}},
+		{name: "不明なプロファイル", flag: "nope", wantErr: "不明なプロファイル: nope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &FM24Tool{Config: config, Profile: tt.flag, Install: tt.install}
+			profile, err := tool.selectProfile()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("selectProfile() = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if profile.Name != tt.wantProfile || !slices.Equal(profile.Exclude, tt.wantExclude) {
+				t.Errorf("selectProfile() = %+v, want %s (exclude %v)", profile, tt.wantProfile, tt.wantExclude)
+			}
+		})
+	}
+}
+
+func TestProfileRules(t *testing.T) {
+	tool := newTestTool(t)
+	tool.Rules = append(tool.Rules,
+		Rule{Name: "fm2430", Type: RuleExact, Path: "dbc/permanent/x.dbc", Category: "japan", DBVersions: []string{"2430"}},
+		Rule{Name: "fm2400", Type: RuleExact, Path: "dbc/permanent/y.dbc", Category: "japan", DBVersions: []string{"2400"}},
+	)
+
+	profiles := defaultProfiles()
+	tests := []struct {
+		profile Profile
+		want    []string
+	}{
+		{profiles[1], []string{"j-league-non-player", "japan-removed-clubs", "japan-files", "fm2400"}},
+		{profiles[3], []string{"lnc-all", "fake-edt", "forbidden-names", "license"}},
+		{profiles[2], []string{
+			"lnc-all", "fake-edt", "brazil-kits", "forbidden-names", "license",
+			"j-league-non-player", "japan-removed-clubs", "japan-files", "licensing2", "licensing2-chn", "fm2400",
+		}},
+	}
+
+	for _, tt := range tests {
+		var names []string
+		for _, rule := range tool.profileRules(&tt.profile) {
+			names = append(names, rule.Name)
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("%s: profileRules() = %q, want %q", tt.profile.Name, names, tt.want)
+		}
+	}
+}
+
+func TestValidateProfiles(t *testing.T) {
+	game, err := findGame("fm24")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{
+			name: "有効",
+			config: Config{
+				Rules:        []Rule{{Name: "mine", Type: RuleExact, Path: "dbc/x.dbc"}, {Name: "lnc-greek", Disabled: true}},
+				Profiles:     []Profile{{Name: "p", Categories: []string{"japan", ruleCategoryCustom}, Exclude: []string{"greece"}}},
+				InstallPaths: []InstallPath{{Name: "steam", Profile: "p"}, {Name: "epic", Profile: "japan-only"}},
+			},
+		},
+		{
+			name:    "name なし",
+			config:  Config{Profiles: []Profile{{Categories: []string{"japan"}}}},
+			wantErr: "profiles[0]: name を指定してください",
+		},
+		{
+			name:    "不明なカテゴリ",
+			config:  Config{Profiles: []Profile{{Name: "p", Categories: []string{"jpan"}}}},
+			wantErr: `プロファイル "p": 不明なカテゴリ: jpan`,
+		},
+		{
+			name:    "不明な除外カテゴリ",
+			config:  Config{Profiles: []Profile{{Name: "p", Exclude: []string{"greek"}}}},
+			wantErr: `プロファイル "p": 不明なカテゴリ: greek`,
+		},
+		{
+			name:    "インストールの不明なプロファイル",
+			config:  Config{InstallPaths: []InstallPath{{Name: "steam", Profile: "nope"}}},
+			wantErr: `インストールパス "steam": 不明なプロファイル: nope`,
+		},
+	}
+
+	for _, tt := range tests {
+		err := tt.config.validateProfiles(game, nil)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: validateProfiles() = %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: validateProfiles() = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/fatih/color"
+)
+
+// 対象ファイルの状態
+const (
+	TargetPresent = "present" // ファイルが存在
+	TargetAbsent  = "absent"  // ファイル・ディレクトリなし
+	TargetEmpty   = "empty"   // ディレクトリは存在するが空
+)
+
+// InstallInfo 検出したインストール情報
+type InstallInfo struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Path        string `json:"path"`
+	Source      string `json:"source"`             // custom, config, scan
+	Store       string `json:"store,omitempty"`    // steam, epic
+	BuildID     string `json:"build_id,omitempty"` // Steam のビルドID（appmanifest の buildid）
+	Version     string `json:"version,omitempty"`  // Epic のアプリバージョン（.item の AppVersionString）
+	Prefix      string `json:"prefix,omitempty"`   // Wine プレフィックス内のインストールの場合のプレフィックス
+}
+
+// TargetStatus 対象ファイルの状態
+type TargetStatus struct {
+	Rule        string   `json:"rule"`
+	Type        string   `json:"type"`
+	Path        string   `json:"path"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Optional    bool     `json:"optional"`
+	State       string   `json:"state"`
+	Files       int      `json:"files"`
+	Matches     []string `json:"matches"`
+}
+
+// StatusReport 実名化状態チェックの結果
+type StatusReport struct {
+	Install    *InstallInfo   `json:"install"`
+	Game       string         `json:"game"`
+	DBBasePath string         `json:"db_base_path"`
+	DBVersion  string         `json:"db_version"`
+	Profile    string         `json:"profile"`
+	Targets    []TargetStatus `json:"targets"`
+	Present    int            `json:"present"`
+	Removed    int            `json:"removed"`
+	Absent     int            `json:"absent"` // このインストールに存在しない任意の対象
+	Verdict    string         `json:"verdict"`
+	Status     ApplyStatus    `json:"-"`
+}
+
+// TargetResult 対象ごとの処理結果
+type TargetResult struct {
+	Rule    string `json:"rule"`
+	Result  string `json:"result"` // deleted, not_found
+	Deleted int    `json:"deleted"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// ApplyReport 実名化処理の結果
+type ApplyReport struct {
+	Install        *InstallInfo   `json:"install"`
+	Game           string         `json:"game"`
+	DBBasePath     string         `json:"db_base_path"`
+	DBVersion      string         `json:"db_version"`
+	Profile        string         `json:"profile"`
+	BackupEnabled  bool           `json:"backup_enabled"`
+	BackupFormat   string         `json:"backup_format,omitempty"`
+	BackupLocation string         `json:"backup_location,omitempty"`
+	Targets        []TargetResult `json:"targets"`
+	Items          []PlanItem     `json:"items"`
+	Total          int            `json:"total"`
+	Deleted        int            `json:"deleted"`
+}
+
+// VersionsStatusReport 複数のDBバージョンの実名化状態
+type VersionsStatusReport struct {
+	Install  *InstallInfo    `json:"install"`
+	Game     string          `json:"game"`
+	DBRoot   string          `json:"db_root"`
+	Profile  string          `json:"profile"`
+	Versions []*StatusReport `json:"versions"`
+	Verdict  string          `json:"verdict"`
+	Status   ApplyStatus     `json:"-"`
+}
+
+// VersionsApplyReport 複数のDBバージョンの実名化処理の結果
+type VersionsApplyReport struct {
+	Versions []*ApplyReport `json:"versions"`
+}
+
+// String 適用状態の文字列表現（JSON出力用）
+func (s ApplyStatus) String() string {
+	switch s {
+	case StatusApplied:
+		return "applied"
+	case StatusPartial:
+		return "partial"
+	default:
+		return "not_applied"
+	}
+}
+
+// inspectStatus 検出済みのインストールの実名化状態を調べる
+func (t *FM24Tool) inspectStatus() (*StatusReport, error) {
+	profile, err := t.selectProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StatusReport{
+		Install:    t.Install,
+		Game:       t.Game.ID,
+		DBBasePath: t.DBBasePath,
+		DBVersion:  filepath.Base(t.DBBasePath),
+		Profile:    profile.Name,
+		Targets:    []TargetStatus{},
+	}
+
+	for _, rule := range t.profileRules(profile) {
+		match, err := rule.match(t.DBBasePath)
+		if err != nil {
+			return nil, fmt.Errorf("ルール %s: %w", rule.Name, err)
+		}
+
+		status := TargetStatus{
+			Rule:        rule.Name,
+			Type:        rule.Type,
+			Path:        rule.Path,
+			Description: rule.Description,
+			Category:    rule.Category,
+			Optional:    rule.isOptional(),
+			State:       match.State,
+			Files:       len(match.Paths),
+			Matches:     []string{},
+		}
+		for _, path := range match.Paths {
+			if relPath, err := filepath.Rel(t.DBBasePath, path); err == nil {
+				status.Matches = append(status.Matches, filepath.ToSlash(relPath))
+			}
+		}
+
+		// 任意の対象が存在しない場合は、削除済みかもともとないのか区別できないため判定に含めない
+		switch {
+		case status.State == TargetPresent:
+			report.Present++
+		case status.State == TargetAbsent && status.Optional:
+			report.Absent++
+		default:
+			report.Removed++
+		}
+		report.Targets = append(report.Targets, status)
+	}
+
+	switch {
+	case report.Present == 0:
+		report.Status = StatusApplied
+	case report.Removed == 0:
+		report.Status = StatusNotApplied
+	default:
+		report.Status = StatusPartial
+	}
+	report.Verdict = report.Status.String()
+
+	return report, nil
+}
+
+// printStatus 実名化状態を表示
+func printStatus(report *StatusReport) {
+	logf("\n📋 ライセンスファイル状態（プロファイル: %s）:\n", report.Profile)
+	logln()
+
+	for _, target := range report.Targets {
+		switch {
+		case target.State == TargetPresent && target.Type == RuleExact:
+			color.Yellow("  ⊘ %s (存在)", target.Description)
+		case target.State == TargetPresent:
+			color.Yellow("  ⊘ %s (%d個のファイル存在)", target.Description, target.Files)
+		case target.State == TargetEmpty:
+			color.Green("  ✓ %s (空)", target.Description)
+		case target.Optional:
+			color.White("  - %s (なし)", target.Description)
+		case target.Type == RuleDirContents:
+			color.Green("  ✓ %s (ディレクトリなし)", target.Description)
+		default:
+			color.Green("  ✓ %s (削除済み)", target.Description)
+		}
+	}
+
+	// 結果サマリー
+	logln()
+	color.Cyan("==========================================================")
+	logf("ライセンスファイル: %d個存在 / %d個削除済み / %d個なし（任意）\n", report.Present, report.Removed, report.Absent)
+
+	switch report.Status {
+	case StatusApplied:
+		color.Green("\n✅ 実名化が適用されています")
+	case StatusNotApplied:
+		color.Yellow("\n⚠️  実名化は未適用です")
+		color.White("実名化を適用するには: fm24-real apply")
+	default:
+		color.Yellow("\n⚠️  実名化は一部のみ適用されています")
+		color.White("実名化を再適用するには: fm24-real update")
+	}
+	color.Cyan("==========================================================")
+}
+
+// newApplyReport 実行した計画から処理結果を作成
+func (t *FM24Tool) newApplyReport(plan *Plan) *ApplyReport {
+	report := &ApplyReport{
+		Install:        t.Install,
+		Game:           t.Game.ID,
+		DBBasePath:     plan.DBBasePath,
+		DBVersion:      plan.DBVersion,
+		Profile:        plan.Profile,
+		BackupEnabled:  plan.BackupEnabled,
+		BackupFormat:   plan.BackupFormat,
+		BackupLocation: plan.BackupLocation,
+		Targets:        []TargetResult{},
+		Items:          plan.Items,
+		Total:          len(plan.Items),
+		Deleted:        len(plan.Items),
+	}
+
+	// ルールごとの削除件数（計画の順序を維持）
+	index := make(map[string]int)
+	for _, item := range plan.Items {
+		i, ok := index[item.Rule]
+		if !ok {
+			i = len(report.Targets)
+			index[item.Rule] = i
+			report.Targets = append(report.Targets, TargetResult{Rule: item.Rule, Result: "deleted"})
+		}
+		report.Targets[i].Deleted++
+	}
+	for _, skip := range plan.Skipped {
+		report.Targets = append(report.Targets, TargetResult{Rule: skip.Rule, Result: "not_found", Reason: skip.Reason})
+	}
+
+	return report
+}
+
+// newVersionsStatusReport バージョンごとの状態をまとめる（全て適用済みの場合のみ適用済み）
+func (t *FM24Tool) newVersionsStatusReport(reports []*StatusReport) *VersionsStatusReport {
+	summary := &VersionsStatusReport{
+		Install:  t.Install,
+		Game:     t.Game.ID,
+		DBRoot:   t.DBRoot,
+		Profile:  reports[0].Profile,
+		Versions: reports,
+		Status:   reports[0].Status,
+	}
+
+	for _, report := range reports[1:] {
+		if report.Status != summary.Status {
+			summary.Status = StatusPartial
+		}
+	}
+	summary.Verdict = summary.Status.String()
+
+	return summary
+}
+
+// printVersionsStatus バージョンごとの実名化状態を表形式で表示
+func printVersionsStatus(summary *VersionsStatusReport) {
+	logf("\n📋 DBバージョン別の状態（プロファイル: %s）:\n\n", summary.Profile)
+	logf("  %-8s  %6s  %8s  %6s  %s\n", "DB", "存在", "削除済み", "なし", "状態")
+
+	for _, report := range summary.Versions {
+		switch report.Status {
+		case StatusApplied:
+			color.Green("  %-8s  %6d  %8d  %6d  ✓ 適用済み", report.DBVersion, report.Present, report.Removed, report.Absent)
+		case StatusNotApplied:
+			color.Yellow("  %-8s  %6d  %8d  %6d  ⊘ 未適用", report.DBVersion, report.Present, report.Removed, report.Absent)
+		default:
+			color.Yellow("  %-8s  %6d  %8d  %6d  ⊘ 一部のみ適用", report.DBVersion, report.Present, report.Removed, report.Absent)
+		}
+	}
+
+	logln()
+	color.Cyan("==========================================================")
+	switch summary.Status {
+	case StatusApplied:
+		color.Green("✅ 全てのDBバージョンで実名化が適用されています")
+	case StatusNotApplied:
+		color.Yellow("⚠️  全てのDBバージョンで実名化は未適用です")
+		color.White("実名化を適用するには: fm24-real apply --all-db-versions")
+	default:
+		color.Yellow("⚠️  実名化が適用されていないDBバージョンがあります")
+		color.White("実名化を再適用するには: fm24-real update --all-db-versions")
+	}
+	color.Cyan("==========================================================")
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// RestoreResult 復元処理の結果
+type RestoreResult struct {
+	Restored  []string
+	Unchanged []string
+	Conflicts []string
+	Failed    []string
+}
+
+// Restore バックアップスナップショットから復元
+func (t *FM24Tool) Restore(customPath, snapshotID string, force bool) error {
+	color.Cyan("==========================================================")
+	color.Cyan("%s バックアップ復元", t.Game.Short)
+	color.Cyan("==========================================================\n")
+
+	// インストールパス検出
+	if err := t.DetectInstallation(customPath); err != nil {
+		return err
+	}
+
+	color.Green("✓ %sデータベース検出: %s\n", t.Game.Short, t.DBBasePath)
+
+	root, err := t.backupRoot()
+	if err != nil {
+		return err
+	}
+
+	// バックアップの選択から復元まで prune 等と排他する
+	release, err := t.lockForRestore()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	snapshots, err := t.gameSnapshots(root)
+	if err != nil {
+		return fmt.Errorf("バックアップ一覧取得エラー: %w", err)
+	}
+	if snapshotID == "" {
+		snapshots = t.installSnapshots(snapshots)
+	}
+	if len(snapshots) == 0 {
+		return notFoundf("このインストールの%sのバックアップが見つかりません: %s", t.Game.Short, root)
+	}
+
+	snapshot, err := t.selectSnapshot(snapshots, snapshotID)
+	if err != nil {
+		return err
+	}
+
+	if err := t.useSnapshotVersion(snapshot); err != nil {
+		return err
+	}
+
+	color.Cyan("📦 復元元: %s", snapshot.Path)
+	color.Cyan("📁 復元先: %s\n", t.DBBasePath)
+
+	if err := t.ensureGameNotRunning(); err != nil {
+		return err
+	}
+
+	// 確認
+	color.Yellow("\n⚠️  警告: バックアップからライセンスファイルを書き戻します")
+	if force {
+		color.Yellow("⚠️  --force が指定されているため、内容が異なるファイルも上書きします")
+	}
+	if !confirm("\n続行しますか? (y/n): ", t.AssumeYes) {
+		return errCancelled
+	}
+
+	result, err := t.restoreSnapshot(snapshot, force)
+	if err != nil {
+		return err
+	}
+
+	t.generateRestoreReport(snapshot, result, force)
+
+	switch {
+	case len(result.Failed) > 0:
+		return statusErrorf(ExitIOError, "%d個のファイルを復元できませんでした", len(result.Failed))
+	case len(result.Conflicts) > 0:
+		return statusErrorf(ExitPartial, "%d個のファイルは競合のため復元していません", len(result.Conflicts))
+	}
+	return nil
+}
+
+// selectSnapshot 復元するスナップショットを選択（IDが空の場合は対話的に選択）
+func (t *FM24Tool) selectSnapshot(snapshots []BackupSnapshot, snapshotID string) (*BackupSnapshot, error) {
+	if snapshotID != "" {
+		for i := range snapshots {
+			if snapshots[i].ID == snapshotID {
+				return &snapshots[i], nil
+			}
+		}
+		return nil, notFoundf("指定された%sのバックアップが見つかりません: %s", t.Game.Short, snapshotID)
+	}
+
+	// 検出したインストール・DBバージョンの最新スナップショットをデフォルトにする
+	latest := 0
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if t.isSnapshotOfInstall(&snapshots[i]) {
+			latest = i + 1
+			break
+		}
+	}
+
+	fmt.Println("📋 バックアップ一覧:")
+	fmt.Println()
+	for i, snapshot := range snapshots {
+		source := "マニフェストなし"
+		if snapshot.Manifest != nil {
+			source = fmt.Sprintf("DB %s, %d個のファイル, %s", snapshot.Manifest.DBVersion, len(snapshot.Manifest.Files), snapshot.Manifest.SourcePath)
+		}
+		fmt.Printf("  [%d] %s (%s) - %s\n", i+1, snapshot.ID, snapshot.CreatedAt.Format("2006-01-02 15:04:05"), source)
+	}
+
+	if latest == 0 {
+		color.Yellow("\n⚠️  このインストールの DB %s のバックアップはありません", strings.Join(t.DBVersions, ", "))
+		if t.AssumeYes {
+			return nil, notFoundf("復元できるバックアップがありません（--snapshot でIDを指定してください）")
+		}
+	}
+
+	if t.AssumeYes {
+		fmt.Printf("\n最新のバックアップ [%d] を使用します (--yes)\n", latest)
+		return &snapshots[latest-1], nil
+	}
+
+	var response string
+	if latest == 0 {
+		fmt.Print("\n復元するバックアップ番号を入力してください: ")
+	} else {
+		fmt.Printf("\n復元するバックアップ番号を入力してください (Enterで最新 [%d]): ", latest)
+	}
+	if _, err := fmt.Scanln(&response); errors.Is(err, io.EOF) {
+		fmt.Println()
+		color.Yellow("⚠️  入力がないため続行しません（確認せずに実行するには --yes を指定してください）")
+		return nil, errCancelled
+	}
+	response = strings.TrimSpace(response)
+	if response == "" {
+		if latest == 0 {
+			return nil, errCancelled
+		}
+		return &snapshots[latest-1], nil
+	}
+
+	index, err := strconv.Atoi(response)
+	if err != nil || index < 1 || index > len(snapshots) {
+		return nil, fmt.Errorf("無効な番号です: %s", response)
+	}
+
+	return &snapshots[index-1], nil
+}
+
+// installSnapshots 検出したインストールのスナップショット（マニフェストのない古いものを含む）
+func (t *FM24Tool) installSnapshots(snapshots []BackupSnapshot) []BackupSnapshot {
+	var filtered []BackupSnapshot
+	for _, snapshot := range snapshots {
+		if snapshot.Manifest == nil || filepath.Dir(snapshot.Manifest.SourcePath) == t.DBRoot {
+			filtered = append(filtered, snapshot)
+		}
+	}
+	return filtered
+}
+
+// isSnapshotOfInstall スナップショットが検出したインストールの処理対象のDBバージョンのものか
+func (t *FM24Tool) isSnapshotOfInstall(snapshot *BackupSnapshot) bool {
+	manifest := snapshot.Manifest
+	if manifest == nil || filepath.Dir(manifest.SourcePath) != t.DBRoot {
+		return false
+	}
+	for _, version := range t.DBVersions {
+		if manifest.DBVersion == version {
+			return true
+		}
+	}
+	return false
+}
+
+// useSnapshotVersion 復元先をスナップショットのDBバージョンフォルダにする
+//
+// 別のバージョンのフォルダにライセンスファイルを書き戻さないよう、
+// --db-version の指定とスナップショットのバージョンが異なる場合はエラーにする。
+// マニフェストのない古いバックアップは選択中のバージョンに復元する。
+func (t *FM24Tool) useSnapshotVersion(snapshot *BackupSnapshot) error {
+	if snapshot.Manifest == nil || snapshot.Manifest.DBVersion == "" {
+		color.Yellow("⚠️  マニフェストがないため DB %s に復元します", filepath.Base(t.DBBasePath))
+		return nil
+	}
+
+	version := snapshot.Manifest.DBVersion
+	if t.DBVersion != "" && t.DBVersion != version {
+		return fmt.Errorf("バックアップ %s は DB %s のものです（--db-version %s と異なります）", snapshot.ID, version, t.DBVersion)
+	}
+
+	versions, err := listVersionFolders(t.DBRoot)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, v := range versions {
+		if v == version {
+			found = true
+		}
+	}
+	if !found {
+		return notFoundf("バックアップ %s の復元先 DB %s が見つかりません: %s", snapshot.ID, version, t.DBRoot)
+	}
+
+	t.useDBVersion(version)
+	return nil
+}
+
+// restoreSnapshot スナップショット内の全ファイルを元の場所に書き戻す
+func (t *FM24Tool) restoreSnapshot(snapshot *BackupSnapshot, force bool) (*RestoreResult, error) {
+	color.Cyan("\n🔄 復元処理を開始します...\n")
+
+	reader, err := openSnapshot(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("バックアップ読み込みエラー: %w", err)
+	}
+	defer reader.Close()
+
+	entries, err := snapshotEntries(snapshot, reader)
+	if err != nil {
+		return nil, fmt.Errorf("バックアップ読み込みエラー: %w", err)
+	}
+
+	result := &RestoreResult{}
+	for _, entry := range entries {
+		if !isSafeSnapshotPath(entry.Path) {
+			color.Red("  ✗ %s: 復元先の外を指すパスのため復元しません", entry.Path)
+			result.Failed = append(result.Failed, entry.Path)
+			continue
+		}
+		dstPath := filepath.Join(t.DBBasePath, filepath.FromSlash(entry.Path))
+
+		data, err := reader.ReadFile(entry.Path)
+		if err != nil {
+			color.Yellow("  ⚠️  読み込み失敗: %s - %v", entry.Path, err)
+			result.Failed = append(result.Failed, entry.Path)
+			continue
+		}
+
+		// マニフェストがある場合はバックアップの破損をチェック
+		sum := sha256Hex(data)
+		if entry.SHA256 != "" && sum != entry.SHA256 {
+			color.Red("  ✗ %s: バックアップが破損しています（ハッシュ不一致）", entry.Path)
+			result.Failed = append(result.Failed, entry.Path)
+			continue
+		}
+
+		// 現在のファイルとの差分チェック
+		if current, err := os.ReadFile(dstPath); err == nil {
+			if bytes.Equal(current, data) {
+				result.Unchanged = append(result.Unchanged, entry.Path)
+				continue
+			}
+			if !force {
+				color.Yellow("  ⊘ %s: 現在のファイルと内容が異なります（競合）", entry.Path)
+				result.Conflicts = append(result.Conflicts, entry.Path)
+				continue
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+			color.Yellow("  ⚠️  復元失敗: %s - %v", entry.Path, err)
+			result.Failed = append(result.Failed, entry.Path)
+			continue
+		}
+
+		// 既存のファイル（--force）は書き込み途中の状態にならないよう置き換える
+		if err := writeFileAtomic(dstPath, data, entry.Mode.Perm()); err != nil {
+			color.Yellow("  ⚠️  復元失敗: %s - %v", entry.Path, err)
+			result.Failed = append(result.Failed, entry.Path)
+			continue
+		}
+
+		if !entry.ModTime.IsZero() {
+			os.Chtimes(dstPath, entry.ModTime, entry.ModTime)
+		}
+
+		color.Green("  ✓ %s: 復元完了", entry.Path)
+		result.Restored = append(result.Restored, entry.Path)
+	}
+
+	return result, nil
+}
+
+// snapshotEntries スナップショット内の復元対象ファイルを取得（マニフェストがない場合は全ファイル）
+func snapshotEntries(snapshot *BackupSnapshot, reader snapshotReader) ([]ManifestEntry, error) {
+	if snapshot.Manifest != nil {
+		return snapshot.Manifest.Files, nil
+	}
+
+	files, err := reader.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]ManifestEntry, 0, len(files))
+	for _, path := range files {
+		entries = append(entries, ManifestEntry{Path: path, Mode: 0644})
+	}
+
+	return entries, nil
+}
+
+// generateRestoreReport 復元結果レポートを生成
+func (t *FM24Tool) generateRestoreReport(snapshot *BackupSnapshot, result *RestoreResult, force bool) {
+	fmt.Println()
+	color.Cyan("==========================================================")
+	color.Cyan("📊 復元処理レポート")
+	color.Cyan("==========================================================")
+	fmt.Printf("復元元: %s\n", snapshot.Path)
+	fmt.Printf("復元先: %s\n", t.DBBasePath)
+	color.Green("復元成功: %d", len(result.Restored))
+	fmt.Printf("変更なし: %d\n", len(result.Unchanged))
+	color.Yellow("競合: %d", len(result.Conflicts))
+	color.Red("失敗: %d", len(result.Failed))
+	color.Cyan("==========================================================")
+
+	if len(result.Conflicts) > 0 {
+		color.Yellow("\n⚠️  以下のファイルは現在の内容とバックアップが異なるため復元していません:")
+		for _, path := range result.Conflicts {
+			fmt.Printf("    %s\n", path)
+		}
+		if !force {
+			color.White("    上書きするには 'fm24-real restore --force' を実行してください")
+		}
+	}
+
+	if len(result.Failed) == 0 {
+		color.Green("\n✅ 復元処理が完了しました")
+		color.Yellow("⚠️  ゲームを再起動して変更を反映してください")
+	}
+}
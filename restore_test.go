@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newRestoreTestTool バックアップ（license.dbc と a.lnc）を1件持つテスト用のツール
+func newRestoreTestTool(t *testing.T) (*FM24Tool, *BackupSnapshot) {
+	t.Helper()
+	tool := newTestTool(t)
+	tool.AssumeYes = true
+	tool.Config.Backup.Directory = t.TempDir()
+
+	writer, err := newSnapshotWriter(tool.Config.Backup.Directory, "20240118_143022", BackupFormatDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := tool.newBackupManifest()
+	for _, relPath := range []string{"dbc/permanent/license.dbc", "lnc/all/a.lnc"} {
+		srcPath := filepath.Join(tool.DBBasePath, filepath.FromSlash(relPath))
+		info, err := os.Stat(srcPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size, sum, err := writer.AddFile(relPath, srcPath, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest.add(ManifestEntry{Path: relPath, Size: size, Mode: info.Mode(), SHA256: sum})
+	}
+	if err := writer.Commit(manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	return tool, &BackupSnapshot{ID: "20240118_143022", Path: writer.Location(), Format: BackupFormatDir, Manifest: manifest}
+}
+
+func TestRestoreSnapshotForce(t *testing.T) {
+	tool, snapshot := newRestoreTestTool(t)
+	license := filepath.Join(tool.DBBasePath, "dbc", "permanent", "license.dbc")
+	if err := os.WriteFile(license, []byte("updated"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// --force なしでは競合として残す
+	result, err := tool.restoreSnapshot(snapshot, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Conflicts) != 1 || len(result.Unchanged) != 1 {
+		t.Errorf("restoreSnapshot(force=false) = %+v", result)
+	}
+	if data, _ := os.ReadFile(license); string(data) != "updated" {
+		t.Errorf("競合したファイルが上書きされました: %q", data)
+	}
+
+	result, err = tool.restoreSnapshot(snapshot, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Restored) != 1 || len(result.Failed) != 0 {
+		t.Errorf("restoreSnapshot(force=true) = %+v", result)
+	}
+	info, err := os.Stat(license)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(license); string(data) != "license" {
+		t.Errorf("上書き後の内容 = %q", data)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("上書き後のパーミッション = %s, want バックアップ時の -rw-r--r--", info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(license))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".partial") {
+			t.Errorf("一時ファイルが残っています: %s", entry.Name())
+		}
+	}
+}
+
+func TestRestoreLocksBeforeSelecting(t *testing.T) {
+	tool, snapshot := newRestoreTestTool(t)
+
+	// 別のプロセス（prune 等）がバックアップディレクトリを処理中
+	other := &FM24Tool{Config: tool.Config, Game: tool.Game}
+	release, err := other.lockBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	err = tool.Restore(tool.DBRoot, snapshot.ID, false)
+	if err == nil || !strings.Contains(err.Error(), "バックアップディレクトリ") {
+		t.Fatalf("Restore() = %v, want ロック中のエラー", err)
+	}
+
+	// バックアップが無効な設定でもロックする
+	tool.Config.Backup.Enabled = false
+	if err := tool.Restore(tool.DBRoot, snapshot.ID, false); err == nil || !strings.Contains(err.Error(), "バックアップディレクトリ") {
+		t.Errorf("バックアップが無効な設定での Restore() = %v, want ロック中のエラー", err)
+	}
+
+	release()
+	if err := tool.Restore(tool.DBRoot, snapshot.ID, false); err != nil {
+		t.Errorf("ロック解放後の Restore() = %v", err)
+	}
+}
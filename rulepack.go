@@ -0,0 +1,370 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// rulePackNamePattern ルールパック名（インポート先のファイル名に使用）
+var rulePackNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// RulePacksConfig ルールパック設定
+type RulePacksConfig struct {
+	Directory     string   `yaml:"directory,omitempty"`      // インポートしたルールパックの保存先
+	TrustedKeys   []string `yaml:"trusted_keys,omitempty"`   // 信頼する ed25519 公開鍵（base64）
+	AllowUnsigned bool     `yaml:"allow_unsigned,omitempty"` // 署名のないルールパックを許可（非推奨）
+}
+
+// RulePack 署名付きのルールパック
+type RulePack struct {
+	Name        string   `yaml:"name" json:"name"`
+	Version     int      `yaml:"version" json:"version"`
+	Game        string   `yaml:"game,omitempty" json:"game,omitempty"`               // 対象のゲーム（省略時は全エディション）
+	DBVersions  []string `yaml:"db_versions,omitempty" json:"db_versions,omitempty"` // 対象のDBバージョン（省略時は全バージョン）
+	Author      string   `yaml:"author,omitempty" json:"author,omitempty"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Rules       []Rule   `yaml:"rules" json:"rules"`
+	PublicKey   string   `yaml:"public_key,omitempty" json:"public_key,omitempty"`
+	Signature   string   `yaml:"signature,omitempty" json:"-"`
+
+	path string // 読み込んだファイル
+}
+
+// rulePacksDir ルールパックの保存先ディレクトリ
+func (c *Config) rulePacksDir() (string, error) {
+	if c.RulePacks.Directory == "" {
+		return filepath.Join(filepath.Dir(GetDefaultConfigPath()), "rules.d"), nil
+	}
+	return expandPath(c.RulePacks.Directory)
+}
+
+// parseRulePack ルールパックを読み込み、内容を検証
+func parseRulePack(path string) (*RulePack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var pack RulePack
+	if err := yaml.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("ルールパック解析エラー (%s): %w", path, err)
+	}
+	pack.path = path
+
+	if !rulePackNamePattern.MatchString(pack.Name) {
+		return nil, fmt.Errorf("ルールパック %s: name は英小文字・数字・._- で指定してください: %q", path, pack.Name)
+	}
+	if pack.Version <= 0 {
+		return nil, fmt.Errorf("ルールパック %s: version を1以上で指定してください", pack.Name)
+	}
+	if len(pack.Rules) == 0 {
+		return nil, fmt.Errorf("ルールパック %s: rules が空です", pack.Name)
+	}
+	for i, rule := range pack.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("ルールパック %s: rules[%d]: name を指定してください", pack.Name, i)
+		}
+	}
+
+	return &pack, nil
+}
+
+// signedPayload 署名対象のデータ（署名以外の全項目の正規化JSON）
+func (p *RulePack) signedPayload() ([]byte, error) {
+	payload := *p
+	payload.Rules = make([]Rule, len(p.Rules))
+	for i, rule := range p.Rules {
+		rule.Source = ""
+		payload.Rules[i] = rule
+	}
+	return json.Marshal(payload)
+}
+
+// verify ルールパックの署名を検証
+func (c *RulePacksConfig) verify(pack *RulePack) error {
+	if pack.Signature == "" && pack.PublicKey == "" {
+		if c.AllowUnsigned {
+			return nil
+		}
+		return fmt.Errorf("ルールパック %s は署名されていません", pack.Name)
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(pack.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("ルールパック %s: public_key が不正です", pack.Name)
+	}
+	signature, err := base64.StdEncoding.DecodeString(pack.Signature)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("ルールパック %s: signature が不正です", pack.Name)
+	}
+
+	trusted := false
+	for _, key := range c.TrustedKeys {
+		if key == pack.PublicKey {
+			trusted = true
+			break
+		}
+	}
+	if !trusted {
+		return fmt.Errorf("ルールパック %s: 信頼されていない鍵で署名されています（rule_packs.trusted_keys に追加してください）: %s", pack.Name, pack.PublicKey)
+	}
+
+	payload, err := pack.signedPayload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), payload, signature) {
+		return fmt.Errorf("ルールパック %s: 署名が一致しません（改ざんされている可能性があります）", pack.Name)
+	}
+
+	return nil
+}
+
+// rulePackFiles 保存先ディレクトリ内のルールパックファイル（名前順）
+func (c *Config) rulePackFiles() ([]string, error) {
+	dir, err := c.rulePacksDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && (strings.HasSuffix(entry.Name(), ".yaml") || strings.HasSuffix(entry.Name(), ".yml")) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// LoadRulePacks インポート済みのルールパックを読み込み、全ての署名を検証
+func (c *Config) LoadRulePacks() ([]*RulePack, error) {
+	files, err := c.rulePackFiles()
+	if err != nil {
+		return nil, fmt.Errorf("ルールパック読み込みエラー: %w", err)
+	}
+
+	var packs []*RulePack
+	for _, file := range files {
+		pack, err := parseRulePack(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.RulePacks.verify(pack); err != nil {
+			return nil, fmt.Errorf("%w（%s）", err, file)
+		}
+		packs = append(packs, pack)
+	}
+
+	return packs, nil
+}
+
+// ImportRulePack ルールパックを検証して保存先ディレクトリにコピー
+func ImportRulePack(config *Config, game *GameProfile, path string, force bool) error {
+	pack, err := parseRulePack(path)
+	if err != nil {
+		return err
+	}
+	if err := config.RulePacks.verify(pack); err != nil {
+		return err
+	}
+
+	// ルール定義の検証（対象ゲームの組み込みルールと合わせた結果）
+	if pack.Game != "" {
+		if game, err = findGame(pack.Game); err != nil {
+			return fmt.Errorf("ルールパック %s: %w", pack.Name, err)
+		}
+	}
+	if _, err := config.TargetRules(game, []*RulePack{pack}); err != nil {
+		return fmt.Errorf("ルールパック %s: %w", pack.Name, err)
+	}
+
+	dir, err := config.rulePacksDir()
+	if err != nil {
+		return err
+	}
+	dest := filepath.Join(dir, pack.Name+".yaml")
+
+	// 古いバージョンでの上書きは --force が必要
+	if existing, err := parseRulePack(dest); err == nil && existing.Version > pack.Version && !force {
+		return fmt.Errorf("ルールパック %s はバージョン %d がインポート済みです（バージョン %d で上書きするには --force を指定してください）", pack.Name, existing.Version, pack.Version)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("ディレクトリ作成エラー: %w", err)
+	}
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return fmt.Errorf("ルールパック保存エラー: %w", err)
+	}
+
+	color.Green("✅ ルールパックをインポートしました: %s v%d（%d個のルール）", pack.Name, pack.Version, len(pack.Rules))
+	logf("保存先: %s\n", dest)
+	return nil
+}
+
+// ListRules インポート済みのルールパックとゲームの有効なルールを表示
+func ListRules(config *Config, game *GameProfile) error {
+	files, err := config.rulePackFiles()
+	if err != nil {
+		return err
+	}
+
+	color.Cyan("==========================================================")
+	color.Cyan("%s 削除対象ルール", game.Short)
+	color.Cyan("==========================================================\n")
+
+	dir, _ := config.rulePacksDir()
+	logf("ルールパック: %s\n\n", dir)
+
+	var packs []*RulePack
+	if len(files) == 0 {
+		logln("  インポートされたルールパックはありません")
+	}
+	for _, file := range files {
+		pack, err := parseRulePack(file)
+		if err != nil {
+			color.Red("  ✗ %s: %v", filepath.Base(file), err)
+			continue
+		}
+		if err := config.RulePacks.verify(pack); err != nil {
+			color.Red("  ✗ %s v%d: %v", pack.Name, pack.Version, err)
+			continue
+		}
+
+		if pack.Game != "" && pack.Game != game.ID {
+			color.White("  - %s v%d（%s 用）", pack.Name, pack.Version, pack.Game)
+			continue
+		}
+
+		dbVersions := "全バージョン"
+		if len(pack.DBVersions) > 0 {
+			dbVersions = strings.Join(pack.DBVersions, ", ")
+		}
+		signature := "署名検証済み"
+		if pack.Signature == "" {
+			signature = "未署名"
+		}
+		color.Green("  ✓ %s v%d（%s）", pack.Name, pack.Version, signature)
+		logf("      DB: %s / 作成者: %s / ルール: %d個\n", dbVersions, pack.Author, len(pack.Rules))
+		if pack.Description != "" {
+			logf("      %s\n", pack.Description)
+		}
+		packs = append(packs, pack)
+	}
+
+	rules, err := config.TargetRules(game, packs)
+	if err != nil {
+		return err
+	}
+
+	logln()
+	logf("  %-22s  %-12s  %-12s  %-14s  %s\n", "ルール", "種別", "カテゴリ", "定義元", "パス")
+	for _, rule := range rules {
+		path := rule.Path
+		if rule.Type == RuleRegex {
+			path = fmt.Sprintf("%s/ (%s)", rule.Path, rule.Pattern)
+		}
+		if len(rule.DBVersions) > 0 {
+			path = fmt.Sprintf("%s [DB %s]", path, strings.Join(rule.DBVersions, ", "))
+		}
+		logf("  %-22s  %-12s  %-12s  %-14s  %s\n", rule.Name, rule.Type, rule.Category, rule.Source, path)
+	}
+	logln()
+	color.Cyan("==========================================================")
+
+	return nil
+}
+
+// GenerateSigningKey ルールパック署名用の鍵ペアを生成（<prefix>.key と <prefix>.pub）
+func GenerateSigningKey(prefix string) error {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	keyPath := prefix + ".key"
+	pubPath := prefix + ".pub"
+	for _, path := range []string{keyPath, pubPath} {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("ファイルが既に存在します: %s", path)
+		}
+	}
+
+	if err := os.WriteFile(keyPath, []byte(base64.StdEncoding.EncodeToString(privateKey)+"\n"), 0600); err != nil {
+		return fmt.Errorf("秘密鍵保存エラー: %w", err)
+	}
+	encodedPublicKey := base64.StdEncoding.EncodeToString(publicKey)
+	if err := os.WriteFile(pubPath, []byte(encodedPublicKey+"\n"), 0644); err != nil {
+		return fmt.Errorf("公開鍵保存エラー: %w", err)
+	}
+
+	color.Green("✅ 署名用の鍵を生成しました")
+	logf("秘密鍵: %s（公開しないでください）\n", keyPath)
+	logf("公開鍵: %s\n", pubPath)
+	logf("\n利用者は設定ファイルの rule_packs.trusted_keys に公開鍵を追加してください:\n  %s\n", encodedPublicKey)
+	return nil
+}
+
+// SignRulePack ルールパックに署名して上書き保存
+func SignRulePack(path, keyPath string) error {
+	if keyPath == "" {
+		return fmt.Errorf("署名に使う秘密鍵を --key で指定してください")
+	}
+
+	keyData, err := os.ReadFile(keyPath)
+	if err != nil {
+		return err
+	}
+	privateKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(keyData)))
+	if err != nil || len(privateKey) != ed25519.PrivateKeySize {
+		return fmt.Errorf("秘密鍵の形式が不正です: %s", keyPath)
+	}
+
+	pack, err := parseRulePack(path)
+	if err != nil {
+		return err
+	}
+
+	pack.PublicKey = base64.StdEncoding.EncodeToString(ed25519.PrivateKey(privateKey).Public().(ed25519.PublicKey))
+	pack.Signature = ""
+	payload, err := pack.signedPayload()
+	if err != nil {
+		return err
+	}
+	pack.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(ed25519.PrivateKey(privateKey), payload))
+
+	data, err := yaml.Marshal(pack)
+	if err != nil {
+		return fmt.Errorf("ルールパック生成エラー: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("ルールパック保存エラー: %w", err)
+	}
+
+	color.Green("✅ ルールパックに署名しました: %s v%d", pack.Name, pack.Version)
+	return nil
+}
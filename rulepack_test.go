@@ -0,0 +1,286 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRulePack 署名前のルールパック
+const testRulePack = `name: community-extra
+version: 3
+game: fm24
+db_versions: ["2400"]
+author: community
+description: 追加のライセンスファイル
+rules:
+  - name: extra-license
+    type: exact
+    path: dbc/permanent/extra.dbc
+    category: licensing
+`
+
+// writeSignedRulePack ルールパックを作成して署名し、パスと公開鍵を返す
+func writeSignedRulePack(t *testing.T, content string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pack.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	prefix := filepath.Join(dir, "signing")
+	if err := GenerateSigningKey(prefix); err != nil {
+		t.Fatal(err)
+	}
+	if err := SignRulePack(path, prefix+".key"); err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := os.ReadFile(prefix + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, strings.TrimSpace(string(pub))
+}
+
+func TestRulePackVerify(t *testing.T) {
+	path, publicKey := writeSignedRulePack(t, testRulePack)
+	otherPublicKey, otherPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		config  RulePacksConfig
+		modify  func(p *RulePack)
+		wantErr string
+	}{
+		{
+			name:   "署名検証済み",
+			config: RulePacksConfig{TrustedKeys: []string{publicKey}},
+		},
+		{
+			name:    "信頼されていない鍵",
+			config:  RulePacksConfig{},
+			wantErr: "信頼されていない鍵",
+		},
+		{
+			name:    "信頼されていない鍵（未署名を許可）",
+			config:  RulePacksConfig{AllowUnsigned: true},
+			wantErr: "信頼されていない鍵",
+		},
+		{
+			name:    "ルールのパスを改ざん",
+			config:  RulePacksConfig{TrustedKeys: []string{publicKey}},
+			modify:  func(p *RulePack) { p.Rules[0].Path = "dbc" },
+			wantErr: "署名が一致しません",
+		},
+		{
+			name:    "ルールの種別を改ざん",
+			config:  RulePacksConfig{TrustedKeys: []string{publicKey}},
+			modify:  func(p *RulePack) { p.Rules[0].Type = RuleDirContents },
+			wantErr: "署名が一致しません",
+		},
+		{
+			name:   "ルールを追加",
+			config: RulePacksConfig{TrustedKeys: []string{publicKey}},
+			modify: func(p *RulePack) {
+				p.Rules = append(p.Rules, Rule{Name: "all", Type: RuleDirContents, Path: "dbc/permanent"})
+			},
+			wantErr: "署名が一致しません",
+		},
+		{
+			name:    "バージョンを改ざん",
+			config:  RulePacksConfig{TrustedKeys: []string{publicKey}},
+			modify:  func(p *RulePack) { p.Version = 99 },
+			wantErr: "署名が一致しません",
+		},
+		{
+			name:    "対象のDBバージョンを改ざん",
+			config:  RulePacksConfig{TrustedKeys: []string{publicKey}},
+			modify:  func(p *RulePack) { p.DBVersions = nil },
+			wantErr: "署名が一致しません",
+		},
+		{
+			name:   "別の鍵で署名し直して公開鍵を差し替え",
+			config: RulePacksConfig{TrustedKeys: []string{publicKey}},
+			modify: func(p *RulePack) {
+				p.Rules[0].Path = "dbc"
+				p.PublicKey = base64.StdEncoding.EncodeToString(otherPublicKey)
+				p.Signature = ""
+				payload, _ := p.signedPayload()
+				p.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(otherPrivateKey, payload))
+			},
+			wantErr: "信頼されていない鍵",
+		},
+		{
+			name:   "信頼する鍵の公開鍵に差し替え",
+			config: RulePacksConfig{TrustedKeys: []string{publicKey}},
+			modify: func(p *RulePack) {
+				payload, _ := p.signedPayload()
+				p.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(otherPrivateKey, payload))
+			},
+			wantErr: "署名が一致しません",
+		},
+		{
+			name:    "署名なし（公開鍵あり）",
+			config:  RulePacksConfig{TrustedKeys: []string{publicKey}, AllowUnsigned: true},
+			modify:  func(p *RulePack) { p.Signature = "" },
+			wantErr: "signature が不正です",
+		},
+		{
+			name:    "署名が base64 ではない",
+			config:  RulePacksConfig{TrustedKeys: []string{publicKey}},
+			modify:  func(p *RulePack) { p.Signature = "not base64!" },
+			wantErr: "signature が不正です",
+		},
+		{
+			name:    "署名の長さが不正",
+			config:  RulePacksConfig{TrustedKeys: []string{publicKey}},
+			modify:  func(p *RulePack) { p.Signature = base64.StdEncoding.EncodeToString([]byte("short")) },
+			wantErr: "signature が不正です",
+		},
+		{
+			name:    "公開鍵なし（署名あり）",
+			config:  RulePacksConfig{TrustedKeys: []string{publicKey}, AllowUnsigned: true},
+			modify:  func(p *RulePack) { p.PublicKey = "" },
+			wantErr: "public_key が不正です",
+		},
+		{
+			name:    "未署名",
+			config:  RulePacksConfig{TrustedKeys: []string{publicKey}},
+			modify:  func(p *RulePack) { p.PublicKey, p.Signature = "", "" },
+			wantErr: "署名されていません",
+		},
+		{
+			name:   "未署名（allow_unsigned）",
+			config: RulePacksConfig{AllowUnsigned: true},
+			modify: func(p *RulePack) { p.PublicKey, p.Signature = "", "" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pack, err := parseRulePack(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.modify != nil {
+				tt.modify(pack)
+			}
+
+			err = tt.config.verify(pack)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("verify() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("verify() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImportRulePack(t *testing.T) {
+	path, publicKey := writeSignedRulePack(t, testRulePack)
+	game, err := findGame("fm24")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := filepath.Join(t.TempDir(), "tampered.yaml")
+	content := strings.Replace(string(signed), "path: dbc/permanent/extra.dbc", "path: dbc/permanent", 1)
+	if content == string(signed) {
+		t.Fatal("改ざん対象の行がありません")
+	}
+	if err := os.WriteFile(tampered, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	unsigned := filepath.Join(t.TempDir(), "unsigned.yaml")
+	if err := os.WriteFile(unsigned, []byte(testRulePack), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		config  RulePacksConfig
+		wantErr string
+	}{
+		{name: "署名検証済み", path: path, config: RulePacksConfig{TrustedKeys: []string{publicKey}}},
+		{name: "改ざん", path: tampered, config: RulePacksConfig{TrustedKeys: []string{publicKey}}, wantErr: "署名が一致しません"},
+		{name: "信頼されていない鍵", path: path, config: RulePacksConfig{}, wantErr: "信頼されていない鍵"},
+		{name: "未署名", path: unsigned, config: RulePacksConfig{TrustedKeys: []string{publicKey}}, wantErr: "署名されていません"},
+		{name: "未署名（allow_unsigned）", path: unsigned, config: RulePacksConfig{AllowUnsigned: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{RulePacks: tt.config}
+			config.RulePacks.Directory = t.TempDir()
+			dest := filepath.Join(config.RulePacks.Directory, "community-extra.yaml")
+
+			err := ImportRulePack(config, game, tt.path, false)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ImportRulePack() = %v, want %q", err, tt.wantErr)
+				}
+				if _, err := os.Stat(dest); !os.IsNotExist(err) {
+					t.Error("検証に失敗したルールパックが保存されました")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// インポート後の読み込みでも検証される
+			packs, err := config.LoadRulePacks()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(packs) != 1 || packs[0].Name != "community-extra" {
+				t.Errorf("LoadRulePacks() = %+v", packs)
+			}
+		})
+	}
+}
+
+func TestLoadRulePacksRejectsTampered(t *testing.T) {
+	path, publicKey := writeSignedRulePack(t, testRulePack)
+	game, err := findGame("fm24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{RulePacks: RulePacksConfig{Directory: t.TempDir(), TrustedKeys: []string{publicKey}}}
+	if err := ImportRulePack(config, game, path, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// インポート後に保存先のファイルを書き換え
+	dest := filepath.Join(config.RulePacks.Directory, "community-extra.yaml")
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dest, []byte(strings.Replace(string(data), "version: 3", "version: 4", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := config.LoadRulePacks(); err == nil || !strings.Contains(err.Error(), "署名が一致しません") {
+		t.Errorf("LoadRulePacks() = %v, want 署名が一致しません", err)
+	}
+}
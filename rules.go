@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ルールの種類
+const (
+	RuleExact       = "exact"        // DBフォルダからの相対パス（ファイルまたはディレクトリ）
+	RuleGlob        = "glob"         // グロブパターン（例: dbc/permanent/*_fake.dbc）
+	RuleDirContents = "dir-contents" // ディレクトリ内の全エントリ（ディレクトリ自体は残す）
+	RuleRegex       = "regex"        // ディレクトリ直下のファイル名に一致する正規表現
+)
+
+// ruleCategoryCustom カテゴリ省略時のカテゴリ
+const ruleCategoryCustom = "custom"
+
+// Rule 削除対象ルールの定義
+type Rule struct {
+	Name        string   `yaml:"name" json:"name"`
+	Type        string   `yaml:"type,omitempty" json:"type"`
+	Path        string   `yaml:"path,omitempty" json:"path"`       // exact/dir-contents: 対象パス, glob: パターン, regex: 検索するディレクトリ
+	Pattern     string   `yaml:"pattern,omitempty" json:"pattern"` // regex: ファイル名の正規表現
+	Description string   `yaml:"description,omitempty" json:"description"`
+	Category    string   `yaml:"category,omitempty" json:"category"`
+	Disabled    bool     `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	Optional    bool     `yaml:"optional,omitempty" json:"optional,omitempty"`       // インストールによって存在しない（ない場合は削除済みとみなさない）
+	DBVersions  []string `yaml:"db_versions,omitempty" json:"db_versions,omitempty"` // 対象のDBバージョン（省略時は全バージョン）
+	Source      string   `yaml:"-" json:"source,omitempty"`                          // 定義元（builtin, pack:<名前>, config）
+}
+
+// ルールの定義元
+const (
+	ruleSourceBuiltin = "builtin"
+	ruleSourceConfig  = "config"
+)
+
+// mergeRules ルールに別のルールを重ねる
+//
+// 同じ名前のルールは指定された項目のみ上書きし（disabled: true で無効化）、
+// 新しい名前のルールは末尾に追加する。
+func mergeRules(base, overrides []Rule) []Rule {
+	merged := make([]Rule, len(base))
+	copy(merged, base)
+
+	index := make(map[string]int, len(merged))
+	for i, rule := range merged {
+		index[rule.Name] = i
+	}
+
+	for _, override := range overrides {
+		i, ok := index[override.Name]
+		if !ok {
+			index[override.Name] = len(merged)
+			merged = append(merged, override)
+			continue
+		}
+
+		rule := &merged[i]
+		if override.Type != "" {
+			rule.Type = override.Type
+			rule.Pattern = override.Pattern
+		}
+		if override.Path != "" {
+			rule.Path = override.Path
+		}
+		if override.Pattern != "" {
+			rule.Pattern = override.Pattern
+		}
+		if override.Description != "" {
+			rule.Description = override.Description
+		}
+		if override.Category != "" {
+			rule.Category = override.Category
+		}
+		if len(override.DBVersions) > 0 {
+			rule.DBVersions = override.DBVersions
+		}
+		if override.Optional {
+			rule.Optional = true
+		}
+		rule.Disabled = override.Disabled
+		rule.Source = override.Source
+	}
+
+	return merged
+}
+
+// mergedRules ゲームの組み込みルールにルールパック、設定ファイルの順でルールを重ねる（無効化されたルールを含む）
+func (c *Config) mergedRules(game *GameProfile, packs []*RulePack) []Rule {
+	rules := game.defaultRules()
+
+	for _, pack := range packs {
+		if pack.Game != "" && pack.Game != game.ID {
+			continue
+		}
+		packRules := make([]Rule, len(pack.Rules))
+		for i, rule := range pack.Rules {
+			rule.Source = "pack:" + pack.Name
+			if len(rule.DBVersions) == 0 {
+				rule.DBVersions = pack.DBVersions
+			}
+			packRules[i] = rule
+		}
+		rules = mergeRules(rules, packRules)
+	}
+
+	configRules := make([]Rule, len(c.Rules))
+	for i, rule := range c.Rules {
+		rule.Source = ruleSourceConfig
+		configRules[i] = rule
+	}
+
+	return mergeRules(rules, configRules)
+}
+
+// TargetRules 組み込みルール・ルールパック・設定ファイルのルールを合わせた有効なルール
+func (c *Config) TargetRules(game *GameProfile, packs []*RulePack) ([]Rule, error) {
+	for i, rule := range c.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rules[%d]: name を指定してください", i)
+		}
+	}
+
+	var rules []Rule
+	for _, rule := range c.mergedRules(game, packs) {
+		if rule.Disabled {
+			continue
+		}
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("ルール %q: %w", rule.Name, err)
+		}
+		if rule.Description == "" {
+			rule.Description = rule.Path
+		}
+		if rule.Category == "" {
+			rule.Category = ruleCategoryCustom
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// appliesTo ルールがDBバージョンの対象か判定
+func (r Rule) appliesTo(dbVersion string) bool {
+	if len(r.DBVersions) == 0 {
+		return true
+	}
+	for _, version := range r.DBVersions {
+		if version == dbVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// isOptional 対象が存在しないことのあるルールか（glob・regex は一致しないことがあるため常に任意）
+func (r Rule) isOptional() bool {
+	return r.Optional || r.Type == RuleGlob || r.Type == RuleRegex
+}
+
+// validate ルール定義を検証
+func (r Rule) validate() error {
+	if r.Path == "" {
+		return fmt.Errorf("path を指定してください")
+	}
+
+	// DBフォルダの外を指すパスは許可しない
+	cleaned := filepath.Clean(filepath.FromSlash(r.Path))
+	if filepath.IsAbs(cleaned) || filepath.VolumeName(cleaned) != "" || cleaned == "." ||
+		cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("path はDBフォルダからの相対パスで指定してください: %s", r.Path)
+	}
+
+	switch r.Type {
+	case RuleExact, RuleDirContents:
+	case RuleGlob:
+		if _, err := filepath.Match(filepath.FromSlash(r.Path), ""); err != nil {
+			return fmt.Errorf("不正なグロブパターン: %s", r.Path)
+		}
+	case RuleRegex:
+		if r.Pattern == "" {
+			return fmt.Errorf("pattern を指定してください")
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("不正な正規表現: %w", err)
+		}
+	default:
+		return fmt.Errorf("不明なルール種別: %q（exact, glob, dir-contents, regex）", r.Type)
+	}
+
+	return nil
+}
+
+// ruleMatch ルールの照合結果
+type ruleMatch struct {
+	Paths  []string // 一致したフルパス
+	State  string   // TargetPresent, TargetAbsent, TargetEmpty
+	Reason string   // 一致しなかった理由
+}
+
+// match DBフォルダ内でルールに一致するパスを検索
+func (r Rule) match(basePath string) (*ruleMatch, error) {
+	fullPath := filepath.Join(basePath, filepath.FromSlash(r.Path))
+	result := &ruleMatch{State: TargetAbsent}
+
+	switch r.Type {
+	case RuleExact:
+		if _, err := os.Stat(fullPath); err != nil {
+			result.Reason = "ファイルが見つかりません"
+			return result, nil
+		}
+		result.Paths = []string{fullPath}
+
+	case RuleDirContents:
+		stat, err := os.Stat(fullPath)
+		if err != nil || !stat.IsDir() {
+			result.Reason = "ディレクトリが見つかりません"
+			return result, nil
+		}
+		entries, err := os.ReadDir(fullPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			result.State = TargetEmpty
+			result.Reason = "ディレクトリは空です"
+			return result, nil
+		}
+		for _, entry := range entries {
+			result.Paths = append(result.Paths, filepath.Join(fullPath, entry.Name()))
+		}
+
+	case RuleGlob:
+		matches, err := filepath.Glob(filepath.Join(escapeGlob(basePath), filepath.FromSlash(r.Path)))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		result.Paths = matches
+
+	case RuleRegex:
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := os.ReadDir(fullPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && re.MatchString(entry.Name()) {
+				result.Paths = append(result.Paths, filepath.Join(fullPath, entry.Name()))
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("不明なルール種別: %s", r.Type)
+	}
+
+	if len(result.Paths) == 0 {
+		result.Reason = "一致するファイルがありません"
+		return result, nil
+	}
+
+	result.State = TargetPresent
+	return result, nil
+}
+
+// escapeGlob パス中のグロブ特殊文字をエスケープ（Windowsでも使える [] 形式）
+func escapeGlob(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		switch r {
+		case '*', '?', '[':
+			b.WriteByte('[')
+			b.WriteRune(r)
+			b.WriteByte(']')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRuleMatch(t *testing.T) {
+	files := map[string]string{
+		"lnc/all/a.lnc":                     "a",
+		"lnc/all/sub/b.lnc":                 "b",
+		"lnc/empty/":                        "",
+		"dbc/permanent/license.dbc":         "license",
+		"dbc/permanent/forbidden names.dbc": "forbidden",
+		"dbc/permanent/japan_clubs.dbc":     "japan",
+		"dbc/permanent/japan_dir/":          "",
+		"dbc/permanent/old_fake.dbc":        "fake",
+		"dbc/permanent/new_fake.dbc":        "fake",
+		"dbc/permanent/keep.dbc":            "keep",
+	}
+
+	tests := []struct {
+		name      string
+		rule      Rule
+		wantPaths []string
+		wantState string
+	}{
+		{
+			name:      "exact（ファイル）",
+			rule:      Rule{Type: RuleExact, Path: "dbc/permanent/forbidden names.dbc"},
+			wantPaths: []string{"dbc/permanent/forbidden names.dbc"},
+			wantState: TargetPresent,
+		},
+		{
+			name:      "exact（ディレクトリ）",
+			rule:      Rule{Type: RuleExact, Path: "lnc/all"},
+			wantPaths: []string{"lnc/all"},
+			wantState: TargetPresent,
+		},
+		{
+			name:      "exact（なし）",
+			rule:      Rule{Type: RuleExact, Path: "dbc/permanent/missing.dbc"},
+			wantState: TargetAbsent,
+		},
+		{
+			name:      "glob",
+			rule:      Rule{Type: RuleGlob, Path: "dbc/permanent/*_fake.dbc"},
+			wantPaths: []string{"dbc/permanent/new_fake.dbc", "dbc/permanent/old_fake.dbc"},
+			wantState: TargetPresent,
+		},
+		{
+			name:      "glob（一致なし）",
+			rule:      Rule{Type: RuleGlob, Path: "dbc/permanent/*.edt"},
+			wantState: TargetAbsent,
+		},
+		{
+			name:      "dir-contents",
+			rule:      Rule{Type: RuleDirContents, Path: "lnc/all"},
+			wantPaths: []string{"lnc/all/a.lnc", "lnc/all/sub"},
+			wantState: TargetPresent,
+		},
+		{
+			name:      "dir-contents（空）",
+			rule:      Rule{Type: RuleDirContents, Path: "lnc/empty"},
+			wantState: TargetEmpty,
+		},
+		{
+			name:      "dir-contents（ファイル）",
+			rule:      Rule{Type: RuleDirContents, Path: "dbc/permanent/keep.dbc"},
+			wantState: TargetAbsent,
+		},
+		{
+			name:      "regex（ディレクトリは対象外）",
+			rule:      Rule{Type: RuleRegex, Path: "dbc/permanent", Pattern: "^japan"},
+			wantPaths: []string{"dbc/permanent/japan_clubs.dbc"},
+			wantState: TargetPresent,
+		},
+		{
+			name:      "regex（ディレクトリなし）",
+			rule:      Rule{Type: RuleRegex, Path: "dbc/missing", Pattern: "^japan"},
+			wantState: TargetAbsent,
+		},
+	}
+
+	// DBフォルダのパスにグロブの特殊文字を含む場合も一致する
+	for _, baseName := range []string{"db", "db [2024]*"} {
+		base := filepath.Join(t.TempDir(), baseName)
+		writeTree(t, base, files)
+
+		for _, tt := range tests {
+			t.Run(baseName+"/"+tt.name, func(t *testing.T) {
+				match, err := tt.rule.match(base)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got []string
+				for _, path := range match.Paths {
+					rel, _ := filepath.Rel(base, path)
+					got = append(got, filepath.ToSlash(rel))
+				}
+				slices.Sort(got)
+				if !slices.Equal(got, tt.wantPaths) || match.State != tt.wantState {
+					t.Errorf("match() = %q (%s), want %q (%s)", got, match.State, tt.wantPaths, tt.wantState)
+				}
+				if match.State != TargetPresent && match.Reason == "" {
+					t.Error("一致しない理由がありません")
+				}
+			})
+		}
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		rule    Rule
+		wantErr string
+	}{
+		{Rule{Type: RuleExact, Path: "dbc/permanent/license.dbc"}, ""},
+		{Rule{Type: RuleDirContents, Path: "lnc/all"}, ""},
+		{Rule{Type: RuleGlob, Path: "dbc/*/*.dbc"}, ""},
+		{Rule{Type: RuleRegex, Path: "dbc/permanent", Pattern: `^japan.*\.dbc$`}, ""},
+		{Rule{Type: RuleExact}, "path を指定してください"},
+		{Rule{Type: RuleExact, Path: "/etc/passwd"}, "相対パス"},
+		{Rule{Type: RuleExact, Path: "../other/db"}, "相対パス"},
+		{Rule{Type: RuleExact, Path: "dbc/../.."}, "相対パス"},
+		{Rule{Type: RuleDirContents, Path: "."}, "相対パス"},
+		{Rule{Type: RuleGlob, Path: "dbc/[.dbc"}, "不正なグロブパターン"},
+		{Rule{Type: RuleRegex, Path: "dbc/permanent"}, "pattern を指定してください"},
+		{Rule{Type: RuleRegex, Path: "dbc/permanent", Pattern: "("}, "不正な正規表現"},
+		{Rule{Type: "delete-all", Path: "dbc"}, "不明なルール種別"},
+		{Rule{Path: "dbc"}, "不明なルール種別"},
+	}
+
+	for _, tt := range tests {
+		err := tt.rule.validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("validate(%+v) = %v", tt.rule, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("validate(%+v) = %v, want %q", tt.rule, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMergedRules(t *testing.T) {
+	game, err := findGame("fm24")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packs := []*RulePack{
+		{
+			Name:       "community",
+			DBVersions: []string{"2430"},
+			Rules: []Rule{
+				{Name: "license", Path: "dbc/permanent/license_2430.dbc"},
+				{Name: "extra", Type: RuleExact, Path: "dbc/permanent/extra.dbc", Category: "licensing"},
+				{Name: "brazil-kits", Disabled: true},
+			},
+		},
+		{
+			Name:  "fm23-only",
+			Game:  "fm23",
+			Rules: []Rule{{Name: "fake-edt", Disabled: true}},
+		},
+	}
+	config := &Config{Rules: []Rule{
+		{Name: "extra", Path: "dbc/permanent/extra2.dbc"},
+		{Name: "lnc-greek", Disabled: true},
+		{Name: "brazil-kits", Category: "kits"},
+		{Name: "mine", Type: RuleGlob, Path: "dbc/permanent/*_fake.dbc", Category: "fake"},
+	}}
+
+	rules, err := config.TargetRules(game, packs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]Rule)
+	var names []string
+	for _, rule := range rules {
+		byName[rule.Name] = rule
+		names = append(names, rule.Name)
+	}
+
+	tests := []struct {
+		name       string
+		wantPath   string
+		wantSource string
+		wantDB     []string
+		wantCat    string
+	}{
+		// ルールパックで上書き（パックの db_versions を引き継ぐ）
+		{"license", "dbc/permanent/license_2430.dbc", "pack:community", []string{"2430"}, "licensing"},
+		// ルールパックで追加し、設定ファイルで上書き
+		{"extra", "dbc/permanent/extra2.dbc", ruleSourceConfig, []string{"2430"}, "licensing"},
+		// ルールパックで無効化し、設定ファイルで再度有効化（カテゴリのみ変更）
+		{"brazil-kits", "dbc/permanent/brazil_kits.dbc", ruleSourceConfig, []string{"2430"}, "kits"},
+		// 他のゲーム向けのルールパックは適用しない
+		{"fake-edt", "edt/permanent/fake.edt", ruleSourceBuiltin, nil, "licensing"},
+		// 設定ファイルで追加
+		{"mine", "dbc/permanent/*_fake.dbc", ruleSourceConfig, nil, "fake"},
+	}
+	for _, tt := range tests {
+		rule, ok := byName[tt.name]
+		if !ok {
+			t.Errorf("%s: ルールがありません", tt.name)
+			continue
+		}
+		if rule.Path != tt.wantPath || rule.Source != tt.wantSource || !slices.Equal(rule.DBVersions, tt.wantDB) || rule.Category != tt.wantCat {
+			t.Errorf("%s = {path: %s, source: %s, db: %v, category: %s}, want {%s, %s, %v, %s}",
+				tt.name, rule.Path, rule.Source, rule.DBVersions, rule.Category, tt.wantPath, tt.wantSource, tt.wantDB, tt.wantCat)
+		}
+	}
+
+	if _, ok := byName["lnc-greek"]; ok {
+		t.Error("無効化したルールが含まれています")
+	}
+	// 組み込みルールの順序を保ち、追加したルールは末尾
+	if names[0] != "lnc-all" || names[len(names)-2] != "extra" || names[len(names)-1] != "mine" {
+		t.Errorf("ルールの順序 = %q", names)
+	}
+	if !byName["japan-removed-clubs"].isOptional() || byName["license"].isOptional() || !byName["mine"].isOptional() {
+		t.Error("任意のルールの判定が異なります")
+	}
+}
+
+func TestTargetRulesErrors(t *testing.T) {
+	game, err := findGame("fm24")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		rules   []Rule
+		wantErr string
+	}{
+		{"name なし", []Rule{{Path: "dbc/x.dbc"}}, "rules[0]: name を指定してください"},
+		{"種別の変更で pattern が必要", []Rule{{Name: "license", Type: RuleRegex}}, `ルール "license": pattern を指定してください`},
+		{"DBフォルダの外", []Rule{{Name: "escape", Type: RuleExact, Path: "../../save"}}, `ルール "escape"`},
+	}
+
+	for _, tt := range tests {
+		config := &Config{Rules: tt.rules}
+		if _, err := config.TargetRules(game, nil); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: TargetRules() = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,608 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/fatih/color"
+)
+
+// serviceNamePrefix 監視サービスの名前の接頭辞
+const serviceNamePrefix = "fm24-real-watch"
+
+// launchdLabelPrefix launchd エージェントのラベルの接頭辞
+const launchdLabelPrefix = "com.github.safeekow."
+
+// taskFolder Task Scheduler のタスクフォルダ
+const taskFolder = `\fm24-real\`
+
+// serviceNameUnsafe サービス名に使えない文字
+var serviceNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// serviceSpec 監視サービスの定義（生成するファイルはこの内容のみで決まる）
+type serviceSpec struct {
+	Name        string   // サービス名（例: fm24-real-watch-my-fm24）
+	Description string   // サービスの説明
+	Executable  string   // fm24-real の実行ファイル
+	Args        []string // 実行ファイルに渡す引数（watch 以降）
+	LogPath     string   // launchd の出力先
+	User        string   // Task Scheduler の実行ユーザー（DOMAIN\user、空の場合は省略）
+}
+
+// ServiceOptions service コマンドのオプション
+type ServiceOptions struct {
+	ConfigPath    string
+	Game          string
+	Installs      []string // 監視するインストール（インストールごとにサービスを作成）
+	Profile       string
+	DBVersion     string
+	AllDBVersions bool
+	Platform      string // 生成するファイルのプラットフォーム（--dry-run のみ）
+	DryRun        bool   // ファイルを書き込まず内容を表示
+}
+
+// serviceName 監視サービスの名前（インストールまたはゲームを指定した場合はそれを付加）
+func serviceName(game, install string) string {
+	suffix := install
+	if suffix == "" {
+		suffix = game
+	}
+	if suffix == "" {
+		return serviceNamePrefix
+	}
+	return serviceNamePrefix + "-" + strings.Trim(serviceNameUnsafe.ReplaceAllString(suffix, "-"), "-")
+}
+
+// checkServiceName 他のインストールパスやゲームと同じサービス名にならないか確認
+//
+// サービス名は使えない文字を "-" に置き換えるため、"my fm24" と "my-fm24" のように
+// 異なるインストールパスが同じ名前になる場合がある（後からインストールした方で上書きされる）。
+func checkServiceName(config *Config, game, install string) error {
+	name := serviceName(game, install)
+	for _, installPath := range config.InstallPaths {
+		if installPath.Name != install && serviceName(game, installPath.Name) == name {
+			return fmt.Errorf("サービス名 %s がインストールパス %s と重複します（インストールパス名を変更してください）", name, installPath.Name)
+		}
+	}
+	if install == "" {
+		return nil
+	}
+	for _, profile := range gameProfiles() {
+		if serviceName(profile.ID, "") == name {
+			return fmt.Errorf("サービス名 %s が --game %s のサービスと重複します（インストールパス名を変更してください）", name, profile.ID)
+		}
+	}
+	return nil
+}
+
+// serviceFilePath サービス定義ファイルのパス
+func serviceFilePath(platform, name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("ホームディレクトリ取得エラー: %w", err)
+	}
+	switch platform {
+	case "linux":
+		configHome := os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		return filepath.Join(configHome, "systemd", "user", name+".service"), nil
+	case "darwin":
+		return filepath.Join(home, "Library", "LaunchAgents", launchdLabelPrefix+name+".plist"), nil
+	case "windows":
+		return filepath.Join(filepath.Dir(GetDefaultConfigPath()), "services", name+".xml"), nil
+	}
+	return "", fmt.Errorf("サービスに対応していないプラットフォームです: %s", platform)
+}
+
+// newServiceSpecs 選択したインストールごとの監視サービスの定義
+func newServiceSpecs(options ServiceOptions, platform string) ([]*serviceSpec, error) {
+	config, err := LoadConfig(options.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := config.SelectGame(options.Game); err != nil {
+		return nil, err
+	}
+	if options.Profile != "" {
+		if _, err := config.findProfile(options.Profile); err != nil {
+			return nil, err
+		}
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("実行ファイルのパス取得エラー: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	configPath, err := filepath.Abs(options.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	home, _ := os.UserHomeDir()
+	user := os.Getenv("USERNAME")
+	if domain := os.Getenv("USERDOMAIN"); domain != "" && user != "" {
+		user = domain + `\` + user
+	}
+
+	installs := options.Installs
+	if len(installs) == 0 {
+		installs = []string{""}
+	}
+
+	var specs []*serviceSpec
+	for _, install := range installs {
+		target := "自動検出"
+		if install != "" {
+			installPath, err := config.findInstallPath(install)
+			if err != nil {
+				return nil, err
+			}
+			if installPath.Platform != platform {
+				return nil, fmt.Errorf("インストールパス %s は %s 用です", install, installPath.Platform)
+			}
+			target = install
+		}
+
+		args := []string{"watch", "--config", configPath}
+		if options.Game != "" {
+			args = append(args, "--game", options.Game)
+		}
+		if install != "" {
+			args = append(args, "--install", install)
+		}
+		if options.Profile != "" {
+			args = append(args, "--profile", options.Profile)
+		}
+		if options.DBVersion != "" {
+			args = append(args, "--db-version", options.DBVersion)
+		}
+		if options.AllDBVersions {
+			args = append(args, "--all-db-versions")
+		}
+
+		name := serviceName(options.Game, install)
+		if err := checkServiceName(config, options.Game, install); err != nil {
+			return nil, err
+		}
+		specs = append(specs, &serviceSpec{
+			Name:        name,
+			Description: fmt.Sprintf("Football Manager 実名化ツール: アップデートの監視（%s）", target),
+			Executable:  executable,
+			Args:        args,
+			LogPath:     filepath.Join(home, "Library", "Logs", "fm24-real", name+".log"),
+			User:        user,
+		})
+	}
+	return specs, nil
+}
+
+// renderService プラットフォームのサービス定義ファイルの内容
+func renderService(platform string, spec *serviceSpec) (string, error) {
+	switch platform {
+	case "linux":
+		return renderSystemdUnit(spec), nil
+	case "darwin":
+		return renderLaunchdPlist(spec), nil
+	case "windows":
+		return renderTaskXML(spec), nil
+	}
+	return "", fmt.Errorf("サービスに対応していないプラットフォームです: %s", platform)
+}
+
+// renderSystemdUnit systemd ユーザーユニット
+func renderSystemdUnit(spec *serviceSpec) string {
+	command := []string{systemdQuote(spec.Executable)}
+	for _, arg := range spec.Args {
+		command = append(command, systemdQuote(arg))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s service install で生成（手動で編集した内容は再インストールで上書きされます）\n", programName)
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s\n", strings.ReplaceAll(spec.Description, "%", "%%"))
+	b.WriteString("Documentation=https://github.com/safeekow/fm24-real\n")
+	b.WriteString("\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=simple\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(command, " "))
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=60\n")
+	b.WriteString("\n")
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// systemdQuote ExecStart の引数（% と $ はエスケープし、空白等を含む場合は引用符で囲む）
+func systemdQuote(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	arg = strings.ReplaceAll(arg, "$", "$$")
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+// renderLaunchdPlist launchd エージェントの plist
+func renderLaunchdPlist(spec *serviceSpec) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	fmt.Fprintf(&b, "<!-- %s service install で生成 -->\n", programName)
+	b.WriteString(`<plist version="1.0">` + "\n")
+	b.WriteString("<dict>\n")
+	fmt.Fprintf(&b, "\t<key>Label</key>\n\t<string>%s</string>\n", xmlEscape(launchdLabelPrefix+spec.Name))
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	fmt.Fprintf(&b, "\t\t<string>%s</string>\n", xmlEscape(spec.Executable))
+	for _, arg := range spec.Args {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", xmlEscape(arg))
+	}
+	b.WriteString("\t</array>\n")
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	// 異常終了した場合のみ再起動
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	b.WriteString("\t<key>ThrottleInterval</key>\n\t<integer>60</integer>\n")
+	fmt.Fprintf(&b, "\t<key>StandardOutPath</key>\n\t<string>%s</string>\n", xmlEscape(spec.LogPath))
+	fmt.Fprintf(&b, "\t<key>StandardErrorPath</key>\n\t<string>%s</string>\n", xmlEscape(spec.LogPath))
+	b.WriteString("</dict>\n")
+	b.WriteString("</plist>\n")
+	return b.String()
+}
+
+// renderTaskXML Task Scheduler のタスク定義（ログオン時に起動）
+func renderTaskXML(spec *serviceSpec) string {
+	var args []string
+	for _, arg := range spec.Args {
+		args = append(args, windowsQuoteArg(arg))
+	}
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-16"?>` + "\r\n")
+	fmt.Fprintf(&b, "<!-- %s service install で生成 -->\r\n", programName)
+	b.WriteString(`<Task version="1.2" xmlns="http://schemas.microsoft.com/windows/2004/02/mit/task">` + "\r\n")
+	b.WriteString("  <RegistrationInfo>\r\n")
+	fmt.Fprintf(&b, "    <Description>%s</Description>\r\n", xmlEscape(spec.Description))
+	fmt.Fprintf(&b, "    <URI>%s</URI>\r\n", xmlEscape(taskFolder+spec.Name))
+	b.WriteString("  </RegistrationInfo>\r\n")
+	b.WriteString("  <Triggers>\r\n")
+	b.WriteString("    <LogonTrigger>\r\n")
+	b.WriteString("      <Enabled>true</Enabled>\r\n")
+	if spec.User != "" {
+		fmt.Fprintf(&b, "      <UserId>%s</UserId>\r\n", xmlEscape(spec.User))
+	}
+	b.WriteString("    </LogonTrigger>\r\n")
+	b.WriteString("  </Triggers>\r\n")
+	b.WriteString("  <Principals>\r\n")
+	b.WriteString(`    <Principal id="Author">` + "\r\n")
+	if spec.User != "" {
+		fmt.Fprintf(&b, "      <UserId>%s</UserId>\r\n", xmlEscape(spec.User))
+	}
+	b.WriteString("      <LogonType>InteractiveToken</LogonType>\r\n")
+	b.WriteString("      <RunLevel>LeastPrivilege</RunLevel>\r\n")
+	b.WriteString("    </Principal>\r\n")
+	b.WriteString("  </Principals>\r\n")
+	b.WriteString("  <Settings>\r\n")
+	b.WriteString("    <MultipleInstancesPolicy>IgnoreNew</MultipleInstancesPolicy>\r\n")
+	b.WriteString("    <DisallowStartIfOnBatteries>false</DisallowStartIfOnBatteries>\r\n")
+	b.WriteString("    <StopIfGoingOnBatteries>false</StopIfGoingOnBatteries>\r\n")
+	b.WriteString("    <ExecutionTimeLimit>PT0S</ExecutionTimeLimit>\r\n")
+	b.WriteString("    <RestartOnFailure>\r\n")
+	b.WriteString("      <Interval>PT1M</Interval>\r\n")
+	b.WriteString("      <Count>3</Count>\r\n")
+	b.WriteString("    </RestartOnFailure>\r\n")
+	b.WriteString("    <Hidden>true</Hidden>\r\n")
+	b.WriteString("  </Settings>\r\n")
+	b.WriteString(`  <Actions Context="Author">` + "\r\n")
+	b.WriteString("    <Exec>\r\n")
+	fmt.Fprintf(&b, "      <Command>%s</Command>\r\n", xmlEscape(spec.Executable))
+	fmt.Fprintf(&b, "      <Arguments>%s</Arguments>\r\n", xmlEscape(strings.Join(args, " ")))
+	b.WriteString("    </Exec>\r\n")
+	b.WriteString("  </Actions>\r\n")
+	b.WriteString("</Task>\r\n")
+	return b.String()
+}
+
+// windowsQuoteArg Windows のコマンドライン引数（CommandLineToArgvW の規則で引用）
+func windowsQuoteArg(arg string) string {
+	if arg == "" {
+		return `""`
+	}
+	if !strings.ContainsAny(arg, " \t\"") {
+		return arg
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	slashes := 0
+	for _, c := range arg {
+		if c == '\\' {
+			slashes++
+			continue
+		}
+		if c == '"' {
+			b.WriteString(strings.Repeat(`\`, slashes*2+1))
+		} else {
+			b.WriteString(strings.Repeat(`\`, slashes))
+		}
+		slashes = 0
+		b.WriteRune(c)
+	}
+	b.WriteString(strings.Repeat(`\`, slashes*2))
+	b.WriteByte('"')
+	return b.String()
+}
+
+// xmlEscape XMLのテキストとしてエスケープ
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// encodeUTF16 Task Scheduler が読み込む UTF-16LE（BOM付き）に変換
+func encodeUTF16(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	data := make([]byte, 2+len(units)*2)
+	binary.LittleEndian.PutUint16(data, 0xFEFF)
+	for i, unit := range units {
+		binary.LittleEndian.PutUint16(data[2+i*2:], unit)
+	}
+	return data
+}
+
+// InstallService 監視サービスの定義ファイルを書き込み、ログイン時に起動するよう登録
+func InstallService(options ServiceOptions) error {
+	platform := runtime.GOOS
+	if options.Platform != "" {
+		platform = options.Platform
+	}
+
+	specs, err := newServiceSpecs(options, platform)
+	if err != nil {
+		return err
+	}
+
+	for _, spec := range specs {
+		path, err := serviceFilePath(platform, spec.Name)
+		if err != nil {
+			return err
+		}
+		content, err := renderService(platform, spec)
+		if err != nil {
+			return err
+		}
+
+		// 内容のみを標準出力に表示（パスは標準エラー出力）
+		if options.DryRun {
+			color.New(color.FgCyan).Fprintf(os.Stderr, "# %s\n", path)
+			fmt.Print(content)
+			continue
+		}
+
+		if err := writeServiceFile(platform, path, content); err != nil {
+			return err
+		}
+		color.Green("✓ サービス定義を書き込みました: %s", path)
+
+		if platform == "darwin" {
+			if err := os.MkdirAll(filepath.Dir(spec.LogPath), 0755); err != nil {
+				return fmt.Errorf("ログディレクトリ作成エラー: %w", err)
+			}
+		}
+		if err := registerService(platform, spec.Name, path); err != nil {
+			color.Yellow("⚠️  サービスの登録に失敗しました: %v", err)
+			color.Yellow("💡 定義ファイルは書き込み済みです。次のコマンドで登録できます:")
+			for _, command := range registerCommands(platform, spec.Name, path) {
+				logf("    %s\n", strings.Join(command, " "))
+			}
+			continue
+		}
+		color.Green("✓ %s を登録して起動しました", spec.Name)
+	}
+
+	return nil
+}
+
+// writeServiceFile サービス定義ファイルを書き込み（Windows のタスク定義は UTF-16）
+func writeServiceFile(platform, path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("ディレクトリ作成エラー: %w", err)
+	}
+	data := []byte(content)
+	if platform == "windows" {
+		data = encodeUTF16(content)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("サービス定義書き込みエラー: %w", err)
+	}
+	return nil
+}
+
+// registerCommands サービスマネージャーに登録して起動するコマンド
+func registerCommands(platform, name, path string) [][]string {
+	switch platform {
+	case "linux":
+		return [][]string{
+			{"systemctl", "--user", "daemon-reload"},
+			{"systemctl", "--user", "enable", "--now", name + ".service"},
+		}
+	case "darwin":
+		return [][]string{{"launchctl", "load", "-w", path}}
+	case "windows":
+		return [][]string{
+			{"schtasks", "/Create", "/TN", taskFolder + name, "/XML", path, "/F"},
+			{"schtasks", "/Run", "/TN", taskFolder + name},
+		}
+	}
+	return nil
+}
+
+// registerService サービスマネージャーに登録して起動
+func registerService(platform, name, path string) error {
+	// launchd は登録済みの場合に読み込み直す
+	if platform == "darwin" {
+		runServiceManager("launchctl", "unload", path)
+	}
+	for _, command := range registerCommands(platform, name, path) {
+		if err := runServiceManager(command[0], command[1:]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UninstallService 監視サービスを停止して登録と定義ファイルを削除
+func UninstallService(options ServiceOptions) error {
+	platform := runtime.GOOS
+
+	names := []string{}
+	if len(options.Installs) == 0 {
+		names = append(names, serviceName(options.Game, ""))
+	}
+	for _, install := range options.Installs {
+		names = append(names, serviceName(options.Game, install))
+	}
+
+	for _, name := range names {
+		path, err := serviceFilePath(platform, name)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return notFoundf("サービス %s はインストールされていません: %s", name, path)
+		}
+
+		if err := unregisterService(platform, name, path); err != nil {
+			color.Yellow("⚠️  サービスの登録解除に失敗しました: %v", err)
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("サービス定義削除エラー: %w", err)
+		}
+		if platform == "linux" {
+			runServiceManager("systemctl", "--user", "daemon-reload")
+		}
+		color.Green("✓ %s を削除しました: %s", name, path)
+	}
+
+	return nil
+}
+
+// unregisterService サービスを停止してサービスマネージャーから登録解除
+func unregisterService(platform, name, path string) error {
+	switch platform {
+	case "linux":
+		return runServiceManager("systemctl", "--user", "disable", "--now", name+".service")
+	case "darwin":
+		return runServiceManager("launchctl", "unload", "-w", path)
+	case "windows":
+		runServiceManager("schtasks", "/End", "/TN", taskFolder+name)
+		return runServiceManager("schtasks", "/Delete", "/TN", taskFolder+name, "/F")
+	}
+	return nil
+}
+
+// ServiceStatus インストール済みの監視サービスと状態を表示
+func ServiceStatus() error {
+	platform := runtime.GOOS
+	path, err := serviceFilePath(platform, serviceNamePrefix)
+	if err != nil {
+		return err
+	}
+	// 定義ファイル名は <接頭辞（launchd のラベル等）><サービス名><拡張子>
+	dir := filepath.Dir(path)
+	ext := filepath.Ext(path)
+	filePrefix := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ext), serviceNamePrefix)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("サービス定義の読み込みエラー: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := strings.TrimPrefix(strings.TrimSuffix(entry.Name(), ext), filePrefix)
+		if filepath.Ext(entry.Name()) == ext && (name == serviceNamePrefix || strings.HasPrefix(name, serviceNamePrefix+"-")) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	color.Cyan("==========================================================")
+	color.Cyan("監視サービス")
+	color.Cyan("==========================================================\n")
+
+	if len(names) == 0 {
+		logf("インストールされている監視サービスはありません（%s）\n", dir)
+		logf("💡 '%s service install' でインストールできます\n", programName)
+		return nil
+	}
+
+	for _, name := range names {
+		path, _ := serviceFilePath(platform, name)
+		state := serviceState(platform, name)
+		switch state {
+		case "active", "running":
+			color.Green("  ● %s（%s）", name, state)
+		default:
+			color.Yellow("  ○ %s（%s）", name, state)
+		}
+		logf("      %s\n", path)
+	}
+	return nil
+}
+
+// serviceState サービスマネージャーから取得したサービスの状態
+func serviceState(platform, name string) string {
+	switch platform {
+	case "linux":
+		// is-active は停止中の場合に終了コード3を返すため、出力のみを使用
+		output, _ := exec.Command("systemctl", "--user", "is-active", name+".service").Output()
+		if state := strings.TrimSpace(string(output)); state != "" {
+			return state
+		}
+	case "darwin":
+		output, err := exec.Command("launchctl", "list", launchdLabelPrefix+name).Output()
+		if err != nil {
+			return "not loaded"
+		}
+		if strings.Contains(string(output), `"PID" =`) {
+			return "running"
+		}
+		return "loaded"
+	case "windows":
+		if err := exec.Command("schtasks", "/Query", "/TN", taskFolder+name).Run(); err != nil {
+			return "not registered"
+		}
+		return "registered"
+	}
+	return "unknown"
+}
+
+// runServiceManager サービスマネージャーのコマンドを実行（実行内容を表示）
+func runServiceManager(name string, args ...string) error {
+	logf("$ %s %s\n", name, strings.Join(args, " "))
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		message := strings.TrimSpace(string(output))
+		if message == "" {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return fmt.Errorf("%s: %w: %s", name, err, message)
+	}
+	return nil
+}
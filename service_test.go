@@ -0,0 +1,236 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// updateGolden testdata のゴールデンファイルを更新（go test -run TestRender -update）
+var updateGolden = flag.Bool("update", false, "testdata のゴールデンファイルを更新")
+
+// checkGolden 生成した内容をゴールデンファイルと比較
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ゴールデンファイル読み込みエラー（-update で生成）: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s と一致しません\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+func TestRenderService(t *testing.T) {
+	tests := []struct {
+		platform string
+		golden   string
+		spec     *serviceSpec
+	}{
+		{
+			platform: "linux",
+			golden:   "service/fm24-real-watch-my-fm24.service",
+			spec: &serviceSpec{
+				Name:        "fm24-real-watch-my-fm24",
+				Description: "Football Manager 実名化ツール: アップデートの監視（my fm24 100%）",
+				Executable:  "/home/user/bin/fm 24/fm24-real",
+				Args:        []string{"watch", "--config", "/home/user/.config/fm24-real/config $HOME.yaml", "--install", "my fm24"},
+				LogPath:     "/home/user/Library/Logs/fm24-real/fm24-real-watch-my-fm24.log",
+			},
+		},
+		{
+			platform: "darwin",
+			golden:   "service/com.github.safeekow.fm24-real-watch-my-fm24.plist",
+			spec: &serviceSpec{
+				Name:        "fm24-real-watch-my-fm24",
+				Description: "Football Manager 実名化ツール: アップデートの監視（my fm24）",
+				Executable:  "/Users/user/bin/fm24-real",
+				Args:        []string{"watch", "--config", "/Users/user/Library/Application Support/fm24-real/<config>&.yaml", "--install", "my fm24"},
+				LogPath:     "/Users/user/Library/Logs/fm24-real/fm24-real-watch-my-fm24.log",
+			},
+		},
+		{
+			platform: "windows",
+			golden:   "service/fm24-real-watch-my-fm24.xml",
+			spec: &serviceSpec{
+				Name:        "fm24-real-watch-my-fm24",
+				Description: "Football Manager 実名化ツール: アップデートの監視（my fm24）",
+				Executable:  `C:\Program Files\fm24-real\fm24-real.exe`,
+				Args:        []string{"watch", "--config", `C:\Users\user\.config\fm24-real\`, "--install", `my "fm24"`},
+				User:        `DESKTOP\user`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.platform, func(t *testing.T) {
+			got, err := renderService(tt.platform, tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.golden, got)
+
+			// 同じ定義からは常に同じ内容を生成する
+			again, _ := renderService(tt.platform, tt.spec)
+			if again != got {
+				t.Error("生成結果が一定ではありません")
+			}
+		})
+	}
+}
+
+func TestRenderTaskXMLLineEndings(t *testing.T) {
+	got := renderTaskXML(&serviceSpec{Name: "fm24-real-watch", Executable: `C:\fm24-real.exe`, Args: []string{"watch"}})
+	if strings.Contains(strings.ReplaceAll(got, "\r\n", ""), "\n") {
+		t.Error("CRLF 以外の改行が含まれています")
+	}
+
+	data := encodeUTF16(got)
+	if data[0] != 0xFF || data[1] != 0xFE {
+		t.Errorf("BOM がありません: % x", data[:2])
+	}
+}
+
+func TestSystemdQuote(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"watch", "watch"},
+		{"", `""`},
+		{"/opt/fm 24/fm24-real", `"/opt/fm 24/fm24-real"`},
+		{"100%", "100%%"},
+		{"%h/config.yaml", "%%h/config.yaml"},
+		{"$HOME/config.yaml", "$$HOME/config.yaml"},
+		{"${HOME}", "$${HOME}"},
+		{`my "fm24"`, `"my \"fm24\""`},
+		{"it's", `"it's"`},
+		{`C:\games\`, `"C:\\games\\"`},
+		{"a;b", `"a;b"`},
+		{"tab\there", "\"tab\there\""},
+		{"/path/100% $x/", `"/path/100%% $$x/"`},
+	}
+
+	for _, tt := range tests {
+		if got := systemdQuote(tt.arg); got != tt.want {
+			t.Errorf("systemdQuote(%q) = %q, want %q", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestWindowsQuoteArg(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"watch", "watch"},
+		{"", `""`},
+		{`C:\Program Files\fm24-real`, `"C:\Program Files\fm24-real"`},
+		{`C:\config\`, `C:\config\`},
+		{`C:\my config\`, `"C:\my config\\"`},
+		{`C:\my config\\`, `"C:\my config\\\\"`},
+		{`my "fm24"`, `"my \"fm24\""`},
+		{`a\"b`, `"a\\\"b"`},
+		{"%APPDATA%", "%APPDATA%"},
+		{"100% done", `"100% done"`},
+		{"$HOME", "$HOME"},
+	}
+
+	for _, tt := range tests {
+		got := windowsQuoteArg(tt.arg)
+		if got != tt.want {
+			t.Errorf("windowsQuoteArg(%q) = %q, want %q", tt.arg, got, tt.want)
+		}
+		// CommandLineToArgvW の規則で元の引数に戻る
+		if parsed := parseWindowsArgs(got); len(parsed) != 1 || parsed[0] != tt.arg {
+			t.Errorf("windowsQuoteArg(%q) の解析結果 = %q", tt.arg, parsed)
+		}
+	}
+}
+
+// parseWindowsArgs CommandLineToArgvW と同じ規則でコマンドラインを分割（テスト用）
+func parseWindowsArgs(line string) []string {
+	var args []string
+	var current strings.Builder
+	inQuotes, hasArg := false, false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\':
+			slashes := 0
+			for i < len(line) && line[i] == '\\' {
+				slashes++
+				i++
+			}
+			if i < len(line) && line[i] == '"' {
+				current.WriteString(strings.Repeat(`\`, slashes/2))
+				if slashes%2 == 1 {
+					current.WriteByte('"')
+				} else {
+					inQuotes = !inQuotes
+				}
+			} else {
+				current.WriteString(strings.Repeat(`\`, slashes))
+				i--
+			}
+			hasArg = true
+		case c == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case (c == ' ' || c == '\t') && !inQuotes:
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteByte(c)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+func TestCheckServiceName(t *testing.T) {
+	config := &Config{InstallPaths: []InstallPath{
+		{Name: "my fm24"},
+		{Name: "my-fm24"},
+		{Name: "steam"},
+		{Name: "fm23"},
+	}}
+
+	tests := []struct {
+		game    string
+		install string
+		wantErr bool
+	}{
+		{"", "steam", false},
+		{"", "my fm24", true},
+		{"", "my-fm24", true},
+		{"", "", false},
+		{"fm24", "", false},
+		{"fm23", "", true}, // インストールパス fm23 と同じ名前
+		{"", "fm23", true},
+	}
+
+	for _, tt := range tests {
+		err := checkServiceName(config, tt.game, tt.install)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkServiceName(%q, %q) = %v, wantErr %v", tt.game, tt.install, err, tt.wantErr)
+		}
+	}
+}
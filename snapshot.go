@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// バックアップ形式
+const (
+	BackupFormatDir   = "dir"
+	BackupFormatZip   = "zip"
+	BackupFormatTarGz = "tar.gz"
+	BackupFormatCAS   = "cas" // 重複排除ストア
+)
+
+// snapshotWriter バックアップスナップショットの書き込み
+type snapshotWriter interface {
+	// AddFile ファイルをスナップショットに追加し、サイズとSHA-256を返す
+	AddFile(relPath, srcPath string, info os.FileInfo) (int64, string, error)
+	// Commit マニフェストを書き込んでスナップショットを確定
+	Commit(manifest *BackupManifest) error
+	// Abort 書き込み途中のスナップショットを破棄
+	Abort() error
+	// Location スナップショットの保存場所
+	Location() string
+}
+
+// snapshotReader バックアップスナップショットの読み込み
+type snapshotReader interface {
+	// Manifest マニフェスト（存在しない場合はnil）
+	Manifest() (*BackupManifest, error)
+	// Files マニフェストを除く全ファイルの相対パス（スラッシュ区切り）
+	Files() ([]string, error)
+	// ReadFile ファイル内容を読み込み（存在しない場合は os.ErrNotExist）
+	ReadFile(relPath string) ([]byte, error)
+	Close() error
+}
+
+// snapshotExtension バックアップ形式に対応する拡張子
+func snapshotExtension(format string) (string, error) {
+	switch format {
+	case "", BackupFormatDir:
+		return "", nil
+	case BackupFormatZip:
+		return ".zip", nil
+	case BackupFormatTarGz:
+		return ".tar.gz", nil
+	case BackupFormatCAS:
+		return casIndexExt, nil
+	default:
+		return "", fmt.Errorf("不明なバックアップ形式です: %s (dir, zip, tar.gz, cas のいずれかを指定してください)", format)
+	}
+}
+
+// parseSnapshotName ファイル名からスナップショットIDと形式を取得
+func parseSnapshotName(name string, isDir bool) (string, string) {
+	if isDir {
+		return name, BackupFormatDir
+	}
+	for _, format := range []string{BackupFormatZip, BackupFormatTarGz} {
+		ext, _ := snapshotExtension(format)
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext), format
+		}
+	}
+	return "", ""
+}
+
+// snapshotLocation スナップショットの保存場所
+func snapshotLocation(root, id, format string) (string, error) {
+	ext, err := snapshotExtension(format)
+	if err != nil {
+		return "", err
+	}
+
+	if format == BackupFormatCAS {
+		return filepath.Join(root, casDirName, casIndexDir, id+ext), nil
+	}
+
+	return filepath.Join(root, id+ext), nil
+}
+
+// snapshotEntryLocation スナップショット内のファイルの保存場所（表示用）
+func snapshotEntryLocation(format, location, relPath string) string {
+	if format == "" || format == BackupFormatDir {
+		return filepath.Join(location, filepath.FromSlash(relPath))
+	}
+	return location + ":" + relPath
+}
+
+// isSafeSnapshotPath スナップショット内のパスが復元先の外を指さないか（絶対パス・ドライブ名・".." を拒否）
+func isSafeSnapshotPath(relPath string) bool {
+	if strings.Contains(relPath, `\`) || slices.Contains(strings.Split(relPath, "/"), "..") {
+		return false
+	}
+	return filepath.IsLocal(filepath.FromSlash(relPath))
+}
+
+// newSnapshotWriter 指定形式のスナップショットを作成
+func newSnapshotWriter(root, id, format string) (snapshotWriter, error) {
+	path, err := snapshotLocation(root, id, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case BackupFormatZip:
+		return newZipSnapshotWriter(path)
+	case BackupFormatTarGz:
+		return newTarGzSnapshotWriter(path)
+	case BackupFormatCAS:
+		return newCASSnapshotWriter(filepath.Join(root, casDirName), id)
+	default:
+		return newDirSnapshotWriter(path)
+	}
+}
+
+// openSnapshot スナップショットを読み込み用に開く
+func openSnapshot(snapshot *BackupSnapshot) (snapshotReader, error) {
+	switch snapshot.Format {
+	case BackupFormatZip:
+		return openZipSnapshot(snapshot.Path)
+	case BackupFormatTarGz:
+		return openTarGzSnapshot(snapshot.Path)
+	case BackupFormatCAS:
+		return openCASSnapshot(snapshot.Path)
+	default:
+		return &dirSnapshotReader{path: snapshot.Path}, nil
+	}
+}
+
+// dirSnapshotWriter ディレクトリ形式のスナップショット
+type dirSnapshotWriter struct {
+	path string
+}
+
+func newDirSnapshotWriter(path string) (*dirSnapshotWriter, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+	return &dirSnapshotWriter{path: path}, nil
+}
+
+func (w *dirSnapshotWriter) AddFile(relPath, srcPath string, info os.FileInfo) (int64, string, error) {
+	dstPath := filepath.Join(w.path, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return 0, "", err
+	}
+	return copyFileWithHash(srcPath, dstPath, info.Mode())
+}
+
+func (w *dirSnapshotWriter) Commit(manifest *BackupManifest) error {
+	return writeManifest(w.path, manifest)
+}
+
+func (w *dirSnapshotWriter) Abort() error {
+	return os.RemoveAll(w.path)
+}
+
+func (w *dirSnapshotWriter) Location() string {
+	return w.path
+}
+
+// dirSnapshotReader ディレクトリ形式のスナップショット読み込み
+type dirSnapshotReader struct {
+	path string
+}
+
+func (r *dirSnapshotReader) Manifest() (*BackupManifest, error) {
+	return readManifest(r.path)
+}
+
+func (r *dirSnapshotReader) Files() ([]string, error) {
+	var files []string
+	err := filepath.Walk(r.path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(r.path, path)
+		if err != nil {
+			return err
+		}
+		if relPath = filepath.ToSlash(relPath); relPath != manifestFileName {
+			files = append(files, relPath)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func (r *dirSnapshotReader) ReadFile(relPath string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(r.path, filepath.FromSlash(relPath)))
+}
+
+func (r *dirSnapshotReader) Close() error {
+	return nil
+}
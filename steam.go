@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// steamLibrary Steamライブラリフォルダ
+type steamLibrary struct {
+	Path string          // ライブラリフォルダ（steamapps の親）
+	Apps map[string]bool // このライブラリにインストールされているアプリID
+}
+
+// steamApp appmanifest_<appid>.acf から取得したインストール情報
+type steamApp struct {
+	AppID      string
+	Name       string
+	InstallDir string // steamapps/common 内のフォルダ名
+	BuildID    string
+	Library    string
+}
+
+// Path ゲームのインストールフォルダ
+func (a *steamApp) Path() string {
+	return filepath.Join(a.Library, "steamapps", "common", a.InstallDir)
+}
+
+// steamRoots Steam本体がインストールされうる場所
+func steamRoots() []string {
+	home, _ := os.UserHomeDir()
+
+	switch runtime.GOOS {
+	case "windows":
+		return []string{
+			`C:\Program Files (x86)\Steam`,
+			`C:\Program Files\Steam`,
+			filepath.Join(home, "AppData/Local/Steam"),
+		}
+	case "darwin":
+		return []string{filepath.Join(home, "Library/Application Support/Steam")}
+	case "linux":
+		return linuxSteamRoots(home)
+	}
+	return nil
+}
+
+// findSteamLibraries 全てのSteamライブラリフォルダを libraryfolders.vdf から取得
+func findSteamLibraries() []steamLibrary {
+	var libraries []steamLibrary
+	seen := make(map[string]int)
+
+	add := func(library steamLibrary) {
+		key := library.Path
+		if resolved, err := filepath.EvalSymlinks(library.Path); err == nil {
+			key = resolved
+		}
+		if i, ok := seen[key]; ok {
+			for appID := range library.Apps {
+				libraries[i].Apps[appID] = true
+			}
+			return
+		}
+		seen[key] = len(libraries)
+		libraries = append(libraries, library)
+	}
+
+	for _, root := range steamRoots() {
+		if _, err := os.Stat(filepath.Join(root, "steamapps")); err != nil {
+			continue
+		}
+		add(steamLibrary{Path: root, Apps: map[string]bool{}})
+
+		for _, vdfPath := range []string{
+			filepath.Join(root, "steamapps", "libraryfolders.vdf"),
+			filepath.Join(root, "config", "libraryfolders.vdf"),
+		} {
+			data, err := os.ReadFile(vdfPath)
+			if err != nil {
+				continue
+			}
+			found, err := parseLibraryFolders(data)
+			if err != nil {
+				continue
+			}
+			for _, library := range found {
+				add(library)
+			}
+		}
+	}
+
+	return libraries
+}
+
+// parseLibraryFolders libraryfolders.vdf からライブラリ一覧を取得
+//
+// 新形式: "libraryfolders" { "0" { "path" "..." "apps" { "<appid>" "<size>" } } }
+// 旧形式: "LibraryFolders" { "1" "D:\\SteamLibrary" }
+func parseLibraryFolders(data []byte) ([]steamLibrary, error) {
+	root, err := parseVDF(data)
+	if err != nil {
+		return nil, err
+	}
+
+	folders := root.Get("libraryfolders")
+	if folders == nil {
+		return nil, fmt.Errorf("libraryfolders がありません")
+	}
+
+	var libraries []steamLibrary
+	for _, entry := range folders.Children {
+		// ライブラリは数値キーのみ（TimeNextStatsReport 等は無視）
+		if _, err := strconv.Atoi(entry.Key); err != nil {
+			continue
+		}
+
+		library := steamLibrary{Apps: map[string]bool{}}
+		if entry.isBlock() {
+			library.Path = entry.String("path")
+			if apps := entry.Get("apps"); apps != nil {
+				for _, app := range apps.Children {
+					library.Apps[app.Key] = true
+				}
+			}
+		} else {
+			library.Path = entry.Value
+		}
+
+		if library.Path != "" {
+			libraries = append(libraries, library)
+		}
+	}
+
+	return libraries, nil
+}
+
+// readSteamAppManifest ライブラリの appmanifest_<appid>.acf を読み込み
+func readSteamAppManifest(libraryPath, appID string) (*steamApp, error) {
+	data, err := os.ReadFile(filepath.Join(libraryPath, "steamapps", "appmanifest_"+appID+".acf"))
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parseVDF(data)
+	if err != nil {
+		return nil, err
+	}
+
+	state := root.Get("AppState")
+	if state == nil || state.String("installdir") == "" {
+		return nil, fmt.Errorf("appmanifest_%s.acf に installdir がありません", appID)
+	}
+
+	return &steamApp{
+		AppID:      appID,
+		Name:       state.String("name"),
+		InstallDir: state.String("installdir"),
+		BuildID:    state.String("buildid"),
+		Library:    libraryPath,
+	}, nil
+}
+
+// findSteamApp アプリIDのインストール先をSteamライブラリから検索
+//
+// libraryfolders.vdf の apps にアプリIDを持つライブラリを優先し、
+// 見つからない場合は全ライブラリの appmanifest を確認する。
+func findSteamApp(libraries []steamLibrary, appID int) (*steamApp, error) {
+	id := strconv.Itoa(appID)
+
+	for _, preferred := range []bool{true, false} {
+		for _, library := range libraries {
+			if library.Apps[id] != preferred {
+				continue
+			}
+			if app, err := readSteamAppManifest(library.Path, id); err == nil {
+				return app, nil
+			}
+		}
+	}
+
+	return nil, notFoundf("Steamライブラリにアプリ %s が見つかりません", id)
+}
+
+// steamAppForPath インストールフォルダを含むSteamライブラリの appmanifest を検索
+func steamAppForPath(dbRoot string, appID int) *steamApp {
+	// <library>/steamapps/common/<installdir>/... から steamapps を探す
+	dir := dbRoot
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		if filepath.Base(parent) == "common" && filepath.Base(filepath.Dir(parent)) == "steamapps" {
+			app, err := readSteamAppManifest(filepath.Dir(filepath.Dir(parent)), strconv.Itoa(appID))
+			if err != nil || app.InstallDir != filepath.Base(dir) {
+				return nil
+			}
+			return app
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// readFixture testdata のファイルを読み込み
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// writeSteamLibrary ライブラリに testdata の appmanifest を配置し、ゲームのDBフォルダを作成
+func writeSteamLibrary(t *testing.T, library string) {
+	t.Helper()
+	writeTree(t, library, map[string]string{
+		"steamapps/appmanifest_2252570.acf":                             readFixture(t, "steam/appmanifest_2252570.acf"),
+		"steamapps/common/Football Manager 2024/data/database/db/2400/": "",
+	})
+}
+
+func TestSteamFixtures(t *testing.T) {
+	libraries, err := parseLibraryFolders([]byte(readFixture(t, "steam/libraryfolders.vdf")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []steamLibrary{
+		{Path: "/home/deck/.local/share/Steam", Apps: map[string]bool{"228980": true, "1070560": true}},
+		{Path: "/run/media/mmcblk0p1", Apps: map[string]bool{"2252570": true}},
+	}
+	if !reflect.DeepEqual(libraries, want) {
+		t.Errorf("parseLibraryFolders() = %+v, want %+v", libraries, want)
+	}
+
+	library := t.TempDir()
+	writeSteamLibrary(t, library)
+	app, err := readSteamAppManifest(library, "2252570")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantApp := &steamApp{AppID: "2252570", Name: "Football Manager 2024", InstallDir: "Football Manager 2024", BuildID: "13265472", Library: library}
+	if !reflect.DeepEqual(app, wantApp) {
+		t.Errorf("readSteamAppManifest() = %+v, want %+v", app, wantApp)
+	}
+	if want := filepath.Join(library, "steamapps", "common", "Football Manager 2024"); app.Path() != want {
+		t.Errorf("Path() = %s, want %s", app.Path(), want)
+	}
+}
+
+func TestReadSteamAppManifestErrors(t *testing.T) {
+	library := t.TempDir()
+	writeTree(t, library, map[string]string{
+		"steamapps/appmanifest_1.acf": `"AppState" { "appid" "1" "name" "No Dir" }`,
+		"steamapps/appmanifest_2.acf": `"AppState" {`,
+	})
+
+	for _, appID := range []string{"1", "2", "3"} {
+		if app, err := readSteamAppManifest(library, appID); err == nil {
+			t.Errorf("readSteamAppManifest(%s) = %+v", appID, app)
+		}
+	}
+}
+
+func TestFindSteamApp(t *testing.T) {
+	listed := t.TempDir()   // libraryfolders.vdf の apps にあり appmanifest もある
+	unlisted := t.TempDir() // appmanifest のみ
+	stale := t.TempDir()    // apps にあるが appmanifest がない（アンインストール済み）
+	writeSteamLibrary(t, listed)
+	writeSteamLibrary(t, unlisted)
+
+	tests := []struct {
+		name      string
+		libraries []steamLibrary
+		want      string
+	}{
+		{
+			name: "apps にあるライブラリを優先",
+			libraries: []steamLibrary{
+				{Path: unlisted, Apps: map[string]bool{}},
+				{Path: listed, Apps: map[string]bool{"2252570": true}},
+			},
+			want: listed,
+		},
+		{
+			name: "apps にあっても appmanifest がなければ他のライブラリ",
+			libraries: []steamLibrary{
+				{Path: stale, Apps: map[string]bool{"2252570": true}},
+				{Path: unlisted, Apps: map[string]bool{}},
+			},
+			want: unlisted,
+		},
+		{
+			name:      "見つからない",
+			libraries: []steamLibrary{{Path: stale, Apps: map[string]bool{"2252570": true}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := findSteamApp(tt.libraries, 2252570)
+			if tt.want == "" {
+				var notFound *NotFoundError
+				if !errors.As(err, &notFound) {
+					t.Fatalf("findSteamApp() = %+v, %v; want NotFoundError", app, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if app.Library != tt.want {
+				t.Errorf("Library = %s, want %s", app.Library, tt.want)
+			}
+		})
+	}
+}
+
+func TestSteamAppForPath(t *testing.T) {
+	library := t.TempDir()
+	writeSteamLibrary(t, library)
+	writeTree(t, library, map[string]string{"steamapps/common/Other Game/data/database/db/2400/": ""})
+	common := filepath.Join(library, "steamapps", "common")
+
+	tests := []struct {
+		name  string
+		path  string
+		found bool
+	}{
+		{"DBフォルダ", filepath.Join(common, "Football Manager 2024", "data", "database", "db"), true},
+		{"インストールフォルダ", filepath.Join(common, "Football Manager 2024"), true},
+		{"installdir が異なる", filepath.Join(common, "Other Game", "data", "database", "db"), false},
+		{"Steamライブラリの外", filepath.Join(t.TempDir(), "Football Manager 2024", "data", "database", "db"), false},
+	}
+
+	for _, tt := range tests {
+		app := steamAppForPath(tt.path, 2252570)
+		if (app != nil) != tt.found {
+			t.Errorf("%s: steamAppForPath() = %+v, want found=%v", tt.name, app, tt.found)
+		}
+		if app != nil && app.BuildID != "13265472" {
+			t.Errorf("%s: BuildID = %s", tt.name, app.BuildID)
+		}
+	}
+}
+
+// writeLinuxSteam ネイティブ版Steam（~/.steam/steam は ~/.local/share/Steam へのリンク）と
+// SDカードのライブラリを作成し、testdata の libraryfolders.vdf のパスを置き換えて配置
+func writeLinuxSteam(t *testing.T, home, steamRoot, sdcard string) {
+	t.Helper()
+	vdf := readFixture(t, "steam/libraryfolders.vdf")
+	vdf = strings.ReplaceAll(vdf, "/home/deck/.local/share/Steam", filepath.Join(home, steamRoot))
+	vdf = strings.ReplaceAll(vdf, "/run/media/mmcblk0p1", sdcard)
+	writeTree(t, home, map[string]string{
+		steamRoot + "/steamapps/libraryfolders.vdf": vdf,
+		steamRoot + "/steamapps/common/":            "",
+	})
+	writeSteamLibrary(t, sdcard)
+}
+
+func TestFindSteamLibrariesLinux(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Linux のみ")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sdcard := t.TempDir()
+
+	writeLinuxSteam(t, home, ".local/share/Steam", sdcard)
+	if err := os.MkdirAll(filepath.Join(home, ".steam"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(home, ".local/share/Steam"), filepath.Join(home, ".steam", "steam")); err != nil {
+		t.Fatal(err)
+	}
+
+	// リンク先が同じライブラリは1つにまとめ、apps を統合する
+	want := []steamLibrary{
+		{Path: filepath.Join(home, ".steam/steam"), Apps: map[string]bool{"228980": true, "1070560": true}},
+		{Path: sdcard, Apps: map[string]bool{"2252570": true}},
+	}
+	if got := findSteamLibraries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("findSteamLibraries() = %+v, want %+v", got, want)
+	}
+}
+
+func TestScanForInstallationSteamLinux(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Linux のみ")
+	}
+
+	for _, steamRoot := range []string{".local/share/Steam", ".var/app/com.valvesoftware.Steam/.local/share/Steam"} {
+		t.Run(steamRoot, func(t *testing.T) {
+			tool := newTestTool(t)
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			sdcard := t.TempDir()
+			writeLinuxSteam(t, home, steamRoot, sdcard)
+
+			install, err := tool.scanForInstallation()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := &InstallInfo{
+				Path:    filepath.Join(sdcard, "steamapps", "common", "Football Manager 2024", "data", "database", "db"),
+				Source:  "scan",
+				Store:   "steam",
+				BuildID: "13265472",
+			}
+			if !reflect.DeepEqual(install, want) {
+				t.Errorf("scanForInstallation() = %+v, want %+v", install, want)
+			}
+		})
+	}
+}
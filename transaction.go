@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// stagingDirPrefix 削除前にファイルを退避するステージングディレクトリの接頭辞
+const stagingDirPrefix = ".fm24-real-staging-"
+
+// stagingMarkerName ステージングディレクトリ直下の確定済みマーカー（内容はバックアップの保存場所）
+const stagingMarkerName = ".fm24-real-committed"
+
+// stagedPath ステージング済みのパス
+type stagedPath struct {
+	original string
+	staged   string
+}
+
+// removalTransaction 全件成功か全件ロールバックかの削除処理
+//
+// 削除対象はまず同じファイルシステム上のステージングディレクトリへ移動し、
+// 全件の移動とバックアップの確定が成功した場合にのみステージングディレクトリを削除する。
+// バックアップの確定前に確定済みマーカーを書き込み、ステージングディレクトリの削除前に
+// 中断された場合でも、次回の実行で確定済みの削除を元に戻さないようにする。
+type removalTransaction struct {
+	basePath   string
+	stagingDir string
+	staged     []stagedPath
+}
+
+// newRemovalTransaction DBパス直下にステージングディレクトリを作成
+func newRemovalTransaction(basePath string) (*removalTransaction, error) {
+	stagingDir := filepath.Join(basePath, stagingDirPrefix+time.Now().Format(backupTimestampFormat))
+	if err := os.MkdirAll(stagingDir, 0755); err != nil {
+		return nil, fmt.Errorf("ステージングディレクトリ作成エラー: %w", err)
+	}
+	return &removalTransaction{basePath: basePath, stagingDir: stagingDir}, nil
+}
+
+// stage パスをステージングディレクトリへ移動
+func (tx *removalTransaction) stage(fullPath string) error {
+	relPath, err := filepath.Rel(tx.basePath, fullPath)
+	if err != nil {
+		return err
+	}
+
+	stagedPathName := filepath.Join(tx.stagingDir, relPath)
+	if err := os.MkdirAll(filepath.Dir(stagedPathName), 0755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(fullPath, stagedPathName); err != nil {
+		return err
+	}
+
+	tx.staged = append(tx.staged, stagedPath{original: fullPath, staged: stagedPathName})
+	return nil
+}
+
+// rollback ステージング済みのパスを全て元の場所に戻す
+func (tx *removalTransaction) rollback() error {
+	var failed []string
+	for i := len(tx.staged) - 1; i >= 0; i-- {
+		item := tx.staged[i]
+		if err := os.Rename(item.staged, item.original); err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", item.original, err))
+		}
+	}
+	tx.staged = nil
+
+	if len(failed) > 0 {
+		return fmt.Errorf("ロールバックに失敗しました。%s から手動で戻してください: %s", tx.stagingDir, strings.Join(failed, ", "))
+	}
+
+	return os.RemoveAll(tx.stagingDir)
+}
+
+// markCommitted 確定済みマーカーを書き込む（バックアップが無効の場合、保存場所は空）
+func (tx *removalTransaction) markCommitted(backupLocation string) error {
+	if err := os.WriteFile(filepath.Join(tx.stagingDir, stagingMarkerName), []byte(backupLocation), 0644); err != nil {
+		return fmt.Errorf("確定済みマーカー書き込みエラー: %w", err)
+	}
+	return nil
+}
+
+// commit ステージングディレクトリを削除して確定
+func (tx *removalTransaction) commit() error {
+	if err := os.RemoveAll(tx.stagingDir); err != nil {
+		return fmt.Errorf("ステージングディレクトリ削除エラー: %w", err)
+	}
+	return nil
+}
+
+// isCommitted 確定済みマーカーがあり、記録されたバックアップが確定しているか
+//
+// マーカーの書き込み後、バックアップの確定前に中断された場合は未確定として扱う。
+func (tx *removalTransaction) isCommitted() (bool, error) {
+	data, err := os.ReadFile(filepath.Join(tx.stagingDir, stagingMarkerName))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(data) == 0 {
+		return true, nil
+	}
+	return snapshotCommitted(string(data)), nil
+}
+
+// snapshotCommitted 保存場所のスナップショットが確定しているか（ディレクトリ形式はマニフェストの有無）
+func snapshotCommitted(location string) bool {
+	info, err := os.Stat(location)
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		_, err := os.Stat(filepath.Join(location, manifestFileName))
+		return err == nil
+	}
+	return true
+}
+
+// recoverStaging 前回中断された処理のステージングディレクトリを元に戻す
+func (t *FM24Tool) recoverStaging() error {
+	entries, err := os.ReadDir(t.DBBasePath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), stagingDirPrefix) {
+			continue
+		}
+
+		stagingDir := filepath.Join(t.DBBasePath, entry.Name())
+		tx := &removalTransaction{basePath: t.DBBasePath, stagingDir: stagingDir}
+
+		committed, err := tx.isCommitted()
+		if err != nil {
+			return fmt.Errorf("ステージングディレクトリの確認エラー (%s): %w", stagingDir, err)
+		}
+		if committed {
+			// 前回の処理は確定済み（ステージングディレクトリの削除前に中断された）
+			color.Yellow("  ⚠️  前回の処理で残ったステージングディレクトリを削除します: %s", stagingDir)
+			if err := tx.commit(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		color.Yellow("  ⚠️  中断された処理を検出しました。ロールバックします: %s", stagingDir)
+		if err := tx.collectStaged(); err != nil {
+			return fmt.Errorf("ステージングディレクトリの復旧エラー (%s): %w", stagingDir, err)
+		}
+
+		if err := tx.rollback(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// collectStaged ステージングディレクトリ内のパスを元に戻す対象として登録
+//
+// 元の場所に存在しないパスはディレクトリごと（空のディレクトリを含む）戻す。
+// 元の場所にディレクトリがある場合は、ステージング時に作成した親ディレクトリとみなして中を調べる。
+func (tx *removalTransaction) collectStaged() error {
+	return filepath.Walk(tx.stagingDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == tx.stagingDir || path == filepath.Join(tx.stagingDir, stagingMarkerName) {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(tx.stagingDir, path)
+		if err != nil {
+			return err
+		}
+		original := filepath.Join(tx.basePath, relPath)
+
+		if current, err := os.Lstat(original); err == nil {
+			if info.IsDir() && current.IsDir() {
+				return nil
+			}
+			return fmt.Errorf("元の場所に既にファイルがあります: %s", original)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(original), 0755); err != nil {
+			return err
+		}
+		tx.staged = append(tx.staged, stagedPath{original: original, staged: path})
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
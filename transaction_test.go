@@ -0,0 +1,336 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTree テスト用のファイルを作成（末尾が / のパスは空のディレクトリ）
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for relPath, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(relPath))
+		if strings.HasSuffix(relPath, "/") {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readTree ディレクトリ以下の全パスの種別・パーミッション・内容
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	tree := make(map[string]string)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			tree[filepath.ToSlash(relPath)+"/"] = info.Mode().String()
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		tree[filepath.ToSlash(relPath)] = fmt.Sprintf("%s %q", info.Mode(), data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// testDBFiles 実名化対象を含むDBバージョンフォルダ
+var testDBFiles = map[string]string{
+	"lnc/all/a.lnc":                     "lnc a",
+	"lnc/all/sub/b.lnc":                 "lnc b",
+	"lnc/all/empty/":                    "",
+	"edt/permanent/fake.edt":            "fake",
+	"dbc/permanent/license.dbc":         "license",
+	"dbc/permanent/forbidden names.dbc": "forbidden",
+	"dbc/permanent/japan_clubs.dbc":     "japan",
+	"dbc/permanent/keep.dbc":            "keep",
+	"language/Licensing2.dbc":           "licensing2",
+}
+
+// newTestTool テスト用のDBフォルダ（バージョン 2400）に対するツール
+func newTestTool(t *testing.T) *FM24Tool {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	game, err := findGame("fm24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := &FM24Tool{Config: &Config{}, Game: game, Rules: game.defaultRules()}
+	tool.DBRoot = t.TempDir()
+	tool.DBVersions = []string{"2400"}
+	tool.useDBVersion("2400")
+	writeTree(t, tool.DBBasePath, testDBFiles)
+	return tool
+}
+
+func TestRemovalTransactionRollback(t *testing.T) {
+	tool := newTestTool(t)
+	before := readTree(t, tool.DBBasePath)
+
+	tx, err := newRemovalTransaction(tool.DBBasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, relPath := range []string{"lnc/all/a.lnc", "lnc/all/sub", "lnc/all/empty", "dbc/permanent/license.dbc"} {
+		if err := tx.stage(filepath.Join(tool.DBBasePath, filepath.FromSlash(relPath))); err != nil {
+			t.Fatalf("stage(%s): %v", relPath, err)
+		}
+	}
+
+	// 途中で失敗（存在しないパス）
+	if err := tx.stage(filepath.Join(tool.DBBasePath, "dbc", "permanent", "missing.dbc")); err == nil {
+		t.Fatal("存在しないパスの stage が成功しました")
+	}
+	if _, err := os.Stat(filepath.Join(tool.DBBasePath, "dbc", "permanent", "license.dbc")); !os.IsNotExist(err) {
+		t.Fatal("ステージング済みのファイルが元の場所に残っています")
+	}
+
+	if err := tx.rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if after := readTree(t, tool.DBBasePath); !reflect.DeepEqual(before, after) {
+		t.Errorf("ロールバック後の状態が異なります\nbefore: %v\nafter:  %v", before, after)
+	}
+}
+
+func TestRemovalTransactionCommit(t *testing.T) {
+	tool := newTestTool(t)
+
+	tx, err := newRemovalTransaction(tool.DBBasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.stage(filepath.Join(tool.DBBasePath, "edt", "permanent", "fake.edt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	tree := readTree(t, tool.DBBasePath)
+	if _, ok := tree["edt/permanent/fake.edt"]; ok {
+		t.Error("確定後もファイルが残っています")
+	}
+	for path := range tree {
+		if strings.Contains(path, stagingDirPrefix) {
+			t.Errorf("ステージングディレクトリが残っています: %s", path)
+		}
+	}
+}
+
+// failingSnapshotWriter バックアップの確定で失敗するスナップショット
+type failingSnapshotWriter struct {
+	added   []string
+	aborted bool
+}
+
+func (w *failingSnapshotWriter) AddFile(relPath, srcPath string, info os.FileInfo) (int64, string, error) {
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return 0, "", err
+	}
+	w.added = append(w.added, relPath)
+	return int64(len(data)), sha256Hex(data), nil
+}
+
+func (w *failingSnapshotWriter) Commit(manifest *BackupManifest) error {
+	return errors.New("disk full")
+}
+
+func (w *failingSnapshotWriter) Abort() error {
+	w.aborted = true
+	return nil
+}
+
+func (w *failingSnapshotWriter) Location() string {
+	return "failing"
+}
+
+func TestExecuteRealNameProcessRollsBackOnFailure(t *testing.T) {
+	tool := newTestTool(t)
+	before := readTree(t, tool.DBBasePath)
+
+	writer := &failingSnapshotWriter{}
+	tool.snapshot = writer
+	tool.manifest = tool.newBackupManifest()
+
+	if _, err := tool.executeRealNameProcess(); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("executeRealNameProcess() = %v, want disk full", err)
+	}
+	if len(writer.added) == 0 {
+		t.Error("バックアップが行われていません")
+	}
+	if !writer.aborted {
+		t.Error("バックアップが破棄されていません")
+	}
+	if after := readTree(t, tool.DBBasePath); !reflect.DeepEqual(before, after) {
+		t.Errorf("ロールバック後の状態が異なります\nbefore: %v\nafter:  %v", before, after)
+	}
+}
+
+func TestRecoverStaging(t *testing.T) {
+	tool := newTestTool(t)
+	before := readTree(t, tool.DBBasePath)
+
+	// ステージング後に中断された状態（空のディレクトリを含む）
+	tx, err := newRemovalTransaction(tool.DBBasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, relPath := range []string{"lnc/all/a.lnc", "lnc/all/sub", "lnc/all/empty", "dbc/permanent/forbidden names.dbc", "language/Licensing2.dbc"} {
+		if err := tx.stage(filepath.Join(tool.DBBasePath, filepath.FromSlash(relPath))); err != nil {
+			t.Fatalf("stage(%s): %v", relPath, err)
+		}
+	}
+
+	if err := tool.recoverStaging(); err != nil {
+		t.Fatal(err)
+	}
+	if after := readTree(t, tool.DBBasePath); !reflect.DeepEqual(before, after) {
+		t.Errorf("復旧後の状態が異なります\nbefore: %v\nafter:  %v", before, after)
+	}
+}
+
+func TestRecoverStagingConflict(t *testing.T) {
+	tool := newTestTool(t)
+
+	tx, err := newRemovalTransaction(tool.DBBasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	license := filepath.Join(tool.DBBasePath, "dbc", "permanent", "license.dbc")
+	if err := tx.stage(license); err != nil {
+		t.Fatal(err)
+	}
+	// 中断後にアップデート等でファイルが復活した
+	if err := os.WriteFile(license, []byte("updated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tool.recoverStaging(); err == nil {
+		t.Fatal("元の場所にファイルがある場合にエラーになりません")
+	}
+	if data, _ := os.ReadFile(license); string(data) != "updated" {
+		t.Errorf("元の場所のファイルが上書きされました: %q", data)
+	}
+}
+
+func TestRecoverStagingCommitted(t *testing.T) {
+	tests := []struct {
+		name         string
+		backup       bool // 確定済みのバックアップを記録
+		commitBackup bool
+		wantRestored bool
+	}{
+		{name: "バックアップなしで確定済み", wantRestored: false},
+		{name: "バックアップ確定済み", backup: true, commitBackup: true, wantRestored: false},
+		{name: "バックアップ確定前に中断", backup: true, commitBackup: false, wantRestored: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := newTestTool(t)
+			before := readTree(t, tool.DBBasePath)
+
+			tx, err := newRemovalTransaction(tool.DBBasePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, relPath := range []string{"lnc/all/a.lnc", "dbc/permanent/license.dbc"} {
+				if err := tx.stage(filepath.Join(tool.DBBasePath, filepath.FromSlash(relPath))); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			location := ""
+			if tt.backup {
+				writer, err := newSnapshotWriter(t.TempDir(), "20240118_143022", BackupFormatDir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				location = writer.Location()
+				if tt.commitBackup {
+					if err := writer.Commit(&BackupManifest{}); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			// マーカー書き込み後、ステージングディレクトリの削除前に中断された
+			if err := tx.markCommitted(location); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tool.recoverStaging(); err != nil {
+				t.Fatal(err)
+			}
+
+			after := readTree(t, tool.DBBasePath)
+			if tt.wantRestored {
+				if !reflect.DeepEqual(before, after) {
+					t.Errorf("復旧後の状態が異なります\nbefore: %v\nafter:  %v", before, after)
+				}
+				return
+			}
+			for _, relPath := range []string{"lnc/all/a.lnc", "dbc/permanent/license.dbc"} {
+				if _, ok := after[relPath]; ok {
+					t.Errorf("確定済みの削除が元に戻されました: %s", relPath)
+				}
+			}
+			for path := range after {
+				if strings.Contains(path, stagingDirPrefix) {
+					t.Errorf("ステージングディレクトリが残っています: %s", path)
+				}
+			}
+		})
+	}
+}
+
+func TestRemovalTransactionCommitError(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("root ではパーミッションによる削除の失敗を再現できません")
+	}
+	tool := newTestTool(t)
+
+	tx, err := newRemovalTransaction(tool.DBBasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.stage(filepath.Join(tool.DBBasePath, "lnc", "all", "sub")); err != nil {
+		t.Fatal(err)
+	}
+	locked := filepath.Join(tx.stagingDir, "lnc", "all", "sub")
+	if err := os.Chmod(locked, 0555); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(locked, 0755)
+
+	if err := tx.commit(); err == nil {
+		t.Error("ステージングディレクトリの削除に失敗してもエラーになりません")
+	}
+}
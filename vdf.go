@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// vdfNode Valve KeyValues（VDF/ACF）のノード
+//
+// 値を持つノード（"key" "value"）と子ノードを持つノード（"key" { ... }）がある。
+type vdfNode struct {
+	Key      string
+	Value    string
+	Children []*vdfNode
+}
+
+// Get キーに一致する子ノード（大文字小文字を区別しない、存在しない場合はnil）
+func (n *vdfNode) Get(key string) *vdfNode {
+	if n == nil {
+		return nil
+	}
+	for _, child := range n.Children {
+		if strings.EqualFold(child.Key, key) {
+			return child
+		}
+	}
+	return nil
+}
+
+// String キーに一致する子ノードの値（存在しない場合は空文字）
+func (n *vdfNode) String(key string) string {
+	if child := n.Get(key); child != nil {
+		return child.Value
+	}
+	return ""
+}
+
+// isBlock 子ノードを持つブロックか
+func (n *vdfNode) isBlock() bool {
+	return n.Children != nil
+}
+
+// vdfParser KeyValues テキスト形式のパーサー
+type vdfParser struct {
+	data []byte
+	pos  int
+	line int
+}
+
+// vdfToken 字句
+type vdfToken struct {
+	text   string
+	quoted bool
+	line   int
+}
+
+// parseVDF KeyValues テキストを解析し、トップレベルのノードを子に持つルートノードを返す
+func parseVDF(data []byte) (*vdfNode, error) {
+	// UTF-8 BOM
+	data = []byte(strings.TrimPrefix(string(data), "\xef\xbb\xbf"))
+
+	p := &vdfParser{data: data, line: 1}
+	root := &vdfNode{Children: []*vdfNode{}}
+	if err := p.parseBlock(root, false); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// parseBlock "}"（トップレベルの場合は終端）まで子ノードを読み込む
+func (p *vdfParser) parseBlock(parent *vdfNode, nested bool) error {
+	for {
+		key, ok, err := p.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if nested {
+				return fmt.Errorf("VDF解析エラー: %d行目: '}' がありません", p.line)
+			}
+			return nil
+		}
+		if !key.quoted && key.text == "}" {
+			if !nested {
+				return fmt.Errorf("VDF解析エラー: %d行目: 対応しない '}'", key.line)
+			}
+			return nil
+		}
+		if !key.quoted && key.text == "{" {
+			return fmt.Errorf("VDF解析エラー: %d行目: キーがありません", key.line)
+		}
+
+		value, ok, err := p.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("VDF解析エラー: %d行目: %q の値がありません", key.line, key.text)
+		}
+
+		// 条件（例: [$WIN32]）は無視する
+		if !value.quoted && strings.HasPrefix(value.text, "[") {
+			if value, ok, err = p.next(); err != nil || !ok {
+				return fmt.Errorf("VDF解析エラー: %d行目: %q の値がありません", key.line, key.text)
+			}
+		}
+
+		node := &vdfNode{Key: key.text}
+		switch {
+		case !value.quoted && value.text == "{":
+			node.Children = []*vdfNode{}
+			if err := p.parseBlock(node, true); err != nil {
+				return err
+			}
+		case !value.quoted && value.text == "}":
+			return fmt.Errorf("VDF解析エラー: %d行目: %q の値がありません", value.line, key.text)
+		default:
+			node.Value = value.text
+		}
+		parent.Children = append(parent.Children, node)
+
+		p.skipCondition()
+	}
+}
+
+// skipCondition 値の後ろの条件（例: "key" "value" [$OSX]）を読み飛ばす
+func (p *vdfParser) skipCondition() {
+	p.skipSpace()
+	if p.pos < len(p.data) && p.data[p.pos] == '[' {
+		for p.pos < len(p.data) && p.data[p.pos] != ']' && p.data[p.pos] != '\n' {
+			p.pos++
+		}
+		if p.pos < len(p.data) && p.data[p.pos] == ']' {
+			p.pos++
+		}
+	}
+}
+
+// skipSpace 空白とコメント（// から行末まで）を読み飛ばす
+func (p *vdfParser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '/':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// next 次の字句を読み込む（終端の場合は ok=false）
+func (p *vdfParser) next() (vdfToken, bool, error) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return vdfToken{}, false, nil
+	}
+
+	line := p.line
+	c := p.data[p.pos]
+
+	switch c {
+	case '{', '}':
+		p.pos++
+		return vdfToken{text: string(c), line: line}, true, nil
+
+	case '"':
+		p.pos++
+		var b strings.Builder
+		for p.pos < len(p.data) {
+			c := p.data[p.pos]
+			switch {
+			case c == '"':
+				p.pos++
+				return vdfToken{text: b.String(), quoted: true, line: line}, true, nil
+			case c == '\\' && p.pos+1 < len(p.data):
+				p.pos += 2
+				switch esc := p.data[p.pos-1]; esc {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case '\\', '"':
+					b.WriteByte(esc)
+				default:
+					b.WriteByte('\\')
+					b.WriteByte(esc)
+				}
+			default:
+				if c == '\n' {
+					p.line++
+				}
+				b.WriteByte(c)
+				p.pos++
+			}
+		}
+		return vdfToken{}, false, fmt.Errorf("VDF解析エラー: %d行目: 文字列が閉じられていません", line)
+
+	default:
+		// 引用符なしの字句
+		start := p.pos
+		for p.pos < len(p.data) {
+			c := p.data[p.pos]
+			if c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '"' || c == '{' || c == '}' {
+				break
+			}
+			p.pos++
+		}
+		return vdfToken{text: string(p.data[start:p.pos]), line: line}, true, nil
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// formatVDF ノードを比較用の1行に変換（"key"="value" / "key"{...}）
+func formatVDF(n *vdfNode) string {
+	var b strings.Builder
+	for _, child := range n.Children {
+		if child.isBlock() {
+			fmt.Fprintf(&b, `"%s"{%s}`, child.Key, formatVDF(child))
+		} else {
+			fmt.Fprintf(&b, `"%s"="%s"`, child.Key, child.Value)
+		}
+	}
+	return b.String()
+}
+
+func TestParseVDF(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "空",
+			input: "",
+			want:  "",
+		},
+		{
+			name:  "値とブロック",
+			input: "\"AppState\"\n{\n\t\"appid\"\t\t\"2252570\"\n\t\"UserConfig\"\n\t{\n\t}\n}\n",
+			want:  `"AppState"{"appid"="2252570""UserConfig"{}}`,
+		},
+		{
+			name:  "エスケープ",
+			input: `"path" "D:\\SteamLibrary" "name" "say \"hi\"\n" "raw" "a\qb"`,
+			want:  `"path"="D:\SteamLibrary""name"="say "hi"` + "\n" + `""raw"="a\qb"`,
+		},
+		{
+			name:  "コメントと条件",
+			input: "// header\n\"a\" \"1\" [$WIN32] // trailing\n\"b\" [$OSX] \"2\"\n",
+			want:  `"a"="1""b"="2"`,
+		},
+		{
+			name:  "引用符なし",
+			input: "libraryfolders {\n contentstatsid -123\n}",
+			want:  `"libraryfolders"{"contentstatsid"="-123"}`,
+		},
+		{
+			name:  "BOM と CRLF",
+			input: "\xef\xbb\xbf\"a\"\r\n{\r\n\"b\" \"c\"\r\n}\r\n",
+			want:  `"a"{"b"="c"}`,
+		},
+		{
+			name:    "複数行の文字列",
+			input:   "\"a\" \"1\n2\"\n\"b\" {",
+			wantErr: "3行目: '}' がありません",
+		},
+		{
+			name:    "閉じられていない文字列",
+			input:   "\"a\"\n\"b",
+			wantErr: "2行目: 文字列が閉じられていません",
+		},
+		{
+			name:    "対応しない閉じ括弧",
+			input:   "\"a\" \"1\"\n}",
+			wantErr: "2行目: 対応しない '}'",
+		},
+		{
+			name:    "値がない",
+			input:   "\"a\" {\n\"b\"\n}",
+			wantErr: `3行目: "b" の値がありません`,
+		},
+		{
+			name:    "キーがない",
+			input:   "{\n}",
+			wantErr: "1行目: キーがありません",
+		},
+		{
+			name:    "終端で値がない",
+			input:   "\"a\"",
+			wantErr: `1行目: "a" の値がありません`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseVDF([]byte(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseVDF() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := formatVDF(root); got != tt.want {
+				t.Errorf("parseVDF() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVDFNodeGet(t *testing.T) {
+	root, err := parseVDF([]byte(`"AppState" { "InstallDir" "Football Manager 2024" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := root.Get("appstate")
+	if got := state.String("installdir"); got != "Football Manager 2024" {
+		t.Errorf("String(installdir) = %q", got)
+	}
+	if got := root.Get("missing").Get("child").String("key"); got != "" {
+		t.Errorf("存在しないノードの値 = %q", got)
+	}
+}
+
+func TestParseLibraryFolders(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []steamLibrary
+		wantErr bool
+	}{
+		{
+			name: "新形式",
+			input: `"libraryfolders"
+{
+	"contentstatsid"		"-4238913744396932183"
+	"0"
+	{
+		"path"		"/home/deck/.local/share/Steam"
+		"label"		""
+		"apps"
+		{
+			"228980"		"423647935"
+		}
+	}
+	"1"
+	{
+		"path"		"/run/media/mmcblk0p1"
+		"apps"
+		{
+			"2252570"		"3410846211"
+			"1904540"		"2871235321"
+		}
+	}
+}`,
+			want: []steamLibrary{
+				{Path: "/home/deck/.local/share/Steam", Apps: map[string]bool{"228980": true}},
+				{Path: "/run/media/mmcblk0p1", Apps: map[string]bool{"2252570": true, "1904540": true}},
+			},
+		},
+		{
+			name: "旧形式",
+			input: `"LibraryFolders"
+{
+	"TimeNextStatsReport"		"1700000000"
+	"ContentStatsID"		"-4238913744396932183"
+	"1"		"D:\\SteamLibrary"
+}`,
+			want: []steamLibrary{{Path: `D:\SteamLibrary`, Apps: map[string]bool{}}},
+		},
+		{
+			name:  "パスのないライブラリ",
+			input: `"libraryfolders" { "0" { "label" "empty" } }`,
+		},
+		{
+			name:    "libraryfolders がない",
+			input:   `"AppState" { "appid" "2252570" }`,
+			wantErr: true,
+		},
+		{
+			name:    "解析エラー",
+			input:   `"libraryfolders" {`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLibraryFolders([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLibraryFolders() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLibraryFolders() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
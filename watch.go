@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// 監視のデフォルト値
+const (
+	defaultQuietPeriod  = 60 * time.Second
+	defaultPollInterval = 30 * time.Second
+	gameExitRetryPeriod = 10 * time.Second // ゲームの実行中に再適用を見送った場合の再確認の間隔
+)
+
+// errWatchUnsupported ファイルシステムの変更通知に対応していないOS
+var errWatchUnsupported = errors.New("このOSではファイルの変更通知に対応していません")
+
+// fileWatcher ファイルシステムの変更通知
+type fileWatcher interface {
+	// Add ディレクトリを監視対象に追加（サブディレクトリは含まない）
+	Add(dir string) error
+	// Events 作成・書き込み・移動されたパス（通知が溢れた場合は空文字列）
+	Events() <-chan string
+	Close() error
+}
+
+// quietPeriod 対象ファイルの復活後、再適用まで待つ変更のない時間
+func (w WatchConfig) quietPeriod() time.Duration {
+	if w.QuietPeriod > 0 {
+		return w.QuietPeriod
+	}
+	return defaultQuietPeriod
+}
+
+// pollInterval ポーリングで監視する場合の確認間隔
+func (w WatchConfig) pollInterval() time.Duration {
+	if w.PollInterval > 0 {
+		return w.PollInterval
+	}
+	return defaultPollInterval
+}
+
+// Watch DBフォルダを監視し、ゲームのアップデートで対象ファイルが復活したら自動で再適用
+//
+// 変更を検出すると、アップデーターの書き込みが終わるまで静止期間（変更のない時間）を待ってから
+// 状態を確認し、対象ファイルがあれば確認なしで実名化処理（バックアップと削除）を実行する。
+// 新しいDBバージョンフォルダも処理対象の選択（--db-version, --all-db-versions）に従って反映する。
+func (t *FM24Tool) Watch(customPath string, settings WatchConfig) error {
+	color.Cyan("==========================================================")
+	color.Cyan("%s 監視モード", t.Game.Short)
+	color.Cyan("==========================================================\n")
+
+	if err := t.DetectInstallation(customPath); err != nil {
+		return err
+	}
+	t.printDetected()
+
+	// 監視中の再適用は確認しない（ゲームの実行中は監視を続けたまま終了後に再適用する）
+	t.AssumeYes = true
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var watcher fileWatcher
+	if !settings.Poll {
+		var err error
+		watcher, err = t.startWatcher()
+		if err != nil {
+			color.Yellow("⚠️  変更通知を使用できません: %v", err)
+		}
+	}
+
+	var events <-chan string
+	var poll <-chan time.Time
+	if watcher != nil {
+		defer watcher.Close()
+		events = watcher.Events()
+		logf("監視方法: 変更通知（%s）\n", t.DBRoot)
+	} else {
+		ticker := time.NewTicker(settings.pollInterval())
+		defer ticker.Stop()
+		poll = ticker.C
+		logf("監視方法: ポーリング（%s ごと、%s）\n", settings.pollInterval(), t.DBRoot)
+	}
+	logf("静止期間: %s\n", settings.quietPeriod())
+	logln("Ctrl+C で終了します")
+
+	quiet := newQuietTimer(settings.quietPeriod())
+	defer quiet.Stop()
+
+	// 起動時点で復活しているファイルがあれば再適用
+	waiting := !t.watchApply(false)
+	if waiting {
+		quiet.Retry(gameExitRetryPeriod)
+	}
+	drainEvents(events)
+	fingerprint := t.watchFingerprint()
+
+	// 変更を記録し、静止期間を待ち直す
+	changed := func(path string) {
+		if quiet.Change() && !waiting {
+			watchLogf("変更を検出: %s", path)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			logln()
+			watchLogf("監視を終了しました")
+			return nil
+
+		case path, ok := <-events:
+			if !ok {
+				return fmt.Errorf("ファイルの変更通知が停止しました")
+			}
+			if path == "" {
+				changed(t.DBRoot)
+				continue
+			}
+			if isStagingPath(path) {
+				continue
+			}
+			// 新しく作成されたディレクトリ（新しいDBバージョンフォルダ等）も監視する
+			if info, err := os.Stat(path); err == nil && info.IsDir() {
+				t.watchTree(watcher, path)
+			}
+			changed(path)
+
+		case <-poll:
+			current := t.watchFingerprint()
+			if current == fingerprint {
+				continue
+			}
+			fingerprint = current
+			changed(t.DBRoot)
+
+		case <-quiet.C():
+			changes := quiet.Fire()
+			if !waiting {
+				watchLogf("%s 変更がありません（変更 %d 件）。状態を確認します", settings.quietPeriod(), changes)
+			}
+
+			// ゲームの実行中は終了を待たずにループに戻り、変更通知を受け取り続ける
+			if waiting = !t.watchApply(waiting); waiting {
+				quiet.Retry(gameExitRetryPeriod)
+				continue
+			}
+			if watcher != nil {
+				t.watchTree(watcher, t.DBRoot)
+			}
+			// 自身の削除による変更は無視する
+			drainEvents(events)
+			fingerprint = t.watchFingerprint()
+		}
+	}
+}
+
+// startWatcher DBフォルダと処理対象のバージョンフォルダ以下の変更通知を開始
+func (t *FM24Tool) startWatcher() (fileWatcher, error) {
+	watcher, err := newFileWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	// 新しいバージョンフォルダの作成を検出するため、DBフォルダ全体を監視
+	if err := addWatchTree(watcher, t.DBRoot); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	return watcher, nil
+}
+
+// watchTree ディレクトリ以下を監視対象に追加（失敗した場合は警告のみ）
+func (t *FM24Tool) watchTree(watcher fileWatcher, dir string) {
+	if err := addWatchTree(watcher, dir); err != nil {
+		color.Yellow("⚠️  監視の追加に失敗しました: %v", err)
+	}
+}
+
+// addWatchTree ディレクトリとサブディレクトリを監視対象に追加（ステージングディレクトリは除く）
+func addWatchTree(watcher fileWatcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// 監視中に削除されたディレクトリは無視
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), stagingDirPrefix) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// watchApply 処理対象のバージョンに対象ファイルが復活していれば実名化処理を実行
+//
+// ゲームの実行中は再適用せずに false を返す（呼び出し側で時間をおいて再確認する）。
+// waiting は前回の確認でゲームの実行中だった場合で、同じメッセージを繰り返し表示しない。
+func (t *FM24Tool) watchApply(waiting bool) bool {
+	// 新しいバージョンフォルダを反映して処理対象を選び直す
+	if err := t.useDBRoot(t.DBRoot); err != nil {
+		color.Yellow("⚠️  %s", err)
+		return true
+	}
+
+	reports, err := t.inspectVersions()
+	if err != nil {
+		color.Yellow("⚠️  状態の確認に失敗しました: %v", err)
+		return true
+	}
+
+	// 対象ファイルが復活したバージョンのみ処理する
+	var versions []string
+	present := 0
+	for _, report := range reports {
+		if report.Present > 0 {
+			versions = append(versions, report.DBVersion)
+			present += report.Present
+		}
+	}
+	if len(versions) == 0 {
+		watchLogf("対象ファイルはありません（DB %s）", strings.Join(t.DBVersions, ", "))
+		return true
+	}
+
+	processes, err := findGameProcesses(t.Game, t.installRoot())
+	if err != nil {
+		color.Yellow("⚠️  %s が実行中か確認できません。確認せずに続行します: %v", t.Game.Short, err)
+	} else if len(processes) > 0 {
+		if !waiting {
+			color.Yellow("%s 対象ファイルが %d 件復活しています（DB %s）。%s が実行中のため、終了後に再適用します（%s）",
+				watchTimestamp(), present, strings.Join(versions, ", "), t.Game.Name, describeProcesses(processes))
+		}
+		return false
+	}
+
+	color.Yellow("%s 対象ファイルが %d 件復活しています（DB %s）。実名化を再適用します", watchTimestamp(), present, strings.Join(versions, ", "))
+	t.DBVersions = versions
+	if err := t.applyVersions(); err != nil {
+		color.Red("%s ❌ 再適用に失敗しました: %v", watchTimestamp(), err)
+		return true
+	}
+	watchLogf("再適用が完了しました")
+	return true
+}
+
+// watchFingerprint ポーリング用のDBフォルダの状態（ファイル数・合計サイズ・最終更新日時）
+func (t *FM24Tool) watchFingerprint() string {
+	var files int
+	var size int64
+	var latest time.Time
+	filepath.WalkDir(t.DBRoot, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if strings.HasPrefix(entry.Name(), stagingDirPrefix) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		files++
+		size += info.Size()
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return fmt.Sprintf("%d:%d:%d", files, size, latest.UnixNano())
+}
+
+// isStagingPath 実名化処理のステージングディレクトリ内のパスか
+func isStagingPath(path string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if strings.HasPrefix(part, stagingDirPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// drainEvents 溜まっている変更通知を読み捨てる
+func drainEvents(events <-chan string) {
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// quietTimer 最後の変更から静止期間が経過したら発火するタイマー
+type quietTimer struct {
+	period  time.Duration
+	timer   *time.Timer
+	changes int // 前回の発火以降の変更数
+}
+
+// newQuietTimer 停止した状態の静止期間タイマー
+func newQuietTimer(period time.Duration) *quietTimer {
+	timer := time.NewTimer(period)
+	timer.Stop()
+	return &quietTimer{period: period, timer: timer}
+}
+
+// C 静止期間の経過（または Retry の時間の経過）を通知するチャネル
+func (q *quietTimer) C() <-chan time.Time {
+	return q.timer.C
+}
+
+// Change 変更を記録して静止期間を待ち直す（前回の発火以降で最初の変更の場合は true）
+func (q *quietTimer) Change() bool {
+	q.changes++
+	resetTimer(q.timer, q.period)
+	return q.changes == 1
+}
+
+// Retry 変更を記録せずに指定時間後に発火させる
+func (q *quietTimer) Retry(d time.Duration) {
+	resetTimer(q.timer, d)
+}
+
+// Fire 発火を受け取った後に呼び出し、記録していた変更数を返してリセット
+func (q *quietTimer) Fire() int {
+	changes := q.changes
+	q.changes = 0
+	return changes
+}
+
+// Stop タイマーを停止
+func (q *quietTimer) Stop() {
+	q.timer.Stop()
+}
+
+// resetTimer タイマーを止めて指定時間後に再設定
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(d)
+}
+
+// watchTimestamp 監視ログの時刻
+func watchTimestamp() string {
+	return time.Now().Format("[2006-01-02 15:04:05]")
+}
+
+// watchLogf 時刻付きで監視ログを表示
+func watchLogf(format string, args ...interface{}) {
+	logf("%s %s\n", watchTimestamp(), fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,132 @@
+//go:build linux
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// inotifyMask 監視するイベント（アップデーターによるファイルの作成・書き込み・移動）
+const inotifyMask = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO
+
+// inotifyWatcher inotify による変更通知
+type inotifyWatcher struct {
+	fd     int
+	file   *os.File
+	events chan string
+
+	mu   sync.Mutex
+	dirs map[int32]string // ウォッチ記述子と監視中のディレクトリ
+
+	dropped bool // 通知が溢れて読み捨てたイベントがある（read のみが使用）
+}
+
+// newFileWatcher inotify による変更通知を開始
+func newFileWatcher() (fileWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify 初期化エラー: %w", err)
+	}
+
+	// 非ブロッキングの記述子はランタイムのポーラーで待機するため、Close で読み込みが終了する
+	// （File.Fd はブロッキングモードに戻すため使わない）
+	w := &inotifyWatcher{
+		fd:     fd,
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		events: make(chan string, 256),
+		dirs:   make(map[int32]string),
+	}
+	go w.read()
+	return w, nil
+}
+
+// Add ディレクトリを監視対象に追加
+func (w *inotifyWatcher) Add(dir string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wd, err := syscall.InotifyAddWatch(w.fd, dir, inotifyMask)
+	if err != nil {
+		if err == syscall.ENOSPC {
+			return fmt.Errorf("inotify の監視数が上限に達しました（fs.inotify.max_user_watches）: %s", dir)
+		}
+		return fmt.Errorf("inotify 監視エラー: %s: %w", dir, err)
+	}
+	w.dirs[int32(wd)] = dir
+	return nil
+}
+
+// Events 変更されたパス
+func (w *inotifyWatcher) Events() <-chan string {
+	return w.events
+}
+
+// Close 変更通知を終了
+func (w *inotifyWatcher) Close() error {
+	return w.file.Close()
+}
+
+// read inotify のイベントを読み込んでパスに変換
+func (w *inotifyWatcher) read() {
+	defer close(w.events)
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			wd := int32(binary.NativeEndian.Uint32(buf[offset:]))
+			mask := binary.NativeEndian.Uint32(buf[offset+4:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[offset+12:]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			name := strings.TrimRight(string(buf[nameStart:nameStart+nameLen]), "\x00")
+			offset = nameStart + nameLen
+
+			w.mu.Lock()
+			dir := w.dirs[wd]
+			if mask&syscall.IN_IGNORED != 0 {
+				delete(w.dirs, wd)
+			}
+			w.mu.Unlock()
+
+			switch {
+			case mask&syscall.IN_Q_OVERFLOW != 0:
+				// 取りこぼしたイベントがあるため、変更があったものとして扱う
+				w.send("")
+			case mask&syscall.IN_IGNORED != 0 || dir == "":
+			default:
+				w.send(filepath.Join(dir, name))
+			}
+		}
+	}
+}
+
+// send 変更を通知（受け取り側を待たない）
+//
+// 通知が溢れた場合はイベントを読み捨て、空きができ次第 "" で取りこぼしを通知する。
+// ブロックすると inotify のキューが溢れるまで読み込みが止まるため。
+func (w *inotifyWatcher) send(path string) {
+	if w.dropped {
+		select {
+		case w.events <- "":
+			w.dropped = false
+		default:
+			return
+		}
+	}
+
+	select {
+	case w.events <- path:
+	default:
+		w.dropped = true
+	}
+}
@@ -0,0 +1,139 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// nextEvent 変更通知を1件受け取る
+func nextEvent(t *testing.T, events <-chan string) string {
+	t.Helper()
+	select {
+	case path, ok := <-events:
+		if !ok {
+			t.Fatal("変更通知が停止しました")
+		}
+		return path
+	case <-time.After(5 * time.Second):
+		t.Fatal("変更通知がありません")
+	}
+	return ""
+}
+
+func TestInotifyWatcher(t *testing.T) {
+	dir := t.TempDir()
+	watcher, err := newFileWatcher()
+	if err != nil {
+		t.Skip(err)
+	}
+	if err := watcher.Add(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "license.dbc")
+	if err := os.WriteFile(path, []byte("license"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 作成と書き込み完了
+	for i := 0; i < 2; i++ {
+		if got := nextEvent(t, watcher.Events()); got != path {
+			t.Errorf("event = %q, want %q", got, path)
+		}
+	}
+
+	// Close で通知が停止する
+	watcher.Close()
+	for range watcher.Events() {
+	}
+}
+
+func TestInotifyWatcherDropsWhenFull(t *testing.T) {
+	dir := t.TempDir()
+	watcher, err := newFileWatcher()
+	if err != nil {
+		t.Skip(err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(dir); err != nil {
+		t.Fatal(err)
+	}
+	events := watcher.Events()
+
+	// 受け取らずにチャネルの容量を超える変更を発生させる
+	for i := 0; i < cap(events); i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%03d.lnc", i)), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for deadline := time.Now().Add(5 * time.Second); len(events) < cap(events); {
+		if time.Now().After(deadline) {
+			t.Fatalf("変更通知が %d 件しかありません", len(events))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// 溢れたイベントの読み捨てを待つ
+	time.Sleep(100 * time.Millisecond)
+	drainEvents(events)
+
+	// 読み込みは止まっておらず、読み捨てたイベントは "" で通知される
+	last := filepath.Join(dir, "last.lnc")
+	if err := os.WriteFile(last, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextEvent(t, events); got != "" {
+		t.Errorf("溢れた後の最初のイベント = %q, want 取りこぼしの通知（\"\"）", got)
+	}
+	for {
+		if got := nextEvent(t, events); got == last {
+			break
+		}
+	}
+}
+
+func TestWatchApplyGameRunning(t *testing.T) {
+	tool := newTestTool(t)
+	tool.AssumeYes = true
+	install := filepath.Join(t.TempDir(), "Football Manager 2024")
+	dbRoot := filepath.Join(install, "data", "database", "db")
+	writeTree(t, filepath.Join(dbRoot, "2400"), testDBFiles)
+	tool.DBRoot = dbRoot
+	license := filepath.Join(dbRoot, "2400", "dbc", "permanent", "license.dbc")
+
+	// インストールフォルダの fm.exe として見えるプロセス（Proton の cmdline と同じ形）
+	game := &exec.Cmd{Path: "/bin/sleep", Args: []string{filepath.Join(install, "fm.exe"), "60"}}
+	if err := game.Start(); err != nil {
+		t.Skip(err)
+	}
+	defer game.Process.Kill()
+
+	// 実行中は終了を待たずに戻り、ファイルは変更しない
+	start := time.Now()
+	if tool.watchApply(false) {
+		t.Fatal("ゲームの実行中に再適用しました")
+	}
+	if elapsed := time.Since(start); elapsed > gameExitPollInterval {
+		t.Errorf("watchApply() が %s ブロックしました", elapsed)
+	}
+	if _, err := os.Stat(license); err != nil {
+		t.Fatalf("ゲームの実行中にファイルが変更されました: %v", err)
+	}
+	if tool.watchApply(true) {
+		t.Fatal("ゲームの実行中に再適用しました")
+	}
+
+	// 終了後の再確認で再適用する
+	game.Process.Kill()
+	game.Wait()
+	if !tool.watchApply(true) {
+		t.Fatal("ゲームの終了後に再適用しません")
+	}
+	if _, err := os.Stat(license); !os.IsNotExist(err) {
+		t.Errorf("再適用後も %s があります: %v", license, err)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package main
+
+// newFileWatcher Linux 以外ではポーリングで監視する
+func newFileWatcher() (fileWatcher, error) {
+	return nil, errWatchUnsupported
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// receivedWithin d 以内にチャネルから受信できるか
+func receivedWithin(c <-chan time.Time, d time.Duration) bool {
+	select {
+	case <-c:
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
+
+func TestQuietTimer(t *testing.T) {
+	const period = 100 * time.Millisecond
+	quiet := newQuietTimer(period)
+	defer quiet.Stop()
+
+	// 変更がなければ発火しない
+	if receivedWithin(quiet.C(), 2*period) {
+		t.Fatal("変更がないのに発火しました")
+	}
+
+	// 静止期間より短い間隔の変更は1回にまとめ、最後の変更から静止期間後に発火する
+	start := time.Now()
+	if !quiet.Change() {
+		t.Error("最初の変更で true になりません")
+	}
+	for i := 0; i < 4; i++ {
+		time.Sleep(period / 2)
+		if quiet.Change() {
+			t.Error("2回目以降の変更で true になりました")
+		}
+	}
+	last := time.Now()
+	if !receivedWithin(quiet.C(), 10*period) {
+		t.Fatal("発火しません")
+	}
+	if elapsed := time.Since(last); elapsed < period {
+		t.Errorf("最後の変更から %s で発火しました（静止期間 %s）", elapsed, period)
+	}
+	if elapsed := time.Since(start); elapsed < period*3 {
+		t.Errorf("最初の変更から %s で発火しました", elapsed)
+	}
+	if changes := quiet.Fire(); changes != 5 {
+		t.Errorf("Fire() = %d, want 5", changes)
+	}
+	if receivedWithin(quiet.C(), 2*period) {
+		t.Error("1回の静止期間で複数回発火しました")
+	}
+
+	// 発火後の変更は再び最初の変更
+	if !quiet.Change() {
+		t.Error("発火後の最初の変更で true になりません")
+	}
+	quiet.Fire()
+
+	// Retry は変更を記録せずに指定時間後に発火する（待機中の静止期間は置き換える）
+	quiet.Retry(period / 4)
+	if !receivedWithin(quiet.C(), period/2+period/4) {
+		t.Fatal("Retry で発火しません")
+	}
+	if changes := quiet.Fire(); changes != 0 {
+		t.Errorf("Retry 後の Fire() = %d, want 0", changes)
+	}
+}
+
+func TestQuietTimerStaleFire(t *testing.T) {
+	const period = 50 * time.Millisecond
+	quiet := newQuietTimer(period)
+	defer quiet.Stop()
+
+	// 受け取られずに残っていた発火は、変更で待ち直した場合に捨てる
+	quiet.Change()
+	time.Sleep(2 * period)
+	quiet.Change()
+	start := time.Now()
+	if !receivedWithin(quiet.C(), 10*period) {
+		t.Fatal("発火しません")
+	}
+	if elapsed := time.Since(start); elapsed < period {
+		t.Errorf("待ち直す前の発火を受け取りました（%s）", elapsed)
+	}
+}
+
+func TestIsStagingPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join("db", "2400", stagingDirPrefix+"123", "lnc", "all", "a.lnc"), true},
+		{filepath.Join("db", "2400", stagingDirPrefix+"123"), true},
+		{filepath.Join("db", "2400", "lnc", "all", "a.lnc"), false},
+		{filepath.Join("db", "2400", "fm24-real-staging-notes.txt"), false},
+	}
+
+	for _, tt := range tests {
+		if got := isStagingPath(tt.path); got != tt.want {
+			t.Errorf("isStagingPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDrainEvents(t *testing.T) {
+	events := make(chan string, 4)
+	events <- "a"
+	events <- ""
+	drainEvents(events)
+	if len(events) != 0 {
+		t.Errorf("%d 件残っています", len(events))
+	}
+
+	// 停止した通知と nil（ポーリング）でも戻る
+	close(events)
+	drainEvents(events)
+	drainEvents(nil)
+}
+
+// recordingWatcher 監視対象に追加したディレクトリを記録する fileWatcher
+type recordingWatcher struct {
+	dirs []string
+}
+
+func (w *recordingWatcher) Add(dir string) error {
+	w.dirs = append(w.dirs, dir)
+	return nil
+}
+
+func (w *recordingWatcher) Events() <-chan string { return nil }
+
+func (w *recordingWatcher) Close() error { return nil }
+
+func TestAddWatchTree(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"2400/lnc/all/a.lnc": "a",
+		"2400/dbc/":          "",
+		"2400/" + stagingDirPrefix + "1/lnc/all/": "",
+		"2410/": "",
+	})
+
+	watcher := &recordingWatcher{}
+	if err := addWatchTree(watcher, root); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, dir := range watcher.dirs {
+		rel, _ := filepath.Rel(root, dir)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	slices.Sort(got)
+	want := []string{".", "2400", "2400/dbc", "2400/lnc", "2400/lnc/all", "2410"}
+	if !slices.Equal(got, want) {
+		t.Errorf("監視対象 = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// windowsScanRoots Windowsでゲームのインストールフォルダを探すフォルダ
+//
+// Wine プレフィックス内でも同じパスを drive_c 等に読み替えて使用する。
+var windowsScanRoots = []string{
+	`C:\Program Files (x86)\Steam\steamapps\common`,
+	`C:\Program Files\Steam\steamapps\common`,
+	`C:\Program Files\Epic Games`,
+	`C:\XboxGames`,
+	// Steamライブラリフォルダ（複数のドライブをチェック）
+	`D:\SteamLibrary\steamapps\common`,
+	`E:\SteamLibrary\steamapps\common`,
+	`F:\SteamLibrary\steamapps\common`,
+}
+
+// winePrefix Wine プレフィックス
+type winePrefix struct {
+	Path     string // プレフィックス（drive_c の親）
+	Launcher string // wine, lutris, heroic, bottles, proton
+}
+
+// winePath Windowsのパスをプレフィックス内のパスに変換（C: は drive_c、その他は dosdevices）
+func (p winePrefix) winePath(windowsPath string) string {
+	windowsPath = strings.ReplaceAll(windowsPath, `\`, "/")
+	if len(windowsPath) < 2 || windowsPath[1] != ':' {
+		return ""
+	}
+
+	drive := strings.ToLower(windowsPath[:1])
+	rest := filepath.FromSlash(strings.TrimPrefix(windowsPath[2:], "/"))
+	if drive == "c" {
+		return filepath.Join(p.Path, "drive_c", rest)
+	}
+	return filepath.Join(p.Path, "dosdevices", drive+":", rest)
+}
+
+// isWinePrefix フォルダが Wine プレフィックスか（drive_c があるか）
+func isWinePrefix(path string) bool {
+	info, err := os.Stat(filepath.Join(path, "drive_c"))
+	return err == nil && info.IsDir()
+}
+
+// findWinePrefixes Wine系ランチャーのプレフィックスを列挙
+//
+// ~/.wine（$WINEPREFIX）、Lutris・Heroic の設定にあるプレフィックス、
+// Bottles のボトル、Steamライブラリの Proton の compatdata/<appid>/pfx を対象とする。
+func findWinePrefixes(home string, libraries []steamLibrary) []winePrefix {
+	var prefixes []winePrefix
+	seen := make(map[string]bool)
+
+	add := func(path, launcher string) {
+		path, err := expandPath(path)
+		if err != nil || path == "" {
+			return
+		}
+		key := filepath.Clean(path)
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			key = resolved
+		}
+		if seen[key] || !isWinePrefix(path) {
+			return
+		}
+		seen[key] = true
+		prefixes = append(prefixes, winePrefix{Path: path, Launcher: launcher})
+	}
+
+	// プレフィックス自身、またはプレフィックスを並べたフォルダ
+	addPrefixOrChildren := func(path, launcher string) {
+		path, err := expandPath(path)
+		if err != nil || path == "" {
+			return
+		}
+		if isWinePrefix(path) {
+			add(path, launcher)
+			return
+		}
+		for _, child := range subdirs(path) {
+			add(child, launcher)
+		}
+	}
+
+	// Wine
+	if prefix := os.Getenv("WINEPREFIX"); prefix != "" {
+		add(prefix, "wine")
+	}
+	add(filepath.Join(home, ".wine"), "wine")
+
+	// Lutris（ゲームごとの設定の game.prefix）
+	for _, dir := range []string{
+		filepath.Join(home, ".config/lutris/games"),
+		filepath.Join(home, ".local/share/lutris/games"),
+		filepath.Join(home, ".var/app/net.lutris.Lutris/config/lutris/games"),
+		filepath.Join(home, ".var/app/net.lutris.Lutris/data/lutris/games"),
+	} {
+		for _, prefix := range lutrisPrefixes(dir) {
+			add(prefix, "lutris")
+		}
+	}
+
+	// Heroic（デフォルト設定とゲームごとの設定の winePrefix）
+	for _, dir := range heroicConfigDirs(home) {
+		for _, prefix := range heroicPrefixes(dir) {
+			addPrefixOrChildren(prefix, "heroic")
+		}
+	}
+	addPrefixOrChildren(filepath.Join(home, "Games/Heroic/Prefixes/default"), "heroic")
+
+	// Bottles（各ボトルがプレフィックス）
+	addPrefixOrChildren(filepath.Join(home, ".local/share/bottles/bottles"), "bottles")
+	addPrefixOrChildren(filepath.Join(home, ".var/app/com.usebottles.bottles/data/bottles/bottles"), "bottles")
+
+	// Proton（Steamライブラリの compatdata/<appid>/pfx）
+	for _, library := range libraries {
+		for _, appDir := range subdirs(filepath.Join(library.Path, "steamapps", "compatdata")) {
+			add(filepath.Join(appDir, "pfx"), "proton")
+		}
+	}
+
+	return prefixes
+}
+
+// subdirs フォルダ直下のサブフォルダを名前順に取得
+func subdirs(path string) []string {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(path, entry.Name()))
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// lutrisPrefixes Lutris のゲーム設定（*.yml）からプレフィックスを取得
+func lutrisPrefixes(dir string) []string {
+	paths, _ := filepath.Glob(filepath.Join(escapeGlob(dir), "*.yml"))
+	sort.Strings(paths)
+
+	var prefixes []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var config struct {
+			Game struct {
+				Prefix string `yaml:"prefix"`
+			} `yaml:"game"`
+		}
+		if err := yaml.Unmarshal(data, &config); err != nil || config.Game.Prefix == "" {
+			continue
+		}
+		prefixes = append(prefixes, config.Game.Prefix)
+	}
+	return prefixes
+}
+
+// heroicConfigDirs Heroic Games Launcher の設定フォルダ（ネイティブ版、Flatpak版）
+func heroicConfigDirs(home string) []string {
+	return []string{
+		filepath.Join(home, ".config/heroic"),
+		filepath.Join(home, ".var/app/com.heroicgameslauncher.hgl/config/heroic"),
+	}
+}
+
+// heroicSettings Heroic の設定のうちプレフィックスとインストール先
+type heroicSettings struct {
+	WinePrefix         string `json:"winePrefix"`
+	DefaultInstallPath string `json:"defaultInstallPath"`
+}
+
+// heroicPrefixes Heroic の config.json と GamesConfig/*.json からプレフィックスを取得
+func heroicPrefixes(dir string) []string {
+	var prefixes []string
+
+	if data, err := os.ReadFile(filepath.Join(dir, "config.json")); err == nil {
+		var config struct {
+			DefaultSettings heroicSettings `json:"defaultSettings"`
+		}
+		if json.Unmarshal(data, &config) == nil && config.DefaultSettings.WinePrefix != "" {
+			prefixes = append(prefixes, config.DefaultSettings.WinePrefix)
+		}
+	}
+
+	// GamesConfig/<appName>.json: { "<appName>": { "winePrefix": "..." }, "version": "v0" }
+	paths, _ := filepath.Glob(filepath.Join(escapeGlob(dir), "GamesConfig", "*.json"))
+	sort.Strings(paths)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var games map[string]json.RawMessage
+		if err := json.Unmarshal(data, &games); err != nil {
+			continue
+		}
+		keys := make([]string, 0, len(games))
+		for key := range games {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			var settings heroicSettings
+			if json.Unmarshal(games[key], &settings) == nil && settings.WinePrefix != "" {
+				prefixes = append(prefixes, settings.WinePrefix)
+			}
+		}
+	}
+
+	return prefixes
+}
+
+// heroicInstallDirs Heroic のゲームのインストール先（プレフィックスの外に置かれる）
+func heroicInstallDirs(home string) []string {
+	dirs := []string{filepath.Join(home, "Games/Heroic")}
+	for _, dir := range heroicConfigDirs(home) {
+		data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+		if err != nil {
+			continue
+		}
+		var config struct {
+			DefaultSettings heroicSettings `json:"defaultSettings"`
+		}
+		if json.Unmarshal(data, &config) == nil && config.DefaultSettings.DefaultInstallPath != "" {
+			if path, err := expandPath(config.DefaultSettings.DefaultInstallPath); err == nil {
+				dirs = append(dirs, path)
+			}
+		}
+	}
+	return dirs
+}
+
+// scanWinePrefix プレフィックス内のWindows版のインストールを検索
+//
+// Windowsのスキャン対象に加え、プレフィックス内のSteam（libraryfolders.vdf）と
+// Epic Games Launcher（.item マニフェスト）のインストール先も確認する。
+func (t *FM24Tool) scanWinePrefix(prefix winePrefix) *InstallInfo {
+	found := func(installDir, store string) *InstallInfo {
+		if installDir == "" {
+			return nil
+		}
+		for _, scanPath := range t.Game.dbLayoutPaths(installDir) {
+			if _, err := t.detectVersionFolder(scanPath); err == nil {
+				return &InstallInfo{Path: scanPath, Source: "scan", Store: store, Prefix: prefix.Path}
+			}
+		}
+		return nil
+	}
+
+	// プレフィックス内のSteam
+	for _, steamRoot := range []string{`C:\Program Files (x86)\Steam`, `C:\Program Files\Steam`} {
+		root := prefix.winePath(steamRoot)
+		libraries := []steamLibrary{{Path: root}}
+		if data, err := os.ReadFile(filepath.Join(root, "steamapps", "libraryfolders.vdf")); err == nil {
+			if folders, err := parseLibraryFolders(data); err == nil {
+				for _, library := range folders {
+					if library.Path = prefix.winePath(library.Path); library.Path != "" {
+						libraries = append(libraries, library)
+					}
+				}
+			}
+		}
+		if app, err := findSteamApp(libraries, t.Game.SteamAppID); err == nil {
+			if install := found(app.Path(), "steam"); install != nil {
+				install.BuildID = app.BuildID
+				return install
+			}
+		}
+	}
+
+	// プレフィックス内の Epic Games Launcher
+	for _, app := range readEpicManifests(prefix.winePath(`C:\ProgramData\Epic\EpicGamesLauncher\Data\Manifests`)) {
+		if app.IsIncompleteInstall || !app.isGame(t.Game) {
+			continue
+		}
+		if install := found(prefix.winePath(app.InstallLocation), "epic"); install != nil {
+			install.Version = app.AppVersionString
+			return install
+		}
+	}
+
+	// Windowsのスキャン対象
+	for _, root := range windowsScanRoots {
+		for _, scanPath := range t.Game.dbPaths(prefix.winePath(root)) {
+			if _, err := t.detectVersionFolder(scanPath); err == nil {
+				return &InstallInfo{Path: scanPath, Source: "scan", Prefix: prefix.Path}
+			}
+		}
+	}
+
+	return nil
+}
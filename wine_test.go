@@ -0,0 +1,180 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestWinePath(t *testing.T) {
+	prefix := winePrefix{Path: "/home/user/.wine"}
+
+	tests := []struct {
+		windowsPath string
+		want        string
+	}{
+		{`C:\Program Files (x86)\Steam`, "/home/user/.wine/drive_c/Program Files (x86)/Steam"},
+		{`c:/ProgramData/Epic`, "/home/user/.wine/drive_c/ProgramData/Epic"},
+		{`D:\SteamLibrary\`, "/home/user/.wine/dosdevices/d:/SteamLibrary"},
+		{`E:`, "/home/user/.wine/dosdevices/e:"},
+		{"/home/user/Games", ""},
+		{`\\server\share`, ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := filepath.ToSlash(prefix.winePath(tt.windowsPath)); got != tt.want {
+			t.Errorf("winePath(%q) = %q, want %q", tt.windowsPath, got, tt.want)
+		}
+	}
+}
+
+func TestLutrisPrefixes(t *testing.T) {
+	// prefix のない設定と解析できない設定は無視
+	got := lutrisPrefixes(filepath.Join("testdata", "wine", "lutris"))
+	if want := []string{"~/Games/football-manager-2024"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lutrisPrefixes() = %q, want %q", got, want)
+	}
+}
+
+func TestHeroicPrefixes(t *testing.T) {
+	// デフォルト設定、ゲームごとの設定の順（winePrefix のないゲームは無視）
+	got := heroicPrefixes(filepath.Join("testdata", "wine", "heroic"))
+	want := []string{"~/Games/Heroic/Prefixes", "~/Games/Heroic/Prefixes/Football Manager 2024"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("heroicPrefixes() = %q, want %q", got, want)
+	}
+}
+
+func TestFindWinePrefixes(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("WINEPREFIX", filepath.Join(home, "Games/football-manager-2024"))
+	library := t.TempDir()
+
+	writeTree(t, home, map[string]string{
+		".wine/drive_c/": "",
+		// Lutris（$WINEPREFIX と同じプレフィックス）
+		".config/lutris/games/football-manager-2024-1705579822.yml": readFixture(t, "wine/lutris/football-manager-2024-1705579822.yml"),
+		".config/lutris/games/celeste-1690000000.yml":               readFixture(t, "wine/lutris/celeste-1690000000.yml"),
+		"Games/football-manager-2024/drive_c/":                      "",
+		// Heroic（デフォルトのプレフィックスはプレフィックスを並べたフォルダ）
+		".config/heroic/config.json":                           readFixture(t, "wine/heroic/config.json"),
+		".config/heroic/GamesConfig/FM24AppName.json":          readFixture(t, "wine/heroic/GamesConfig/FM24AppName.json"),
+		"Games/Heroic/Prefixes/Football Manager 2024/drive_c/": "",
+		"Games/Heroic/Prefixes/default/drive_c/":               "",
+		"Games/Heroic/Prefixes/not-a-prefix/":                  "",
+		// Bottles
+		".local/share/bottles/bottles/FM24/drive_c/": "",
+		".local/share/bottles/bottles/Empty/":        "",
+	})
+	writeTree(t, library, map[string]string{
+		"steamapps/compatdata/2252570/pfx/drive_c/": "",
+		"steamapps/compatdata/228980/":              "",
+	})
+
+	got := findWinePrefixes(home, []steamLibrary{{Path: library}})
+	want := []winePrefix{
+		{Path: filepath.Join(home, "Games/football-manager-2024"), Launcher: "wine"},
+		{Path: filepath.Join(home, ".wine"), Launcher: "wine"},
+		{Path: filepath.Join(home, "Games/Heroic/Prefixes/Football Manager 2024"), Launcher: "heroic"},
+		{Path: filepath.Join(home, "Games/Heroic/Prefixes/default"), Launcher: "heroic"},
+		{Path: filepath.Join(home, ".local/share/bottles/bottles/FM24"), Launcher: "bottles"},
+		{Path: filepath.Join(library, "steamapps/compatdata/2252570/pfx"), Launcher: "proton"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findWinePrefixes() =")
+		for _, prefix := range got {
+			t.Errorf("  %+v", prefix)
+		}
+	}
+}
+
+func TestScanWinePrefix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows では Wine プレフィックスを検索しない")
+	}
+	const dbDir = "data/database/db"
+
+	tests := []struct {
+		name  string
+		files func(t *testing.T) map[string]string
+		want  func(prefix string) *InstallInfo
+	}{
+		{
+			name: "プレフィックス内のSteam（追加のライブラリ）",
+			files: func(t *testing.T) map[string]string {
+				return map[string]string{
+					"drive_c/Program Files (x86)/Steam/steamapps/libraryfolders.vdf": `"libraryfolders"
+{
+	"0" { "path" "C:\\Program Files (x86)\\Steam" "apps" { } }
+	"1" { "path" "D:\\SteamLibrary" "apps" { "2252570" "3410846211" } }
+}`,
+					"dosdevices/d:/SteamLibrary/steamapps/appmanifest_2252570.acf":                          readFixture(t, "steam/appmanifest_2252570.acf"),
+					"dosdevices/d:/SteamLibrary/steamapps/common/Football Manager 2024/" + dbDir + "/2400/": "",
+				}
+			},
+			want: func(prefix string) *InstallInfo {
+				return &InstallInfo{
+					Path:    filepath.Join(prefix, "dosdevices/d:/SteamLibrary/steamapps/common/Football Manager 2024", dbDir),
+					Source:  "scan",
+					Store:   "steam",
+					BuildID: "13265472",
+					Prefix:  prefix,
+				}
+			},
+		},
+		{
+			name: "プレフィックス内の Epic Games Launcher",
+			files: func(t *testing.T) map[string]string {
+				return map[string]string{
+					"drive_c/ProgramData/Epic/EpicGamesLauncher/Data/Manifests/" + epicFM24:    readFixture(t, "epic/"+epicFM24),
+					"drive_c/Program Files/Epic Games/FootballManager2024/" + dbDir + "/2400/": "",
+				}
+			},
+			want: func(prefix string) *InstallInfo {
+				return &InstallInfo{
+					Path:    filepath.Join(prefix, "drive_c/Program Files/Epic Games/FootballManager2024", dbDir),
+					Source:  "scan",
+					Store:   "epic",
+					Version: "24.3.0.2043-Windows",
+					Prefix:  prefix,
+				}
+			},
+		},
+		{
+			name: "Windowsのスキャン対象（Xbox）",
+			files: func(t *testing.T) map[string]string {
+				return map[string]string{"drive_c/XboxGames/Football Manager 2024/Content/" + dbDir + "/2400/": ""}
+			},
+			want: func(prefix string) *InstallInfo {
+				return &InstallInfo{
+					Path:   filepath.Join(prefix, "drive_c/XboxGames/Football Manager 2024/Content", dbDir),
+					Source: "scan",
+					Prefix: prefix,
+				}
+			},
+		},
+		{
+			name: "インストールなし",
+			files: func(t *testing.T) map[string]string {
+				return map[string]string{"drive_c/Program Files/Epic Games/FootballManager2024/": ""}
+			},
+			want: func(string) *InstallInfo { return nil },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := newTestTool(t)
+			prefix := t.TempDir()
+			writeTree(t, prefix, tt.files(t))
+
+			got := tool.scanWinePrefix(winePrefix{Path: prefix, Launcher: "wine"})
+			if want := tt.want(prefix); !reflect.DeepEqual(got, want) {
+				t.Errorf("scanWinePrefix() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}